@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/apikey"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	APIKeyRequest struct {
+		Name        string            `json:"name"`
+		Permissions []role.Permission `json:"permissions"`
+		ExpiresAt   *time.Time        `json:"expiresAt"`
+	}
+
+	// CreatedAPIKeyResponse is the only time the full key is shown.
+	CreatedAPIKeyResponse struct {
+		apikey.Key
+		Token string `json:"key"`
+	}
+)
+
+func (s *server) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.CreateAPIKey"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req APIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := apikey.Key{
+		MerchantID:  userCredentials.MerchantID,
+		CreatedBy:   userCredentials.UserID,
+		Name:        req.Name,
+		Permissions: req.Permissions,
+		ExpiresAt:   req.ExpiresAt,
+	}
+
+	created, key, err := s.apiKeyService.CreateAPIKey(ctx, userCredentials.Permissions, entity)
+	if err != nil {
+		apiKeyErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	logger.Info(ctx, ops, "API key %s created by user %d", created.Prefix, userCredentials.UserID)
+	SuccessResponse(w, "store the key now, it will not be shown again", CreatedAPIKeyResponse{Key: created, Token: key}, http.StatusCreated)
+}
+
+func (s *server) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetAPIKeys"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	keys, err := s.apiKeyService.GetAPIKeys(ctx, userCredentials.MerchantID)
+	if err != nil {
+		apiKeyErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if keys == nil {
+		keys = []apikey.Key{}
+	}
+
+	SuccessResponse(w, "data found", keys, http.StatusOK)
+}
+
+func (s *server) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RevokeAPIKey"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	keyID, err := strconv.Atoi(mux.Vars(r)["keyId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid API key id"), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.apiKeyService.RevokeAPIKey(ctx, userCredentials.MerchantID, keyID); err != nil {
+		apiKeyErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "API key revoked", nil, http.StatusOK)
+}
+
+func apiKeyErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apikey.ErrInvalidAPIKeyParameters):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, apikey.ErrAPIKeyNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, role.ErrInsufficientPermissions):
+		FailedResponse(w, err, http.StatusForbidden)
+	default:
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
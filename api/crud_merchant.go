@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/merchant"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	MerchantRequest struct {
+		Name string  `json:"name"`
+		Logo *string `json:"logo"`
+	}
+
+	MerchantResponse struct {
+		Merchant merchant.Merchant `json:"merchant"`
+	}
+
+	GetMerchantsResponse struct {
+		Merchants []merchant.Merchant `json:"merchants"`
+		Page      int                 `json:"page"`
+		TotalData int                 `json:"totalData"`
+	}
+)
+
+func (s *server) CreateMerchant(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.CreateMerchant"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req MerchantRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := merchant.Merchant{
+		Name: req.Name,
+		Logo: req.Logo,
+	}
+
+	mID, err := s.merchantService.CreateMerchant(ctx, entity)
+	if err != nil {
+		if errors.Is(err, merchant.ErrInvalidMerchantParameters) {
+			FailedResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity.ID = mID
+	logger.Info(ctx, ops, "success created new merchant")
+	SuccessResponse(w, "success", MerchantResponse{Merchant: entity}, http.StatusCreated)
+}
+
+func (s *server) GetMerchants(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetMerchants"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+
+	page, limit, lastID, err := parsePagination(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	merchants, totalData, err := s.merchantService.GetMerchants(ctx, lastID, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if merchants == nil {
+		merchants = []merchant.Merchant{}
+	}
+
+	SuccessResponse(w, "data found", GetMerchantsResponse{
+		Page:      page,
+		Merchants: merchants,
+		TotalData: totalData,
+	}, http.StatusOK)
+}
+
+func (s *server) GetMerchant(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetMerchant"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	merchantID, err := strconv.Atoi(mux.Vars(r)["merchantId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid merchant id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.merchantService.GetMerchant(ctx, userCredentials.MerchantID, merchantID)
+	if err != nil {
+		switch {
+		case errors.Is(err, merchant.ErrForbiddenMerchant):
+			FailedResponse(w, err, http.StatusForbidden)
+		case errors.Is(err, merchant.ErrMerchantNotFound):
+			FailedResponse(w, err, http.StatusNotFound)
+		default:
+			logger.Error(ctx, ops, "unkown error: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func (s *server) UpdateMerchant(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.UpdateMerchant"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req MerchantRequest
+
+	merchantID, err := strconv.Atoi(mux.Vars(r)["merchantId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid merchant id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := merchant.Merchant{
+		ID:   merchantID,
+		Name: req.Name,
+		Logo: req.Logo,
+	}
+
+	err = s.merchantService.UpdateMerchant(ctx, userCredentials.MerchantID, entity)
+	if err != nil {
+		switch {
+		case errors.Is(err, merchant.ErrInvalidMerchantParameters):
+			FailedResponse(w, err, http.StatusBadRequest)
+		case errors.Is(err, merchant.ErrForbiddenMerchant):
+			FailedResponse(w, err, http.StatusForbidden)
+		case errors.Is(err, merchant.ErrMerchantNotFound):
+			FailedResponse(w, err, http.StatusNotFound)
+		default:
+			logger.Error(ctx, ops, "unkown error: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	SuccessResponse(w, "success", MerchantResponse{Merchant: entity}, http.StatusOK)
+}
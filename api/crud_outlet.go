@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	OutletRequest struct {
+		Name     string  `json:"name"`
+		Location *string `json:"location"`
+	}
+
+	OutletResponse struct {
+		Outlet outlet.Outlet `json:"outlet"`
+	}
+
+	GetOutletsResponse struct {
+		Outlets   []outlet.Outlet `json:"outlets"`
+		Page      int             `json:"page"`
+		TotalData int             `json:"totalData"`
+	}
+)
+
+func (s *server) CreateOutlet(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.CreateOutlet"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req OutletRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := outlet.Outlet{
+		MerchantID: userCredentials.MerchantID,
+		Name:       req.Name,
+		Location:   req.Location,
+	}
+
+	oID, err := s.outletService.CreateOutlet(ctx, entity)
+	if err != nil {
+		if errors.Is(err, outlet.ErrInvalidOutletParameters) {
+			FailedResponse(w, err, http.StatusBadRequest)
+			return
+		}
+
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity.ID = oID
+	logger.Info(ctx, ops, "success created new outlet")
+	SuccessResponse(w, "success", OutletResponse{Outlet: entity}, http.StatusCreated)
+}
+
+func (s *server) GetOutlets(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetOutlets"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	page, limit, lastID, err := parsePagination(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	outlets, totalData, err := s.outletService.GetOutlets(ctx, userCredentials.MerchantID, lastID, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if outlets == nil {
+		outlets = []outlet.Outlet{}
+	}
+
+	SuccessResponse(w, "data found", GetOutletsResponse{
+		Page:      page,
+		Outlets:   outlets,
+		TotalData: totalData,
+	}, http.StatusOK)
+}
+
+func (s *server) GetOutlet(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetOutlet"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.outletService.GetOutlet(ctx, userCredentials.MerchantID, outletID)
+	if err != nil {
+		if errors.Is(err, outlet.ErrOutletNotFound) {
+			FailedResponse(w, err, http.StatusNotFound)
+			return
+		}
+
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func (s *server) UpdateOutlet(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.UpdateOutlet"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req OutletRequest
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := outlet.Outlet{
+		ID:         outletID,
+		MerchantID: userCredentials.MerchantID,
+		Name:       req.Name,
+		Location:   req.Location,
+	}
+
+	err = s.outletService.UpdateOutlet(ctx, entity)
+	if err != nil {
+		switch {
+		case errors.Is(err, outlet.ErrInvalidOutletParameters):
+			FailedResponse(w, err, http.StatusBadRequest)
+		case errors.Is(err, outlet.ErrOutletNotFound):
+			FailedResponse(w, err, http.StatusNotFound)
+		default:
+			logger.Error(ctx, ops, "unkown error: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	SuccessResponse(w, "success", OutletResponse{Outlet: entity}, http.StatusOK)
+}
+
+func (s *server) RemoveOutlet(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RemoveOutlet"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	err = s.outletService.DeleteOutlet(ctx, userCredentials.MerchantID, outletID)
+	if err != nil {
+		if errors.Is(err, outlet.ErrOutletNotFound) {
+			FailedResponse(w, err, http.StatusNotFound)
+			return
+		}
+
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	SuccessResponse(w, fmt.Sprintf("success delete outlet with id %d", outletID), nil, http.StatusOK)
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	ProductRequest struct {
+		SKU          string  `json:"sku"`
+		Name         string  `json:"name"`
+		DisplayImage *string `json:"displayImage"`
+	}
+
+	ProductResponse struct {
+		Product product.Product `json:"product"`
+	}
+
+	GetProductsResponse struct {
+		Products  []product.Product `json:"products"`
+		Page      int               `json:"page"`
+		TotalData int               `json:"totalData"`
+	}
+)
+
+func (s *server) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.CreateProduct"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req ProductRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := product.Product{
+		MerchantID:   userCredentials.MerchantID,
+		SKU:          req.SKU,
+		Name:         req.Name,
+		DisplayImage: req.DisplayImage,
+	}
+
+	pID, err := s.productService.CreateProduct(ctx, entity)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrInvalidProductParameters):
+			FailedResponse(w, err, http.StatusBadRequest)
+		case errors.Is(err, product.ErrSKUNotUnique):
+			FailedResponse(w, err, http.StatusConflict)
+		default:
+			logger.Error(ctx, ops, "unexpected error %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	entity.ID = pID
+	logger.Info(ctx, ops, "success created new product")
+	SuccessResponse(w, "success", ProductResponse{Product: entity}, http.StatusCreated)
+}
+
+func (s *server) GetProducts(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetProducts"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	page, limit, lastID, err := parsePagination(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	products, totalData, err := s.productService.GetProducts(ctx, userCredentials.MerchantID, lastID, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if products == nil {
+		products = []product.Product{}
+	}
+
+	SuccessResponse(w, "data found", GetProductsResponse{
+		Page:      page,
+		Products:  products,
+		TotalData: totalData,
+	}, http.StatusOK)
+}
+
+func (s *server) GetProduct(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetProduct"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	productID, err := strconv.Atoi(mux.Vars(r)["productId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid product id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.productService.GetProduct(ctx, userCredentials.MerchantID, productID)
+	if err != nil {
+		if errors.Is(err, product.ErrProductNotFound) {
+			FailedResponse(w, err, http.StatusNotFound)
+			return
+		}
+
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func (s *server) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.UpdateProduct"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req ProductRequest
+
+	productID, err := strconv.Atoi(mux.Vars(r)["productId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid product id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := product.Product{
+		ID:           productID,
+		MerchantID:   userCredentials.MerchantID,
+		SKU:          req.SKU,
+		Name:         req.Name,
+		DisplayImage: req.DisplayImage,
+	}
+
+	err = s.productService.UpdateProduct(ctx, entity)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrInvalidProductParameters):
+			FailedResponse(w, err, http.StatusBadRequest)
+		case errors.Is(err, product.ErrSKUNotUnique):
+			FailedResponse(w, err, http.StatusConflict)
+		case errors.Is(err, product.ErrProductNotFound):
+			FailedResponse(w, err, http.StatusNotFound)
+		default:
+			logger.Error(ctx, ops, "unkown error: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	SuccessResponse(w, "success", ProductResponse{Product: entity}, http.StatusOK)
+}
+
+func (s *server) RemoveProduct(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RemoveProduct"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	productID, err := strconv.Atoi(mux.Vars(r)["productId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid product id"), http.StatusBadRequest)
+		return
+	}
+
+	err = s.productService.DeleteProduct(ctx, userCredentials.MerchantID, productID)
+	if err != nil {
+		if errors.Is(err, product.ErrProductNotFound) {
+			FailedResponse(w, err, http.StatusNotFound)
+			return
+		}
+
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	SuccessResponse(w, fmt.Sprintf("success delete product with id %d", productID), nil, http.StatusOK)
+}
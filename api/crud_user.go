@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/role"
 	"github.com/mhdiiilham/POS/entity/user"
 	"github.com/mhdiiilham/POS/pkg/logger"
 )
@@ -20,16 +23,33 @@ type (
 		Password  string `json:"password"`
 		FirstName string `json:"firstname"`
 		LastName  string `json:"lastname"`
+		RoleID    int    `json:"roleID"`
 	}
 
 	CreateUserResponse struct {
 		User user.User `json:"user"`
 	}
 
+	UpdateUserRequest struct {
+		Email           *string `json:"email"`
+		FirstName       *string `json:"firstname"`
+		LastName        *string `json:"lastname"`
+		CurrentPassword *string `json:"currentPassword"`
+	}
+
+	SetPINRequest struct {
+		PIN string `json:"pin"`
+	}
+
+	ChangePasswordRequest struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+
 	GetUsersResponse struct {
-		Users     []user.User `json:"users"`
-		Page      int         `json:"page"`
-		TotalData int         `json:"totalData"`
+		Users      []user.User `json:"users"`
+		TotalData  int         `json:"totalData"`
+		NextCursor string      `json:"nextCursor,omitempty"`
 	}
 )
 
@@ -57,9 +77,10 @@ func (s *server) CreateUser(w http.ResponseWriter, r *http.Request) {
 		FirstName:  req.FirstName,
 		LastName:   &req.LastName,
 		Password:   req.Password,
+		RoleID:     req.RoleID,
 	}
 
-	uID, err := s.userService.CreateUser(ctx, entity)
+	uID, err := s.userService.CreateUser(ctx, userCredential.Permissions, entity)
 	if err != nil {
 		logger.Info(ctx, ops, "err: %v", err)
 		if errors.Is(err, user.ErrEmailNotUnique) {
@@ -67,11 +88,16 @@ func (s *server) CreateUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if errors.Is(err, user.ErrInvalidCreateParameters) {
+		if errors.Is(err, user.ErrInvalidCreateParameters) || errors.Is(err, user.ErrInvalidPassword) {
 			FailedResponse(w, err, http.StatusBadRequest)
 			return
 		}
 
+		if errors.Is(err, role.ErrRoleNotFound) || errors.Is(err, role.ErrInsufficientPermissions) {
+			roleErrorResponse(ctx, ops, w, err)
+			return
+		}
+
 		UnknownErrorResponse(w, err)
 		return
 	}
@@ -85,67 +111,98 @@ func (s *server) CreateUser(w http.ResponseWriter, r *http.Request) {
 	SuccessResponse(w, "success", resp, http.StatusCreated)
 }
 
+// GetUsers lists the merchant's users. Query parameters, all optional:
+// search (name or email substring), roleId, outletId, status (active or
+// deleted), createdFrom and createdTo (RFC 3339), sort (a field name, with a
+// leading "-" for descending order), limit, and cursor (the nextCursor of the
+// previous page).
 func (s *server) GetUsers(w http.ResponseWriter, r *http.Request) {
 	var (
-		page      int = 1
-		limit     int
-		lastID    int
-		totalData int
-		err       error
-		users     []user.User = []user.User{}
+		limit      int
+		filter     user.Filter
+		sort       user.Sort
+		totalData  int
+		nextCursor string
+		err        error
+		users      []user.User
 	)
 
 	const ops = "api.server.GetUsers"
 	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
 	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
-	limitQuery := r.URL.Query().Get("limit")
-	lastIDQuery := r.URL.Query().Get("lastID")
-	pageQuery := r.URL.Query().Get("page")
+	query := r.URL.Query()
 
 	logger.Info(ctx, ops, "start handling GetUsers")
-	limit, err = strconv.Atoi(limitQuery)
-	if err != nil && limitQuery != "" {
-		FailedResponse(w, errors.New("invalid limit"), http.StatusBadRequest)
+	if limit, err = parseLimit(r); err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
 		return
 	}
 
-	lastID, err = strconv.Atoi(lastIDQuery)
-	if err != nil && lastIDQuery != "" {
-		FailedResponse(w, errors.New("invalid lastid"), http.StatusBadRequest)
-		return
+	if roleIDQuery := query.Get("roleId"); roleIDQuery != "" {
+		if filter.RoleID, err = strconv.Atoi(roleIDQuery); err != nil {
+			FailedResponse(w, errors.New("invalid roleId"), http.StatusBadRequest)
+			return
+		}
 	}
 
-	page, err = strconv.Atoi(pageQuery)
-	if err != nil && pageQuery != "" {
-		FailedResponse(w, errors.New("invalid page"), http.StatusBadRequest)
-		return
+	if outletIDQuery := query.Get("outletId"); outletIDQuery != "" {
+		if filter.OutletID, err = strconv.Atoi(outletIDQuery); err != nil {
+			FailedResponse(w, errors.New("invalid outletId"), http.StatusBadRequest)
+			return
+		}
 	}
 
-	if limitQuery == "" {
-		limit = 10
+	if createdFromQuery := query.Get("createdFrom"); createdFromQuery != "" {
+		createdFrom, err := time.Parse(time.RFC3339, createdFromQuery)
+		if err != nil {
+			FailedResponse(w, errors.New("invalid createdFrom"), http.StatusBadRequest)
+			return
+		}
+		filter.CreatedFrom = &createdFrom
 	}
 
-	if pageQuery == "" {
-		page = 1
+	if createdToQuery := query.Get("createdTo"); createdToQuery != "" {
+		createdTo, err := time.Parse(time.RFC3339, createdToQuery)
+		if err != nil {
+			FailedResponse(w, errors.New("invalid createdTo"), http.StatusBadRequest)
+			return
+		}
+		filter.CreatedTo = &createdTo
 	}
 
-	users, totalData, err = s.userService.GetUsers(ctx, userCredentials.MerchantID, lastID, limit)
+	filter.Search = strings.TrimSpace(query.Get("search"))
+	filter.Status = user.Status(query.Get("status"))
+	sort.Field = user.SortField(strings.TrimPrefix(query.Get("sort"), "-"))
+	sort.Descending = strings.HasPrefix(query.Get("sort"), "-")
+
+	users, totalData, nextCursor, err = s.userService.GetUsers(ctx, userCredentials.MerchantID, filter, sort, query.Get("cursor"), limit)
 	if err != nil {
-		logger.Error(ctx, ops, "unexpected error %v", err)
-		UnknownErrorResponse(w, err)
+		switch {
+		case errors.Is(err, user.ErrInvalidStatus), errors.Is(err, user.ErrInvalidSortField),
+			errors.Is(err, user.ErrInvalidCursor), errors.Is(err, user.ErrInvalidDateRange):
+			FailedResponse(w, err, http.StatusBadRequest)
+		default:
+			logger.Error(ctx, ops, "unexpected error %v", err)
+			UnknownErrorResponse(w, err)
+		}
 		return
 	}
 
+	if users == nil {
+		users = []user.User{}
+	}
+
 	SuccessResponse(w, "data found", GetUsersResponse{
-		Page:      page,
-		Users:     users,
-		TotalData: totalData,
+		Users:      users,
+		TotalData:  totalData,
+		NextCursor: nextCursor,
 	}, http.StatusOK)
 }
 
 func (s *server) RemoveUser(w http.ResponseWriter, r *http.Request) {
 	const ops = "api.service.RemoveUser"
 	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
 
 	vars := mux.Vars(r)
 	userIDParam := vars["userId"]
@@ -155,13 +212,18 @@ func (s *server) RemoveUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.userService.DeleteUser(ctx, userID)
+	err = s.userService.DeleteUser(ctx, userCredentials.MerchantID, userCredentials.Permissions, userID)
 	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			FailedResponse(w, errors.New("invalid user id"), http.StatusBadRequest)
 			return
 		}
 
+		if errors.Is(err, role.ErrInsufficientPermissions) {
+			FailedResponse(w, err, http.StatusForbidden)
+			return
+		}
+
 		logger.Error(ctx, ops, "unkown error: %v", err.Error())
 		UnknownErrorResponse(w, err)
 		return
@@ -170,9 +232,71 @@ func (s *server) RemoveUser(w http.ResponseWriter, r *http.Request) {
 	SuccessResponse(w, fmt.Sprintf("success delete user with id %d", userID), nil, http.StatusOK)
 }
 
+// RestoreUser undoes the removal of a user.
+func (s *server) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RestoreUser"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	userID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid user id"), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.userService.RestoreUser(ctx, userCredentials.MerchantID, userCredentials.Permissions, userID); err != nil {
+		userErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, fmt.Sprintf("user %d restored", userID), nil, http.StatusOK)
+}
+
+// PurgeUser erases a removed user's personal data for good, e.g. for a data
+// removal request.
+func (s *server) PurgeUser(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.PurgeUser"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	userID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid user id"), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.userService.PurgeUser(ctx, userCredentials.MerchantID, userCredentials.Permissions, userID); err != nil {
+		userErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, fmt.Sprintf("user %d purged", userID), nil, http.StatusOK)
+}
+
+// UnlockUser lets an admin lift a user's login lockout before it runs out.
+func (s *server) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.UnlockUser"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	userID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid user id"), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.userService.UnlockUser(ctx, userCredentials.MerchantID, userCredentials.Permissions, userID); err != nil {
+		userErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, fmt.Sprintf("user %d unlocked", userID), nil, http.StatusOK)
+}
+
 func (s *server) GetUser(w http.ResponseWriter, r *http.Request) {
 	const ops = "api.service.RemoveUser"
 	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
 
 	vars := mux.Vars(r)
 	userIDParam := vars["userId"]
@@ -182,7 +306,7 @@ func (s *server) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entity, err := s.userService.GetUser(ctx, userID)
+	entity, err := s.userService.GetUser(ctx, userCredentials.MerchantID, userID)
 	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			FailedResponse(w, user.ErrUserNotFound, http.StatusBadRequest)
@@ -196,3 +320,98 @@ func (s *server) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	SuccessResponse(w, "data found", entity, http.StatusOK)
 }
+
+// UpdateUser edits the profile of the user in the path, or the caller's own
+// profile on /api/users/me.
+func (s *server) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.UpdateUser"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req UpdateUserRequest
+
+	userID := userCredentials.UserID
+	if userIDParam, ok := mux.Vars(r)["userId"]; ok {
+		var err error
+		if userID, err = strconv.Atoi(userIDParam); err != nil {
+			FailedResponse(w, errors.New("invalid user id"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	profile := user.Profile{
+		Email:           req.Email,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		CurrentPassword: req.CurrentPassword,
+	}
+
+	entity, err := s.userService.UpdateUser(ctx, userCredentials.MerchantID, userCredentials.UserID, userCredentials.SessionID, userCredentials.Permissions, userID, profile)
+	if err != nil {
+		userErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", entity, http.StatusOK)
+}
+
+func (s *server) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.ChangePassword"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req ChangePasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	err := s.userService.ChangePassword(ctx, userCredentials.MerchantID, userCredentials.UserID, userCredentials.SessionID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		userErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "password changed", nil, http.StatusOK)
+}
+
+func (s *server) SetPIN(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.SetPIN"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req SetPINRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if err := s.userService.SetPIN(ctx, userCredentials.MerchantID, userCredentials.UserID, req.PIN); err != nil {
+		userErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "PIN set", nil, http.StatusOK)
+}
+
+func userErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, user.ErrInvalidUpdateParameters), errors.Is(err, user.ErrInvalidPassword), errors.Is(err, user.ErrInvalidPINFormat):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, user.ErrWrongPassword):
+		FailedResponse(w, err, http.StatusUnauthorized)
+	case errors.Is(err, role.ErrInsufficientPermissions):
+		FailedResponse(w, err, http.StatusForbidden)
+	case errors.Is(err, user.ErrUserNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, user.ErrEmailNotUnique):
+		FailedResponse(w, err, http.StatusConflict)
+	default:
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/stretchr/testify/assert"
+)
+
+// getUsersService records the limit GetUsers is called with.
+type getUsersService struct {
+	Service
+	limit int
+}
+
+func (s *getUsersService) GetUsers(ctx context.Context, merchantID int, filter user.Filter, sort user.Sort, cursor string, limit int) ([]user.User, int, string, error) {
+	s.limit = limit
+	return nil, 0, "", nil
+}
+
+func TestServer_GetUsers_limit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		status   int
+		expected int
+	}{
+		{name: "default", query: "", status: http.StatusOK, expected: defaultPageLimit},
+		{name: "within the cap", query: "?limit=25", status: http.StatusOK, expected: 25},
+		{name: "capped", query: "?limit=1000", status: http.StatusOK, expected: maxPageLimit},
+		{name: "zero", query: "?limit=0", status: http.StatusBadRequest},
+		{name: "not a number", query: "?limit=all", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			userService := &getUsersService{}
+			s := &server{userService: userService}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/users"+tc.query, nil)
+			r = r.WithContext(context.WithValue(r.Context(), "user-credentials", TokenPayload{MerchantID: 1}))
+			w := httptest.NewRecorder()
+
+			s.GetUsers(w, r)
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.expected, userService.limit)
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package api
 
+import "github.com/mhdiiilham/POS/entity/role"
+
 type (
 	Response struct {
 		Code    int         `json:"code"`
@@ -8,9 +10,16 @@ type (
 		Error   error       `json:"error"`
 	}
 
+	// TokenPayload describes the caller. Requests made with an API key carry
+	// its APIKeyID and permissions, and act as the user who created the key.
 	TokenPayload struct {
-		UserID     int
-		MerchantID int
-		Email      string
+		UserID      int
+		MerchantID  int
+		APIKeyID    int
+		OutletID    int
+		Email       string
+		SessionID   string
+		Role        string
+		Permissions []role.Permission
 	}
 )
@@ -2,7 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func SuccessResponse(w http.ResponseWriter, msg string, data interface{}, httpCode int) {
@@ -47,3 +52,116 @@ func UnknownErrorResponse(w http.ResponseWriter, err error) {
 	w.WriteHeader(httpCode)
 	w.Write(j)
 }
+
+const (
+	defaultPageLimit = 10
+	// maxPageLimit caps how many items one list request returns; larger
+	// limits are lowered to it.
+	maxPageLimit = 100
+)
+
+// parseLimit reads the limit query parameter of the list endpoints. It must
+// be a positive number and is capped at maxPageLimit.
+func parseLimit(r *http.Request) (limit int, err error) {
+	limitQuery := r.URL.Query().Get("limit")
+	if limitQuery == "" {
+		return defaultPageLimit, nil
+	}
+
+	if limit, err = strconv.Atoi(limitQuery); err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return limit, nil
+}
+
+// parsePagination reads the page, limit and lastID query parameters shared by
+// the list endpoints, defaulting to the first page of ten items.
+func parsePagination(r *http.Request) (page, limit, lastID int, err error) {
+	page = 1
+	query := r.URL.Query()
+
+	if limit, err = parseLimit(r); err != nil {
+		return 0, 0, 0, err
+	}
+
+	if lastIDQuery := query.Get("lastID"); lastIDQuery != "" {
+		if lastID, err = strconv.Atoi(lastIDQuery); err != nil || lastID < 0 {
+			return 0, 0, 0, errors.New("invalid lastid")
+		}
+	}
+
+	if pageQuery := query.Get("page"); pageQuery != "" {
+		if page, err = strconv.Atoi(pageQuery); err != nil {
+			return 0, 0, 0, errors.New("invalid page")
+		}
+	}
+
+	return page, limit, lastID, nil
+}
+
+// clientIP is the address the request came from. A request from one of the
+// trusted proxies is traced back through X-Forwarded-For, right to left,
+// until an address that is not a trusted proxy; whatever a client put in the
+// header before that is ignored, as anyone can set it. Without trusted
+// proxies the header is never read.
+func (s *server) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0 && s.trustedProxy(ip); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip
+}
+
+func (s *server) trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range s.trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies reads the addresses of the reverse proxies in front of
+// the server, each an IP or a CIDR range.
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	proxies := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", v)
+			}
+
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			v = fmt.Sprintf("%s/%d", v, bits)
+		}
+
+		_, proxy, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
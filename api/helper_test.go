@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_clientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		proxies    bool
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{
+			name:       "no trusted proxies ignores the header",
+			remoteAddr: "203.0.113.7:51234",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer ignores the header",
+			proxies:    true,
+			remoteAddr: "203.0.113.7:51234",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy",
+			proxies:    true,
+			remoteAddr: "10.1.2.3:443",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "spoofed hops before the first untrusted one are ignored",
+			proxies:    true,
+			remoteAddr: "10.1.2.3:443",
+			forwarded:  []string{"1.1.1.1, 198.51.100.1", "192.0.2.1"},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "every hop trusted",
+			proxies:    true,
+			remoteAddr: "10.1.2.3:443",
+			forwarded:  []string{"10.9.9.9"},
+			expected:   "10.9.9.9",
+		},
+		{
+			name:       "garbage hop stops the walk",
+			proxies:    true,
+			remoteAddr: "10.1.2.3:443",
+			forwarded:  []string{"198.51.100.1, not-an-ip"},
+			expected:   "10.1.2.3",
+		},
+		{
+			name:       "trusted proxy without the header",
+			proxies:    true,
+			remoteAddr: "10.1.2.3:443",
+			expected:   "10.1.2.3",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &server{}
+			if tc.proxies {
+				s.trustedProxies = proxies
+			}
+
+			r := httptest.NewRequest("POST", "/api/login", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			assert.Equal(t, tc.expected, s.clientIP(r))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12", "2001:db8::1"})
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+	assert.Equal(t, "10.0.0.1/32", proxies[0].String())
+	assert.Equal(t, "172.16.0.0/12", proxies[1].String())
+	assert.Equal(t, "2001:db8::1/128", proxies[2].String())
+
+	_, err = ParseTrustedProxies([]string{"proxy.internal"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	AssignProductRequest struct {
+		ProductID int     `json:"productID"`
+		Price     float64 `json:"price"`
+		Stock     int     `json:"stock"`
+	}
+
+	SetPriceRequest struct {
+		Price float64 `json:"price"`
+	}
+
+	AdjustStockRequest struct {
+		Quantity int     `json:"quantity"`
+		Reason   string  `json:"reason"`
+		Note     *string `json:"note"`
+	}
+
+	AdjustStockResponse struct {
+		ProductID int `json:"productID"`
+		Stock     int `json:"stock"`
+	}
+
+	GetOutletProductsResponse struct {
+		Products  []inventory.OutletProduct `json:"products"`
+		Page      int                       `json:"page"`
+		TotalData int                       `json:"totalData"`
+	}
+)
+
+func (s *server) AssignProduct(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.AssignProduct"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req AssignProductRequest
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity, err := s.inventoryService.AssignProduct(ctx, userCredentials.MerchantID, userCredentials.UserID, inventory.OutletProduct{
+		OutletID:  outletID,
+		ProductID: req.ProductID,
+		Price:     req.Price,
+		Stock:     req.Stock,
+	})
+	if err != nil {
+		inventoryErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", entity, http.StatusCreated)
+}
+
+func (s *server) GetOutletProducts(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetOutletProducts"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	page, limit, lastID, err := parsePagination(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	products, totalData, err := s.inventoryService.GetOutletProducts(ctx, userCredentials.MerchantID, outletID, lastID, limit)
+	if err != nil {
+		inventoryErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if products == nil {
+		products = []inventory.OutletProduct{}
+	}
+
+	SuccessResponse(w, "data found", GetOutletProductsResponse{
+		Page:      page,
+		Products:  products,
+		TotalData: totalData,
+	}, http.StatusOK)
+}
+
+func (s *server) SetOutletProductPrice(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.SetOutletProductPrice"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req SetPriceRequest
+
+	outletID, productID, err := outletProductVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	err = s.inventoryService.SetPrice(ctx, userCredentials.MerchantID, outletID, productID, req.Price)
+	if err != nil {
+		inventoryErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", nil, http.StatusOK)
+}
+
+func (s *server) AdjustOutletProductStock(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.AdjustOutletProductStock"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req AdjustStockRequest
+
+	outletID, productID, err := outletProductVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	stock, err := s.inventoryService.AdjustStock(ctx, userCredentials.MerchantID, inventory.StockMovement{
+		OutletID:  outletID,
+		ProductID: productID,
+		UserID:    userCredentials.UserID,
+		Quantity:  req.Quantity,
+		Reason:    inventory.Reason(req.Reason),
+		Note:      req.Note,
+	})
+	if err != nil {
+		inventoryErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", AdjustStockResponse{ProductID: productID, Stock: stock}, http.StatusOK)
+}
+
+func outletProductVars(r *http.Request) (outletID, productID int, err error) {
+	vars := mux.Vars(r)
+
+	outletID, err = strconv.Atoi(vars["outletId"])
+	if err != nil {
+		return 0, 0, errors.New("invalid outlet id")
+	}
+
+	productID, err = strconv.Atoi(vars["productId"])
+	if err != nil {
+		return 0, 0, errors.New("invalid product id")
+	}
+
+	return outletID, productID, nil
+}
+
+func inventoryErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, inventory.ErrInvalidInventoryParameters), errors.Is(err, inventory.ErrInvalidStockReason):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, outlet.ErrOutletNotFound), errors.Is(err, product.ErrProductNotFound), errors.Is(err, inventory.ErrOutletProductNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, inventory.ErrProductAlreadyAssigned), errors.Is(err, inventory.ErrInsufficientStock):
+		FailedResponse(w, err, http.StatusConflict)
+	default:
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/invitation"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	InviteRequest struct {
+		Email     string `json:"email"`
+		FirstName string `json:"firstname"`
+		LastName  string `json:"lastname"`
+		RoleID    int    `json:"roleID"`
+	}
+
+	AcceptInvitationRequest struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+)
+
+func (s *server) Invite(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.Invite"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req InviteRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	invitee := user.User{
+		MerchantID: userCredentials.MerchantID,
+		Email:      req.Email,
+		FirstName:  req.FirstName,
+		LastName:   &req.LastName,
+		RoleID:     req.RoleID,
+	}
+
+	created, err := s.invitationService.Invite(ctx, userCredentials.Permissions, userCredentials.UserID, invitee)
+	if err != nil {
+		invitationErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	logger.Info(ctx, ops, "invitation %d sent by user %d", created.ID, userCredentials.UserID)
+	SuccessResponse(w, "invitation sent", created, http.StatusCreated)
+}
+
+func (s *server) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetInvitations"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	invitations, err := s.invitationService.GetInvitations(ctx, userCredentials.MerchantID)
+	if err != nil {
+		invitationErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if invitations == nil {
+		invitations = []invitation.Invitation{}
+	}
+
+	SuccessResponse(w, "data found", invitations, http.StatusOK)
+}
+
+func (s *server) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.ResendInvitation"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	invitationID, err := strconv.Atoi(mux.Vars(r)["invitationId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid invitation id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.invitationService.ResendInvitation(ctx, userCredentials.MerchantID, invitationID)
+	if err != nil {
+		invitationErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "invitation resent", entity, http.StatusOK)
+}
+
+func (s *server) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RevokeInvitation"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	invitationID, err := strconv.Atoi(mux.Vars(r)["invitationId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid invitation id"), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.invitationService.RevokeInvitation(ctx, userCredentials.MerchantID, invitationID); err != nil {
+		invitationErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "invitation revoked", nil, http.StatusOK)
+}
+
+func (s *server) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.AcceptInvitation"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req AcceptInvitationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if err := s.invitationService.AcceptInvitation(ctx, req.Token, req.Password); err != nil {
+		invitationErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "invitation accepted, you can now log in", nil, http.StatusOK)
+}
+
+func invitationErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, user.ErrInvalidCreateParameters), errors.Is(err, user.ErrInvalidPassword), errors.Is(err, invitation.ErrInvalidInviteToken):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, role.ErrInsufficientPermissions):
+		FailedResponse(w, err, http.StatusForbidden)
+	case errors.Is(err, invitation.ErrInvitationNotFound), errors.Is(err, role.ErrRoleNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, user.ErrEmailNotUnique):
+		FailedResponse(w, err, http.StatusConflict)
+	default:
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JWKS publishes the keys that verify our access tokens, so other services
+// can check them without a shared secret. It answers with a bare key set
+// rather than the usual envelope, as JWKS clients expect.
+func (s *server) JWKS(w http.ResponseWriter, r *http.Request) {
+	JSON, err := json.Marshal(s.tokenSigner.JWKS())
+	if err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(JSON)
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/session"
 	"github.com/mhdiiilham/POS/entity/user"
 	"github.com/mhdiiilham/POS/pkg/logger"
 )
@@ -15,6 +19,7 @@ import (
 type (
 	LoginResponse struct {
 		AccessToken    string    `json:"accessToken"`
+		RefreshToken   string    `json:"refreshToken"`
 		TokenType      string    `json:"tokenType"`
 		TokenExpiresIn time.Time `json:"tokenExpiresIn"`
 	}
@@ -23,6 +28,11 @@ type (
 		Email    string `json:"email"`
 		Passwrod string `json:"password"`
 	}
+
+	PINLoginRequest struct {
+		UserID int    `json:"userID"`
+		PIN    string `json:"pin"`
+	}
 )
 
 func (s *server) Login(w http.ResponseWriter, r *http.Request) {
@@ -42,23 +52,89 @@ func (s *server) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := s.userService.Login(ctx, req.Email, req.Passwrod)
+	token, challenge, err := s.userService.Login(ctx, req.Email, req.Passwrod, s.clientIP(r))
 	if err != nil {
-		if errors.Is(err, user.ErrInvalidEmailAndPasword) {
+		var lockout *user.LockoutError
+		switch {
+		case errors.As(err, &lockout):
+			lockedOutResponse(w, lockout)
+		case errors.Is(err, user.ErrInvalidEmailAndPasword):
 			FailedResponse(w, err, http.StatusBadRequest)
-			return
+		default:
+			logger.Error(ctx, ops, "unknown: %v", err)
+			UnknownErrorResponse(w, err)
 		}
+		return
+	}
 
-		logger.Error(ctx, ops, "unknown: %v", err)
-		UnknownErrorResponse(w, err)
+	if challenge != nil {
+		logger.Info(ctx, ops, "user %s passed password step", req.Email)
+		SuccessResponse(w, "two-factor authentication required", TwoFactorChallengeResponse{
+			ChallengeToken:     challenge.Token,
+			ExpiresAt:          challenge.ExpiresAt,
+			EnrollmentRequired: challenge.EnrollmentRequired,
+		}, http.StatusOK)
 		return
 	}
 
 	logger.Info(ctx, ops, "user %s login", req.Email)
-	resp := LoginResponse{
-		AccessToken:    accessToken,
+	SuccessResponse(w, "login success", newLoginResponse(token), http.StatusOK)
+}
+
+// PINLogin is the quick login of outlet terminals. The token it returns only
+// works for that outlet.
+func (s *server) PINLogin(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.PINLogin"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req PINLoginRequest
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	token, err := s.userService.PINLogin(ctx, outletID, req.UserID, req.PIN, s.clientIP(r))
+	if err != nil {
+		var lockout *user.LockoutError
+		switch {
+		case errors.As(err, &lockout):
+			lockedOutResponse(w, lockout)
+		case errors.Is(err, user.ErrInvalidPIN):
+			FailedResponse(w, err, http.StatusUnauthorized)
+		default:
+			logger.Error(ctx, ops, "unknown: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, ops, "user %d PIN login at outlet %d", req.UserID, outletID)
+	SuccessResponse(w, "login success", newLoginResponse(token), http.StatusOK)
+}
+
+// lockedOutResponse answers 429 and tells the client when to retry.
+func lockedOutResponse(w http.ResponseWriter, lockout *user.LockoutError) {
+	retryAfter := int(math.Ceil(time.Until(lockout.Until).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	FailedResponse(w, lockout, http.StatusTooManyRequests)
+}
+
+func newLoginResponse(token session.Token) LoginResponse {
+	return LoginResponse{
+		AccessToken:    token.AccessToken,
+		RefreshToken:   token.RefreshToken,
 		TokenType:      "Bearer",
-		TokenExpiresIn: time.Now().Add(12 * time.Hour),
+		TokenExpiresIn: token.ExpiresAt,
 	}
-	SuccessResponse(w, "login success", resp, http.StatusOK)
 }
@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/gorilla/mux"
+
+	"github.com/mhdiiilham/POS/entity/apikey"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
 	"github.com/mhdiiilham/POS/pkg/logger"
 )
 
@@ -14,6 +20,11 @@ func (s *server) authorization(next http.Handler) http.Handler {
 		const ops = "api.server.authorization"
 		authorizationHeader := r.Header.Get("Authorization")
 
+		if strings.HasPrefix(authorizationHeader, "ApiKey ") {
+			s.apiKeyAuthorization(next, w, r, strings.TrimPrefix(authorizationHeader, "ApiKey "))
+			return
+		}
+
 		if !strings.Contains(authorizationHeader, "Bearer") {
 			err := errors.New("unauthorized")
 			FailedResponse(w, err, http.StatusUnauthorized)
@@ -23,7 +34,7 @@ func (s *server) authorization(next http.Handler) http.Handler {
 		signedToken := strings.Replace(authorizationHeader, "Bearer ", "", -1)
 		claims, err := s.tokenSigner.Extract(r.Context(), signedToken)
 		if err != nil {
-			UnknownErrorResponse(w, err)
+			FailedResponse(w, errors.New("unauthorized"), http.StatusUnauthorized)
 			return
 		}
 
@@ -46,10 +57,46 @@ func (s *server) authorization(next http.Handler) http.Handler {
 			return
 		}
 
+		sessionID, sessionIDCastErr := claims["sid"].(string)
+		if !sessionIDCastErr {
+			FailedResponse(w, errors.New("unauthorized"), http.StatusUnauthorized)
+			return
+		}
+
+		roleName, _ := claims["role"].(string)
+		outletID, _ := claims["outletID"].(float64)
+		perms, permsCastErr := claims["perms"].([]interface{})
+		if !permsCastErr {
+			FailedResponse(w, errors.New("unauthorized"), http.StatusUnauthorized)
+			return
+		}
+
+		permissions := make([]role.Permission, 0, len(perms))
+		for _, p := range perms {
+			if permission, ok := p.(string); ok {
+				permissions = append(permissions, role.Permission(permission))
+			}
+		}
+
+		if err := s.sessionService.Authenticate(r.Context(), sessionID, int(userID)); err != nil {
+			if errors.Is(err, session.ErrSessionRevoked) {
+				FailedResponse(w, err, http.StatusUnauthorized)
+				return
+			}
+
+			logger.Error(context.Background(), ops, "error authenticating session: %v", err)
+			UnknownErrorResponse(w, err)
+			return
+		}
+
 		data := TokenPayload{
-			UserID:     int(userID),
-			MerchantID: int(merchantID),
-			Email:      userEmail,
+			UserID:      int(userID),
+			MerchantID:  int(merchantID),
+			OutletID:    int(outletID),
+			Email:       userEmail,
+			SessionID:   sessionID,
+			Role:        roleName,
+			Permissions: permissions,
 		}
 
 		ctx := context.WithValue(r.Context(), "user-credentials", data)
@@ -57,3 +104,66 @@ func (s *server) authorization(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// apiKeyAuthorization is authorization for the "ApiKey <key>" scheme used by
+// integrations.
+func (s *server) apiKeyAuthorization(next http.Handler, w http.ResponseWriter, r *http.Request, key string) {
+	const ops = "api.server.apiKeyAuthorization"
+
+	entity, err := s.apiKeyService.Authenticate(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, apikey.ErrInvalidAPIKey) {
+			FailedResponse(w, errors.New("unauthorized"), http.StatusUnauthorized)
+			return
+		}
+
+		logger.Error(context.Background(), ops, "error authenticating API key: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	data := TokenPayload{
+		UserID:      entity.CreatedBy,
+		MerchantID:  entity.MerchantID,
+		APIKeyID:    entity.ID,
+		Permissions: entity.Permissions,
+	}
+
+	ctx := context.WithValue(r.Context(), "user-credentials", data)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// userOnly keeps API keys away from routes that act on the caller's own
+// account. It must run behind authorization.
+func (s *server) userOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		credentials, ok := r.Context().Value("user-credentials").(TokenPayload)
+		if !ok || credentials.APIKeyID != 0 {
+			FailedResponse(w, role.ErrForbidden, http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// require lets the request through only when the token grants permission,
+// and, for tokens limited to an outlet, only on that outlet's routes. It must
+// run behind authorization.
+func (s *server) require(permission role.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		credentials, ok := r.Context().Value("user-credentials").(TokenPayload)
+		if !ok || !role.Has(credentials.Permissions, permission) {
+			FailedResponse(w, role.ErrForbidden, http.StatusForbidden)
+			return
+		}
+
+		if outletID, scoped := mux.Vars(r)["outletId"]; credentials.OutletID != 0 && scoped && outletID != strconv.Itoa(credentials.OutletID) {
+			FailedResponse(w, role.ErrForbidden, http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
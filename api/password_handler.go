@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mhdiiilham/POS/entity/passwordreset"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	ForgotPasswordRequest struct {
+		Email string `json:"email"`
+	}
+
+	ResetPasswordRequest struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+)
+
+func (s *server) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.ForgotPassword"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req ForgotPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if req.Email == "" {
+		FailedResponse(w, user.ErrEmptyEmailAndPassword, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.passwordService.ForgotPassword(ctx, req.Email, s.clientIP(r)); err != nil {
+		var lockout *user.LockoutError
+		if errors.As(err, &lockout) {
+			lockedOutResponse(w, lockout)
+			return
+		}
+
+		logger.Error(ctx, ops, "unknown: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	SuccessResponse(w, "if the email is registered, a reset link has been sent", nil, http.StatusAccepted)
+}
+
+func (s *server) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.ResetPassword"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req ResetPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if err := s.passwordService.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, passwordreset.ErrInvalidResetToken), errors.Is(err, user.ErrInvalidPassword):
+			FailedResponse(w, err, http.StatusBadRequest)
+		default:
+			logger.Error(ctx, ops, "unknown: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	SuccessResponse(w, "password has been reset", nil, http.StatusOK)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	SetPaymentMethodRequest struct {
+		Enabled bool `json:"enabled"`
+	}
+)
+
+func (s *server) GetPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetPaymentMethods"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	methods, err := s.paymentService.GetPaymentMethods(ctx, userCredentials.MerchantID, outletID)
+	if err != nil {
+		paymentErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", methods, http.StatusOK)
+}
+
+func (s *server) SetPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.SetPaymentMethod"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req SetPaymentMethodRequest
+
+	vars := mux.Vars(r)
+	outletID, err := strconv.Atoi(vars["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := payment.OutletPaymentMethod{
+		OutletID: outletID,
+		Method:   payment.Method(vars["method"]),
+		Enabled:  req.Enabled,
+	}
+	if err = s.paymentService.SetPaymentMethod(ctx, userCredentials.MerchantID, entity); err != nil {
+		paymentErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", entity, http.StatusOK)
+}
+
+func paymentErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, payment.ErrInvalidPaymentMethod):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, outlet.ErrOutletNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	default:
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/refund"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	VoidSaleRequest struct {
+		Reason string  `json:"reason"`
+		Note   *string `json:"note"`
+	}
+
+	RefundItemRequest struct {
+		ProductID int `json:"productID"`
+		Quantity  int `json:"quantity"`
+	}
+
+	RefundSaleRequest struct {
+		Reason string              `json:"reason"`
+		Note   *string             `json:"note"`
+		Items  []RefundItemRequest `json:"items"`
+	}
+)
+
+func (s *server) VoidSale(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.VoidSale"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req VoidSaleRequest
+
+	outletID, saleID, err := outletSaleVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity, err := s.refundService.VoidSale(ctx, refund.Refund{
+		SaleID:     saleID,
+		MerchantID: userCredentials.MerchantID,
+		OutletID:   outletID,
+		UserID:     userCredentials.UserID,
+		Reason:     refund.ReasonCode(req.Reason),
+		Note:       req.Note,
+	})
+	if err != nil {
+		refundErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "sale voided", entity, http.StatusCreated)
+}
+
+func (s *server) RefundSale(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RefundSale"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req RefundSaleRequest
+
+	outletID, saleID, err := outletSaleVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	items := make([]refund.Item, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, refund.Item{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	entity, err := s.refundService.RefundSale(ctx, refund.Refund{
+		SaleID:     saleID,
+		MerchantID: userCredentials.MerchantID,
+		OutletID:   outletID,
+		UserID:     userCredentials.UserID,
+		Reason:     refund.ReasonCode(req.Reason),
+		Note:       req.Note,
+		Items:      items,
+	})
+	if err != nil {
+		refundErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "sale refunded", entity, http.StatusCreated)
+}
+
+func (s *server) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetRefunds"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, saleID, err := outletSaleVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	refunds, err := s.refundService.GetRefunds(ctx, userCredentials.MerchantID, outletID, saleID)
+	if err != nil {
+		refundErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if refunds == nil {
+		refunds = []refund.Refund{}
+	}
+
+	SuccessResponse(w, "data found", refunds, http.StatusOK)
+}
+
+// RetryRefund pays back what the gateway failed to refund of a recorded
+// refund.
+func (s *server) RetryRefund(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RetryRefund"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, saleID, err := outletSaleVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	refundID, err := strconv.Atoi(mux.Vars(r)["refundId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid refund id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.refundService.RetryRefund(ctx, userCredentials.MerchantID, outletID, saleID, refundID)
+	if err != nil {
+		refundErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "refund settled", entity, http.StatusOK)
+}
+
+func refundErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, refund.ErrInvalidReason),
+		errors.Is(err, refund.ErrEmptyRefund),
+		errors.Is(err, refund.ErrInvalidRefundItem),
+		errors.Is(err, refund.ErrItemNotInSale):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, sale.ErrSaleNotFound), errors.Is(err, shift.ErrShiftNotFound),
+		errors.Is(err, refund.ErrRefundNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, refund.ErrRefundExceedsSold),
+		errors.Is(err, refund.ErrSaleAlreadyVoided),
+		errors.Is(err, refund.ErrSaleAlreadyRefunded),
+		errors.Is(err, refund.ErrVoidWindowExpired),
+		errors.Is(err, shift.ErrNoOpenShift):
+		FailedResponse(w, err, http.StatusConflict)
+	case errors.Is(err, inventory.ErrOutletProductNotFound):
+		FailedResponse(w, err, http.StatusUnprocessableEntity)
+	case errors.Is(err, refund.ErrGatewayRefundFailed):
+		FailedResponse(w, err, http.StatusBadGateway)
+	default:
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mhdiiilham/POS/entity/merchant"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	RegisterRequest struct {
+		MerchantName string  `json:"merchantName"`
+		MerchantLogo *string `json:"merchantLogo"`
+		Email        string  `json:"email"`
+		Password     string  `json:"password"`
+		FirstName    string  `json:"firstname"`
+		LastName     string  `json:"lastname"`
+	}
+
+	RegisterResponse struct {
+		Merchant merchant.Merchant `json:"merchant"`
+		User     user.User         `json:"user"`
+		LoginResponse
+	}
+)
+
+func (s *server) Register(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.Register"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req RegisterRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := merchant.Merchant{
+		Name: req.MerchantName,
+		Logo: req.MerchantLogo,
+	}
+	owner := user.User{
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  &req.LastName,
+		Password:  req.Password,
+	}
+
+	entity, owner, token, err := s.merchantService.Register(ctx, entity, owner)
+	if err != nil {
+		switch {
+		case errors.Is(err, merchant.ErrInvalidMerchantParameters), errors.Is(err, user.ErrInvalidCreateParameters), errors.Is(err, user.ErrInvalidPassword):
+			FailedResponse(w, err, http.StatusBadRequest)
+		case errors.Is(err, user.ErrEmailNotUnique):
+			FailedResponse(w, err, http.StatusConflict)
+		default:
+			logger.Error(ctx, ops, "unknown: %v", err)
+			UnknownErrorResponse(w, err)
+		}
+		return
+	}
+
+	logger.Info(ctx, ops, "merchant %d registered by %s", entity.ID, owner.Email)
+	SuccessResponse(w, "register success", RegisterResponse{
+		Merchant:      entity,
+		User:          owner,
+		LoginResponse: newLoginResponse(token),
+	}, http.StatusCreated)
+}
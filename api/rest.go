@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -10,32 +11,205 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/apikey"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/invitation"
+	"github.com/mhdiiilham/POS/entity/merchant"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/entity/refund"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/entity/twofactor"
 	"github.com/mhdiiilham/POS/entity/user"
 	"github.com/mhdiiilham/POS/pkg/logger"
+	"github.com/mhdiiilham/POS/pkg/token"
 	"github.com/rs/cors"
 )
 
 type (
 	tokenSigner interface {
 		Extract(ctx context.Context, signedToken string) (jwt.MapClaims, error)
+		JWKS() token.JSONWebKeySet
 	}
 
 	Service interface {
-		Login(ctx context.Context, email, password string) (accessToken string, err error)
-		CreateUser(ctx context.Context, entity user.User) (userID int, err error)
-		GetUsers(ctx context.Context, merchantID, lastID, limit int) (users []user.User, totalData int, err error)
-		DeleteUser(ctx context.Context, userID int) error
-		GetUser(ctx context.Context, userID int) (entity user.User, err error)
+		Login(ctx context.Context, email, password, clientIP string) (token session.Token, challenge *twofactor.LoginChallenge, err error)
+		CreateUser(ctx context.Context, grantor []role.Permission, entity user.User) (userID int, err error)
+		GetUsers(ctx context.Context, merchantID int, filter user.Filter, sort user.Sort, cursor string, limit int) (users []user.User, totalData int, nextCursor string, err error)
+		DeleteUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) error
+		RestoreUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) (err error)
+		PurgeUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) (err error)
+		GetUser(ctx context.Context, merchantID, userID int) (entity user.User, err error)
+		AssignRole(ctx context.Context, merchantID, grantorID int, grantor []role.Permission, userID, roleID int) (err error)
+		UpdateUser(ctx context.Context, merchantID, grantorID int, sessionID string, grantor []role.Permission, userID int, profile user.Profile) (entity user.User, err error)
+		ChangePassword(ctx context.Context, merchantID, userID int, sessionID, currentPassword, newPassword string) (err error)
+		SetPIN(ctx context.Context, merchantID, userID int, pin string) (err error)
+		PINLogin(ctx context.Context, outletID, userID int, pin, clientIP string) (token session.Token, err error)
+		UnlockUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) (err error)
+	}
+
+	PasswordService interface {
+		ForgotPassword(ctx context.Context, email, clientIP string) (err error)
+		ResetPassword(ctx context.Context, token, newPassword string) (err error)
+	}
+
+	TwoFactorService interface {
+		Enroll(ctx context.Context, merchantID, userID int) (setup twofactor.Setup, err error)
+		ConfirmEnrollment(ctx context.Context, userID int, code string) (err error)
+		Disable(ctx context.Context, merchantID, userID int, code string) (err error)
+		EnrollForLogin(ctx context.Context, challengeToken string) (setup twofactor.Setup, err error)
+		VerifyLogin(ctx context.Context, challengeToken, code string) (token session.Token, err error)
+	}
+
+	InvitationService interface {
+		Invite(ctx context.Context, grantor []role.Permission, invitedBy int, invitee user.User) (created invitation.Invitation, err error)
+		GetInvitations(ctx context.Context, merchantID int) (invitations []invitation.Invitation, err error)
+		ResendInvitation(ctx context.Context, merchantID, invitationID int) (entity invitation.Invitation, err error)
+		RevokeInvitation(ctx context.Context, merchantID, invitationID int) (err error)
+		AcceptInvitation(ctx context.Context, token, password string) (err error)
+	}
+
+	APIKeyService interface {
+		CreateAPIKey(ctx context.Context, grantor []role.Permission, entity apikey.Key) (created apikey.Key, key string, err error)
+		GetAPIKeys(ctx context.Context, merchantID int) (keys []apikey.Key, err error)
+		RevokeAPIKey(ctx context.Context, merchantID, keyID int) (err error)
+		Authenticate(ctx context.Context, key string) (entity apikey.Key, err error)
+	}
+
+	RoleService interface {
+		CreateRole(ctx context.Context, grantor []role.Permission, entity role.Role) (roleID int, err error)
+		GetRoles(ctx context.Context, merchantID int) (roles []role.Role, err error)
+		GetRole(ctx context.Context, merchantID, roleID int) (entity role.Role, err error)
+		UpdateRole(ctx context.Context, grantor []role.Permission, entity role.Role) (err error)
+		DeleteRole(ctx context.Context, grantor []role.Permission, merchantID, roleID int) (err error)
+	}
+
+	SessionService interface {
+		Refresh(ctx context.Context, refreshToken string) (token session.Token, err error)
+		Logout(ctx context.Context, sessionID string) (err error)
+		Authenticate(ctx context.Context, sessionID string, userID int) (err error)
+	}
+
+	MerchantService interface {
+		CreateMerchant(ctx context.Context, entity merchant.Merchant) (merchantID int, err error)
+		GetMerchants(ctx context.Context, lastID, limit int) (merchants []merchant.Merchant, totalData int, err error)
+		GetMerchant(ctx context.Context, requesterMerchantID, merchantID int) (entity merchant.Merchant, err error)
+		UpdateMerchant(ctx context.Context, requesterMerchantID int, entity merchant.Merchant) error
+		Register(ctx context.Context, entity merchant.Merchant, owner user.User) (registered merchant.Merchant, createdOwner user.User, token session.Token, err error)
+	}
+
+	OutletService interface {
+		CreateOutlet(ctx context.Context, entity outlet.Outlet) (outletID int, err error)
+		GetOutlets(ctx context.Context, merchantID, lastID, limit int) (outlets []outlet.Outlet, totalData int, err error)
+		GetOutlet(ctx context.Context, merchantID, outletID int) (entity outlet.Outlet, err error)
+		UpdateOutlet(ctx context.Context, entity outlet.Outlet) error
+		DeleteOutlet(ctx context.Context, merchantID, outletID int) error
+	}
+
+	ProductService interface {
+		CreateProduct(ctx context.Context, entity product.Product) (productID int, err error)
+		GetProducts(ctx context.Context, merchantID, lastID, limit int) (products []product.Product, totalData int, err error)
+		GetProduct(ctx context.Context, merchantID, productID int) (entity product.Product, err error)
+		UpdateProduct(ctx context.Context, entity product.Product) error
+		DeleteProduct(ctx context.Context, merchantID, productID int) error
+	}
+
+	InventoryService interface {
+		AssignProduct(ctx context.Context, merchantID, userID int, entity inventory.OutletProduct) (assigned inventory.OutletProduct, err error)
+		GetOutletProducts(ctx context.Context, merchantID, outletID, lastID, limit int) (products []inventory.OutletProduct, totalData int, err error)
+		SetPrice(ctx context.Context, merchantID, outletID, productID int, price float64) error
+		AdjustStock(ctx context.Context, merchantID int, movement inventory.StockMovement) (stock int, err error)
+	}
+
+	SaleService interface {
+		Checkout(ctx context.Context, merchantID, cashierID, outletID int, items []sale.Item, payments []payment.Payment) (entity sale.Sale, err error)
+		GetSales(ctx context.Context, merchantID, outletID, lastID, limit int) (sales []sale.Sale, totalData int, err error)
+		GetSale(ctx context.Context, merchantID, saleID int) (entity sale.Sale, err error)
+	}
+
+	RefundService interface {
+		VoidSale(ctx context.Context, entity refund.Refund) (voided refund.Refund, err error)
+		RefundSale(ctx context.Context, entity refund.Refund) (refunded refund.Refund, err error)
+		GetRefunds(ctx context.Context, merchantID, outletID, saleID int) (refunds []refund.Refund, err error)
+		RetryRefund(ctx context.Context, merchantID, outletID, saleID, refundID int) (retried refund.Refund, err error)
+	}
+
+	ShiftService interface {
+		OpenShift(ctx context.Context, merchantID, userID, outletID int, openingFloat float64) (entity shift.Shift, err error)
+		CloseShift(ctx context.Context, merchantID, userID, outletID, shiftID int, countedCash float64, note *string) (entity shift.Shift, err error)
+		GetCurrentShift(ctx context.Context, merchantID, userID, outletID int) (entity shift.Shift, err error)
+		GetShifts(ctx context.Context, merchantID, outletID, lastID, limit int) (shifts []shift.Shift, totalData int, err error)
+		GetShift(ctx context.Context, merchantID, outletID, shiftID int) (entity shift.Shift, err error)
+	}
+
+	PaymentService interface {
+		GetPaymentMethods(ctx context.Context, merchantID, outletID int) (methods []payment.OutletPaymentMethod, err error)
+		SetPaymentMethod(ctx context.Context, merchantID int, entity payment.OutletPaymentMethod) (err error)
 	}
 )
 
 type server struct {
-	userService Service
-	tokenSigner tokenSigner
+	userService       Service
+	sessionService    SessionService
+	roleService       RoleService
+	passwordService   PasswordService
+	invitationService InvitationService
+	twoFactorService  TwoFactorService
+	apiKeyService     APIKeyService
+	merchantService   MerchantService
+	outletService     OutletService
+	productService    ProductService
+	inventoryService  InventoryService
+	saleService       SaleService
+	refundService     RefundService
+	shiftService      ShiftService
+	paymentService    PaymentService
+	tokenSigner       tokenSigner
+	trustedProxies    []*net.IPNet
 }
 
-func NewPOSServer(userService Service, tokenSigner tokenSigner) *server {
-	return &server{userService: userService, tokenSigner: tokenSigner}
+func NewPOSServer(
+	userService Service,
+	sessionService SessionService,
+	roleService RoleService,
+	passwordService PasswordService,
+	invitationService InvitationService,
+	twoFactorService TwoFactorService,
+	apiKeyService APIKeyService,
+	merchantService MerchantService,
+	outletService OutletService,
+	productService ProductService,
+	inventoryService InventoryService,
+	saleService SaleService,
+	refundService RefundService,
+	shiftService ShiftService,
+	paymentService PaymentService,
+	tokenSigner tokenSigner,
+	trustedProxies []*net.IPNet,
+) *server {
+	return &server{
+		userService:       userService,
+		sessionService:    sessionService,
+		roleService:       roleService,
+		passwordService:   passwordService,
+		invitationService: invitationService,
+		twoFactorService:  twoFactorService,
+		apiKeyService:     apiKeyService,
+		merchantService:   merchantService,
+		outletService:     outletService,
+		productService:    productService,
+		inventoryService:  inventoryService,
+		saleService:       saleService,
+		refundService:     refundService,
+		shiftService:      shiftService,
+		paymentService:    paymentService,
+		tokenSigner:       tokenSigner,
+		trustedProxies:    trustedProxies,
+	}
 }
 
 func (s *server) Routes(ctx context.Context) http.Handler {
@@ -45,14 +219,98 @@ func (s *server) Routes(ctx context.Context) http.Handler {
 	mux := mux.NewRouter()
 
 	mux.Use(s.APIMiddleware())
+	mux.HandleFunc("/.well-known/jwks.json", s.JWKS).Methods(http.MethodGet)
 	mux.HandleFunc("/api/login", s.Login).Methods(http.MethodPost)
+	mux.HandleFunc("/api/login/2fa", s.VerifyLogin).Methods(http.MethodPost)
+	mux.HandleFunc("/api/login/2fa/enroll", s.EnrollForLogin).Methods(http.MethodPost)
+	mux.HandleFunc("/api/register", s.Register).Methods(http.MethodPost)
+	mux.HandleFunc("/api/token/refresh", s.RefreshToken).Methods(http.MethodPost)
+	mux.HandleFunc("/api/password/forgot", s.ForgotPassword).Methods(http.MethodPost)
+	mux.HandleFunc("/api/password/reset", s.ResetPassword).Methods(http.MethodPost)
+	mux.HandleFunc("/api/invitations/accept", s.AcceptInvitation).Methods(http.MethodPost)
+	mux.Handle("/api/logout", s.authorization(s.userOnly(s.Logout))).Methods(http.MethodPost)
 
 	userAPI := mux.PathPrefix("/api/users").Subrouter()
 	userAPI.Use(s.authorization)
-	userAPI.HandleFunc("", s.CreateUser).Methods(http.MethodPost)
-	userAPI.HandleFunc("", s.GetUsers).Methods(http.MethodGet)
-	userAPI.HandleFunc("/{userId}", s.RemoveUser).Methods(http.MethodDelete)
-	userAPI.HandleFunc("/{userId}", s.GetUser).Methods(http.MethodGet)
+	userAPI.HandleFunc("", s.require(role.PermissionUserWrite, s.CreateUser)).Methods(http.MethodPost)
+	userAPI.HandleFunc("", s.require(role.PermissionUserRead, s.GetUsers)).Methods(http.MethodGet)
+	userAPI.HandleFunc("/me", s.userOnly(s.UpdateUser)).Methods(http.MethodPatch)
+	userAPI.HandleFunc("/me/password", s.userOnly(s.ChangePassword)).Methods(http.MethodPut)
+	userAPI.HandleFunc("/me/pin", s.userOnly(s.SetPIN)).Methods(http.MethodPut)
+	userAPI.HandleFunc("/me/2fa", s.userOnly(s.EnrollTwoFactor)).Methods(http.MethodPost)
+	userAPI.HandleFunc("/me/2fa/confirm", s.userOnly(s.ConfirmTwoFactor)).Methods(http.MethodPost)
+	userAPI.HandleFunc("/me/2fa", s.userOnly(s.DisableTwoFactor)).Methods(http.MethodDelete)
+	userAPI.HandleFunc("/{userId}", s.require(role.PermissionUserWrite, s.RemoveUser)).Methods(http.MethodDelete)
+	userAPI.HandleFunc("/{userId}", s.require(role.PermissionUserRead, s.GetUser)).Methods(http.MethodGet)
+	userAPI.HandleFunc("/{userId}", s.require(role.PermissionUserWrite, s.UpdateUser)).Methods(http.MethodPatch)
+	userAPI.HandleFunc("/{userId}/role", s.require(role.PermissionUserWrite, s.AssignRole)).Methods(http.MethodPut)
+	userAPI.HandleFunc("/{userId}/unlock", s.require(role.PermissionUserWrite, s.UnlockUser)).Methods(http.MethodPost)
+	userAPI.HandleFunc("/{userId}/restore", s.require(role.PermissionUserWrite, s.RestoreUser)).Methods(http.MethodPost)
+	userAPI.HandleFunc("/{userId}/purge", s.require(role.PermissionUserPurge, s.userOnly(s.PurgeUser))).Methods(http.MethodPost)
+
+	invitationAPI := mux.PathPrefix("/api/invitations").Subrouter()
+	invitationAPI.Use(s.authorization)
+	invitationAPI.HandleFunc("", s.require(role.PermissionUserWrite, s.Invite)).Methods(http.MethodPost)
+	invitationAPI.HandleFunc("", s.require(role.PermissionUserRead, s.GetInvitations)).Methods(http.MethodGet)
+	invitationAPI.HandleFunc("/{invitationId}/resend", s.require(role.PermissionUserWrite, s.ResendInvitation)).Methods(http.MethodPost)
+	invitationAPI.HandleFunc("/{invitationId}", s.require(role.PermissionUserWrite, s.RevokeInvitation)).Methods(http.MethodDelete)
+
+	roleAPI := mux.PathPrefix("/api/roles").Subrouter()
+	roleAPI.Use(s.authorization)
+	roleAPI.HandleFunc("", s.require(role.PermissionRoleManage, s.CreateRole)).Methods(http.MethodPost)
+	roleAPI.HandleFunc("", s.require(role.PermissionUserRead, s.GetRoles)).Methods(http.MethodGet)
+	roleAPI.HandleFunc("/{roleId}", s.require(role.PermissionUserRead, s.GetRole)).Methods(http.MethodGet)
+	roleAPI.HandleFunc("/{roleId}", s.require(role.PermissionRoleManage, s.UpdateRole)).Methods(http.MethodPut)
+	roleAPI.HandleFunc("/{roleId}", s.require(role.PermissionRoleManage, s.RemoveRole)).Methods(http.MethodDelete)
+
+	apiKeyAPI := mux.PathPrefix("/api/api-keys").Subrouter()
+	apiKeyAPI.Use(s.authorization)
+	apiKeyAPI.HandleFunc("", s.require(role.PermissionAPIKeyManage, s.CreateAPIKey)).Methods(http.MethodPost)
+	apiKeyAPI.HandleFunc("", s.require(role.PermissionAPIKeyManage, s.GetAPIKeys)).Methods(http.MethodGet)
+	apiKeyAPI.HandleFunc("/{keyId}", s.require(role.PermissionAPIKeyManage, s.RevokeAPIKey)).Methods(http.MethodDelete)
+
+	merchantAPI := mux.PathPrefix("/api/merchants").Subrouter()
+	merchantAPI.Use(s.authorization)
+	merchantAPI.HandleFunc("", s.require(role.PermissionPlatformAdmin, s.CreateMerchant)).Methods(http.MethodPost)
+	merchantAPI.HandleFunc("", s.require(role.PermissionPlatformAdmin, s.GetMerchants)).Methods(http.MethodGet)
+	merchantAPI.HandleFunc("/{merchantId}", s.require(role.PermissionMerchantRead, s.GetMerchant)).Methods(http.MethodGet)
+	merchantAPI.HandleFunc("/{merchantId}", s.require(role.PermissionMerchantManage, s.UpdateMerchant)).Methods(http.MethodPut)
+
+	mux.HandleFunc("/api/outlets/{outletId}/pin-login", s.PINLogin).Methods(http.MethodPost)
+
+	outletAPI := mux.PathPrefix("/api/outlets").Subrouter()
+	outletAPI.Use(s.authorization)
+	outletAPI.HandleFunc("", s.require(role.PermissionOutletWrite, s.CreateOutlet)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("", s.require(role.PermissionOutletRead, s.GetOutlets)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}", s.require(role.PermissionOutletRead, s.GetOutlet)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}", s.require(role.PermissionOutletWrite, s.UpdateOutlet)).Methods(http.MethodPut)
+	outletAPI.HandleFunc("/{outletId}", s.require(role.PermissionOutletWrite, s.RemoveOutlet)).Methods(http.MethodDelete)
+	outletAPI.HandleFunc("/{outletId}/products", s.require(role.PermissionInventoryWrite, s.AssignProduct)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/products", s.require(role.PermissionInventoryRead, s.GetOutletProducts)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/products/{productId}/price", s.require(role.PermissionInventoryWrite, s.SetOutletProductPrice)).Methods(http.MethodPut)
+	outletAPI.HandleFunc("/{outletId}/products/{productId}/stock", s.require(role.PermissionInventoryWrite, s.AdjustOutletProductStock)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/payment-methods", s.require(role.PermissionOutletRead, s.GetPaymentMethods)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/payment-methods/{method}", s.require(role.PermissionPaymentMethodManage, s.SetPaymentMethod)).Methods(http.MethodPut)
+	outletAPI.HandleFunc("/{outletId}/shifts", s.require(role.PermissionShiftOperate, s.OpenShift)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/shifts", s.require(role.PermissionShiftRead, s.GetShifts)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/shifts/current", s.require(role.PermissionShiftOperate, s.GetCurrentShift)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/shifts/{shiftId}", s.require(role.PermissionShiftRead, s.GetShift)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/shifts/{shiftId}/close", s.require(role.PermissionShiftOperate, s.CloseShift)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/sales", s.require(role.PermissionSaleCreate, s.Checkout)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/sales", s.require(role.PermissionSaleRead, s.GetSales)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/sales/{saleId}", s.require(role.PermissionSaleRead, s.GetSale)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/sales/{saleId}/void", s.require(role.PermissionSaleVoid, s.VoidSale)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/sales/{saleId}/refunds", s.require(role.PermissionSaleRefund, s.RefundSale)).Methods(http.MethodPost)
+	outletAPI.HandleFunc("/{outletId}/sales/{saleId}/refunds", s.require(role.PermissionSaleRead, s.GetRefunds)).Methods(http.MethodGet)
+	outletAPI.HandleFunc("/{outletId}/sales/{saleId}/refunds/{refundId}/retry", s.require(role.PermissionSaleRefund, s.RetryRefund)).Methods(http.MethodPost)
+
+	productAPI := mux.PathPrefix("/api/products").Subrouter()
+	productAPI.Use(s.authorization)
+	productAPI.HandleFunc("", s.require(role.PermissionProductWrite, s.CreateProduct)).Methods(http.MethodPost)
+	productAPI.HandleFunc("", s.require(role.PermissionProductRead, s.GetProducts)).Methods(http.MethodGet)
+	productAPI.HandleFunc("/{productId}", s.require(role.PermissionProductRead, s.GetProduct)).Methods(http.MethodGet)
+	productAPI.HandleFunc("/{productId}", s.require(role.PermissionProductWrite, s.UpdateProduct)).Methods(http.MethodPut)
+	productAPI.HandleFunc("/{productId}", s.require(role.PermissionProductWrite, s.RemoveProduct)).Methods(http.MethodDelete)
 
 	JSON, _ := json.Marshal(Response{
 		Code:    http.StatusRequestTimeout,
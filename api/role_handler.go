@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	RoleRequest struct {
+		Name        string            `json:"name"`
+		Permissions []role.Permission `json:"permissions"`
+	}
+
+	AssignRoleRequest struct {
+		RoleID int `json:"roleID"`
+	}
+)
+
+func (s *server) CreateRole(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.CreateRole"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req RoleRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := role.Role{
+		MerchantID:  &userCredentials.MerchantID,
+		Name:        req.Name,
+		Permissions: req.Permissions,
+	}
+
+	roleID, err := s.roleService.CreateRole(ctx, userCredentials.Permissions, entity)
+	if err != nil {
+		roleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	entity.ID = roleID
+	SuccessResponse(w, "success", entity, http.StatusCreated)
+}
+
+func (s *server) GetRoles(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetRoles"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	roles, err := s.roleService.GetRoles(ctx, userCredentials.MerchantID)
+	if err != nil {
+		roleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if roles == nil {
+		roles = []role.Role{}
+	}
+
+	SuccessResponse(w, "data found", roles, http.StatusOK)
+}
+
+func (s *server) GetRole(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetRole"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	roleID, err := strconv.Atoi(mux.Vars(r)["roleId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid role id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.roleService.GetRole(ctx, userCredentials.MerchantID, roleID)
+	if err != nil {
+		roleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func (s *server) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.UpdateRole"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req RoleRequest
+
+	roleID, err := strconv.Atoi(mux.Vars(r)["roleId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid role id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity := role.Role{
+		ID:          roleID,
+		MerchantID:  &userCredentials.MerchantID,
+		Name:        req.Name,
+		Permissions: req.Permissions,
+	}
+
+	if err = s.roleService.UpdateRole(ctx, userCredentials.Permissions, entity); err != nil {
+		roleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", entity, http.StatusOK)
+}
+
+func (s *server) RemoveRole(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RemoveRole"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	roleID, err := strconv.Atoi(mux.Vars(r)["roleId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid role id"), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.roleService.DeleteRole(ctx, userCredentials.Permissions, userCredentials.MerchantID, roleID); err != nil {
+		roleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, fmt.Sprintf("success delete role with id %d", roleID), nil, http.StatusOK)
+}
+
+func (s *server) AssignRole(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.AssignRole"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req AssignRoleRequest
+
+	userID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid user id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	err = s.userService.AssignRole(ctx, userCredentials.MerchantID, userCredentials.UserID, userCredentials.Permissions, userID, req.RoleID)
+	if err != nil {
+		roleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", nil, http.StatusOK)
+}
+
+func roleErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, role.ErrInvalidRoleParameters):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, role.ErrRoleNotFound), errors.Is(err, user.ErrUserNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, role.ErrRoleNameNotUnique), errors.Is(err, role.ErrRoleInUse):
+		FailedResponse(w, err, http.StatusConflict)
+	case errors.Is(err, role.ErrBuiltinRole), errors.Is(err, role.ErrInsufficientPermissions), errors.Is(err, role.ErrForbidden):
+		FailedResponse(w, err, http.StatusForbidden)
+	default:
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
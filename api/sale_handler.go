@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	CheckoutItemRequest struct {
+		ProductID int `json:"productID"`
+		Quantity  int `json:"quantity"`
+	}
+
+	CheckoutPaymentRequest struct {
+		Method    string  `json:"method"`
+		Amount    float64 `json:"amount"`
+		Reference *string `json:"reference"`
+		Token     string  `json:"token"`
+	}
+
+	CheckoutRequest struct {
+		Items    []CheckoutItemRequest    `json:"items"`
+		Payments []CheckoutPaymentRequest `json:"payments"`
+	}
+
+	GetSalesResponse struct {
+		Sales     []sale.Sale `json:"sales"`
+		Page      int         `json:"page"`
+		TotalData int         `json:"totalData"`
+	}
+)
+
+func (s *server) Checkout(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.Checkout"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req CheckoutRequest
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	items := make([]sale.Item, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, sale.Item{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	payments := make([]payment.Payment, 0, len(req.Payments))
+	for _, p := range req.Payments {
+		payments = append(payments, payment.Payment{Method: payment.Method(p.Method), Amount: p.Amount, Reference: p.Reference, Token: p.Token})
+	}
+
+	entity, err := s.saleService.Checkout(ctx, userCredentials.MerchantID, userCredentials.UserID, outletID, items, payments)
+	if err != nil {
+		saleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "success", entity, http.StatusCreated)
+}
+
+func (s *server) GetSales(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetSales"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	page, limit, lastID, err := parsePagination(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	sales, totalData, err := s.saleService.GetSales(ctx, userCredentials.MerchantID, outletID, lastID, limit)
+	if err != nil {
+		saleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if sales == nil {
+		sales = []sale.Sale{}
+	}
+
+	SuccessResponse(w, "data found", GetSalesResponse{
+		Page:      page,
+		Sales:     sales,
+		TotalData: totalData,
+	}, http.StatusOK)
+}
+
+func (s *server) GetSale(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetSale"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, saleID, err := outletSaleVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.saleService.GetSale(ctx, userCredentials.MerchantID, saleID)
+	if err == nil && entity.OutletID != outletID {
+		err = sale.ErrSaleNotFound
+	}
+	if err != nil {
+		saleErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func outletSaleVars(r *http.Request) (outletID, saleID int, err error) {
+	vars := mux.Vars(r)
+
+	outletID, err = strconv.Atoi(vars["outletId"])
+	if err != nil {
+		return 0, 0, errors.New("invalid outlet id")
+	}
+
+	saleID, err = strconv.Atoi(vars["saleId"])
+	if err != nil {
+		return 0, 0, errors.New("invalid sale id")
+	}
+
+	return outletID, saleID, nil
+}
+
+func saleErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sale.ErrEmptySale), errors.Is(err, sale.ErrInvalidSaleItem),
+		errors.Is(err, payment.ErrInvalidPaymentMethod), errors.Is(err, payment.ErrInvalidPayment):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, outlet.ErrOutletNotFound), errors.Is(err, sale.ErrSaleNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, inventory.ErrOutletProductNotFound), errors.Is(err, payment.ErrPaymentMethodDisabled),
+		errors.Is(err, payment.ErrInsufficientPayment), errors.Is(err, payment.ErrOverpayment):
+		FailedResponse(w, err, http.StatusUnprocessableEntity)
+	case errors.Is(err, payment.ErrPaymentDeclined):
+		FailedResponse(w, err, http.StatusPaymentRequired)
+	case errors.Is(err, payment.ErrCaptureFailed):
+		FailedResponse(w, err, http.StatusBadGateway)
+	case errors.Is(err, payment.ErrGatewayUnavailable):
+		FailedResponse(w, err, http.StatusServiceUnavailable)
+	case errors.Is(err, inventory.ErrInsufficientStock), errors.Is(err, shift.ErrNoOpenShift):
+		FailedResponse(w, err, http.StatusConflict)
+	default:
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	RefreshTokenRequest struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+)
+
+func (s *server) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.RefreshToken"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req RefreshTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	token, err := s.sessionService.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, session.ErrInvalidRefreshToken) {
+			FailedResponse(w, err, http.StatusUnauthorized)
+			return
+		}
+
+		logger.Error(ctx, ops, "unknown: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	SuccessResponse(w, "token refreshed", newLoginResponse(token), http.StatusOK)
+}
+
+func (s *server) Logout(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.Logout"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	if err := s.sessionService.Logout(ctx, userCredentials.SessionID); err != nil {
+		logger.Error(ctx, ops, "unknown: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	logger.Info(ctx, ops, "user %s logout", userCredentials.Email)
+	SuccessResponse(w, "logout success", nil, http.StatusOK)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	OpenShiftRequest struct {
+		OpeningFloat float64 `json:"openingFloat"`
+	}
+
+	CloseShiftRequest struct {
+		CountedCash float64 `json:"countedCash"`
+		Note        *string `json:"note"`
+	}
+
+	GetShiftsResponse struct {
+		Shifts    []shift.Shift `json:"shifts"`
+		Page      int           `json:"page"`
+		TotalData int           `json:"totalData"`
+	}
+)
+
+func (s *server) OpenShift(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.OpenShift"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req OpenShiftRequest
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity, err := s.shiftService.OpenShift(ctx, userCredentials.MerchantID, userCredentials.UserID, outletID, req.OpeningFloat)
+	if err != nil {
+		shiftErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "shift opened", entity, http.StatusCreated)
+}
+
+func (s *server) CloseShift(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.CloseShift"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req CloseShiftRequest
+
+	outletID, shiftID, err := outletShiftVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	entity, err := s.shiftService.CloseShift(ctx, userCredentials.MerchantID, userCredentials.UserID, outletID, shiftID, req.CountedCash, req.Note)
+	if err != nil {
+		shiftErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "shift closed", entity, http.StatusOK)
+}
+
+func (s *server) GetCurrentShift(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetCurrentShift"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.shiftService.GetCurrentShift(ctx, userCredentials.MerchantID, userCredentials.UserID, outletID)
+	if err != nil {
+		shiftErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func (s *server) GetShifts(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetShifts"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, err := strconv.Atoi(mux.Vars(r)["outletId"])
+	if err != nil {
+		FailedResponse(w, errors.New("invalid outlet id"), http.StatusBadRequest)
+		return
+	}
+
+	page, limit, lastID, err := parsePagination(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	shifts, totalData, err := s.shiftService.GetShifts(ctx, userCredentials.MerchantID, outletID, lastID, limit)
+	if err != nil {
+		shiftErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	if shifts == nil {
+		shifts = []shift.Shift{}
+	}
+
+	SuccessResponse(w, "data found", GetShiftsResponse{
+		Page:      page,
+		Shifts:    shifts,
+		TotalData: totalData,
+	}, http.StatusOK)
+}
+
+func (s *server) GetShift(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.GetShift"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	outletID, shiftID, err := outletShiftVars(r)
+	if err != nil {
+		FailedResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	entity, err := s.shiftService.GetShift(ctx, userCredentials.MerchantID, outletID, shiftID)
+	if err != nil {
+		shiftErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "data found", entity, http.StatusOK)
+}
+
+func outletShiftVars(r *http.Request) (outletID, shiftID int, err error) {
+	vars := mux.Vars(r)
+
+	outletID, err = strconv.Atoi(vars["outletId"])
+	if err != nil {
+		return 0, 0, errors.New("invalid outlet id")
+	}
+
+	shiftID, err = strconv.Atoi(vars["shiftId"])
+	if err != nil {
+		return 0, 0, errors.New("invalid shift id")
+	}
+
+	return outletID, shiftID, nil
+}
+
+func shiftErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, shift.ErrInvalidShiftParameters):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, outlet.ErrOutletNotFound), errors.Is(err, shift.ErrShiftNotFound), errors.Is(err, shift.ErrNoOpenShift):
+		FailedResponse(w, err, http.StatusNotFound)
+	case errors.Is(err, shift.ErrShiftAlreadyOpen), errors.Is(err, shift.ErrShiftClosed):
+		FailedResponse(w, err, http.StatusConflict)
+	default:
+		logger.Error(ctx, ops, "unkown error: %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhdiiilham/POS/entity/twofactor"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type (
+	TwoFactorChallengeResponse struct {
+		ChallengeToken     string    `json:"challengeToken"`
+		ExpiresAt          time.Time `json:"expiresAt"`
+		EnrollmentRequired bool      `json:"enrollmentRequired"`
+	}
+
+	TwoFactorSetupResponse struct {
+		Secret        string   `json:"secret"`
+		URI           string   `json:"uri"`
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+
+	VerifyLoginRequest struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+	}
+
+	EnrollForLoginRequest struct {
+		ChallengeToken string `json:"challengeToken"`
+	}
+
+	TwoFactorCodeRequest struct {
+		Code string `json:"code"`
+	}
+)
+
+// VerifyLogin is the second step of a login that answered with a challenge.
+func (s *server) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.VerifyLogin"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req VerifyLoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	token, err := s.twoFactorService.VerifyLogin(ctx, req.ChallengeToken, req.Code)
+	if err != nil {
+		twoFactorErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "login success", newLoginResponse(token), http.StatusOK)
+}
+
+// EnrollForLogin sets up an authenticator app for a user who has to have one
+// before their login can finish.
+func (s *server) EnrollForLogin(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.EnrollForLogin"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	var req EnrollForLoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Error(ctx, ops, "error decode request body: %v", err)
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	setup, err := s.twoFactorService.EnrollForLogin(ctx, req.ChallengeToken)
+	if err != nil {
+		twoFactorErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "scan the code and answer the challenge with it", newTwoFactorSetupResponse(setup), http.StatusOK)
+}
+
+func (s *server) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.EnrollTwoFactor"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+
+	setup, err := s.twoFactorService.Enroll(ctx, userCredentials.MerchantID, userCredentials.UserID)
+	if err != nil {
+		twoFactorErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "scan the code and confirm it to enable two-factor authentication", newTwoFactorSetupResponse(setup), http.StatusOK)
+}
+
+func (s *server) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.ConfirmTwoFactor"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req TwoFactorCodeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if err := s.twoFactorService.ConfirmEnrollment(ctx, userCredentials.UserID, req.Code); err != nil {
+		twoFactorErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "two-factor authentication enabled", nil, http.StatusOK)
+}
+
+func (s *server) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	const ops = "api.server.DisableTwoFactor"
+	ctx := context.WithValue(r.Context(), logger.RequestIDKey, uuid.New().String())
+	userCredentials := r.Context().Value("user-credentials").(TokenPayload)
+	var req TwoFactorCodeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		UnknownErrorResponse(w, err)
+		return
+	}
+
+	if err := s.twoFactorService.Disable(ctx, userCredentials.MerchantID, userCredentials.UserID, req.Code); err != nil {
+		twoFactorErrorResponse(ctx, ops, w, err)
+		return
+	}
+
+	SuccessResponse(w, "two-factor authentication disabled", nil, http.StatusOK)
+}
+
+func twoFactorErrorResponse(ctx context.Context, ops string, w http.ResponseWriter, err error) {
+	var lockout *user.LockoutError
+	switch {
+	case errors.As(err, &lockout):
+		lockedOutResponse(w, lockout)
+	case errors.Is(err, twofactor.ErrInvalidCode), errors.Is(err, twofactor.ErrInvalidChallenge):
+		FailedResponse(w, err, http.StatusUnauthorized)
+	case errors.Is(err, twofactor.ErrNotEnrolled):
+		FailedResponse(w, err, http.StatusBadRequest)
+	case errors.Is(err, twofactor.ErrRequired):
+		FailedResponse(w, err, http.StatusForbidden)
+	case errors.Is(err, twofactor.ErrAlreadyEnabled):
+		FailedResponse(w, err, http.StatusConflict)
+	case errors.Is(err, user.ErrUserNotFound):
+		FailedResponse(w, err, http.StatusNotFound)
+	default:
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		UnknownErrorResponse(w, err)
+	}
+}
+
+func newTwoFactorSetupResponse(setup twofactor.Setup) TwoFactorSetupResponse {
+	return TwoFactorSetupResponse{
+		Secret:        setup.Secret,
+		URI:           setup.URI,
+		RecoveryCodes: setup.RecoveryCodes,
+	}
+}
@@ -11,10 +11,28 @@ import (
 	"github.com/mhdiiilham/POS/api"
 	"github.com/mhdiiilham/POS/config"
 	"github.com/mhdiiilham/POS/database"
+	"github.com/mhdiiilham/POS/pkg/gateway"
 	"github.com/mhdiiilham/POS/pkg/hasher"
 	"github.com/mhdiiilham/POS/pkg/logger"
+	"github.com/mhdiiilham/POS/pkg/mailer"
+	"github.com/mhdiiilham/POS/pkg/password"
 	"github.com/mhdiiilham/POS/pkg/server"
+	"github.com/mhdiiilham/POS/pkg/throttle"
 	"github.com/mhdiiilham/POS/pkg/token"
+	apikeyrepository "github.com/mhdiiilham/POS/repository/apikey"
+	inventoryrepository "github.com/mhdiiilham/POS/repository/inventory"
+	invitationrepository "github.com/mhdiiilham/POS/repository/invitation"
+	merchantrepository "github.com/mhdiiilham/POS/repository/merchant"
+	outletrepository "github.com/mhdiiilham/POS/repository/outlet"
+	passwordresetrepository "github.com/mhdiiilham/POS/repository/passwordreset"
+	paymentrepository "github.com/mhdiiilham/POS/repository/payment"
+	productrepository "github.com/mhdiiilham/POS/repository/product"
+	refundrepository "github.com/mhdiiilham/POS/repository/refund"
+	rolerepository "github.com/mhdiiilham/POS/repository/role"
+	salerepository "github.com/mhdiiilham/POS/repository/sale"
+	sessionrepository "github.com/mhdiiilham/POS/repository/session"
+	shiftrepository "github.com/mhdiiilham/POS/repository/shift"
+	twofactorrepository "github.com/mhdiiilham/POS/repository/twofactor"
 	userrepository "github.com/mhdiiilham/POS/repository/user"
 	"github.com/mhdiiilham/POS/service"
 	"github.com/sirupsen/logrus"
@@ -40,6 +58,7 @@ func main() {
 	logger.Info(ctx, ops, "starting api service")
 
 	env := flag.String("env", "local", "To Set Service Environment Mode")
+	flag.Parse()
 	logger.Info(ctx, ops, "starting service in %s mode", *env)
 
 	dbConn, err := realMain(ctx, *env)
@@ -58,6 +77,19 @@ func realMain(ctx context.Context, env string) (*sql.DB, error) {
 		return nil, cfgErr
 	}
 
+	var paymentGateway service.PaymentGateway
+	switch cfg.PaymentGateway.Driver {
+	case "", "none":
+		paymentGateway = gateway.NewDisabledGateway()
+	case "fake":
+		if env != "local" {
+			return nil, fmt.Errorf("the fake payment gateway only runs in the local env, not %q", env)
+		}
+		paymentGateway = gateway.NewFakeGateway()
+	default:
+		return nil, fmt.Errorf("unsupported payment gateway driver %q", cfg.PaymentGateway.Driver)
+	}
+
 	dbDNS := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Database.Host,
 		cfg.Database.Port,
@@ -72,12 +104,108 @@ func realMain(ctx context.Context, env string) (*sql.DB, error) {
 		return nil, dbErr
 	}
 
-	pwdHasher := hasher.NewHasher()
+	pwdHasher, hasherErr := hasher.NewHasher(hasher.Options{
+		Algorithm:       cfg.PasswordHash.Algorithm,
+		BcryptCost:      cfg.PasswordHash.BcryptCost,
+		Argon2Time:      cfg.PasswordHash.Argon2Time,
+		Argon2MemoryKiB: cfg.PasswordHash.Argon2MemoryKiB,
+		Argon2Threads:   cfg.PasswordHash.Argon2Threads,
+	})
+	if hasherErr != nil {
+		return nil, hasherErr
+	}
+
+	passwordPolicy := password.Policy{
+		MinLength:          cfg.PasswordPolicy.MinLength,
+		MaxLength:          cfg.PasswordPolicy.MaxLength,
+		RequireUppercase:   cfg.PasswordPolicy.RequireUppercase,
+		RequireLowercase:   cfg.PasswordPolicy.RequireLowercase,
+		RequireDigit:       cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol:      cfg.PasswordPolicy.RequireSymbol,
+		RejectPersonalInfo: cfg.PasswordPolicy.RejectPersonalInfo,
+		RejectCommon:       cfg.PasswordPolicy.RejectCommon,
+	}
+
 	tokenService := token.NewJWTService(cfg.JwtSecret, cfg.JwtIssuer)
+	if cfg.JWT.Algorithm == token.AlgorithmRS256 || cfg.JWT.Algorithm == token.AlgorithmEdDSA {
+		keys := make([]token.Key, 0, len(cfg.JWT.Keys))
+		for _, k := range cfg.JWT.Keys {
+			key, keyErr := token.LoadKey(k.ID, k.PrivateKeyFile, k.PublicKeyFile, k.ActiveFrom)
+			if keyErr != nil {
+				return nil, keyErr
+			}
+			keys = append(keys, key)
+		}
+
+		var keyErr error
+		tokenService, keyErr = token.NewKeySetJWTService(cfg.JwtIssuer, cfg.JWT.Algorithm, keys)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+	}
+	trustedProxies, proxyErr := api.ParseTrustedProxies(cfg.TrustedProxies)
+	if proxyErr != nil {
+		return nil, proxyErr
+	}
+
+	transactor := database.NewTransactor(db)
+	loginAttempts := throttle.NewMemoryStore()
+
+	var mail service.Mailer = mailer.NewLogMailer(cfg.Mail.File)
+	if cfg.Mail.Driver == "smtp" {
+		mail = mailer.NewSMTPMailer(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From)
+	}
+
 	userRepository := userrepository.NewRepository(db)
-	userService := service.NewAPIService(userRepository, pwdHasher, tokenService)
+	sessionRepository := sessionrepository.NewRepository(db)
+	roleRepository := rolerepository.NewRepository(db)
+	passwordResetRepository := passwordresetrepository.NewRepository(db)
+	invitationRepository := invitationrepository.NewRepository(db)
+	twoFactorRepository := twofactorrepository.NewRepository(db)
+	apiKeyRepository := apikeyrepository.NewRepository(db)
+	merchantRepository := merchantrepository.NewRepository(db)
+	outletRepository := outletrepository.NewRepository(db)
+	productRepository := productrepository.NewRepository(db)
+	inventoryRepository := inventoryrepository.NewRepository(db)
+	saleRepository := salerepository.NewRepository(db)
+	refundRepository := refundrepository.NewRepository(db)
+	shiftRepository := shiftrepository.NewRepository(db)
+	paymentRepository := paymentrepository.NewRepository(db)
+	userService := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, pwdHasher, passwordPolicy, tokenService, loginAttempts)
+	sessionService := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenService)
+	roleService := service.NewRoleService(roleRepository)
+	passwordService := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, pwdHasher, passwordPolicy, mail, loginAttempts, cfg.PasswordResetURL)
+	invitationService := service.NewInvitationService(userRepository, roleRepository, invitationRepository, pwdHasher, passwordPolicy, mail, cfg.InviteURL)
+	twoFactorService := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenService, loginAttempts, cfg.TwoFactorIssuer)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository)
+	merchantService := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, pwdHasher, passwordPolicy, tokenService)
+	outletService := service.NewOutletService(outletRepository)
+	productService := service.NewProductService(productRepository)
+	inventoryService := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+	saleService := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+	refundService := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+	shiftService := service.NewShiftService(shiftRepository, outletRepository)
+	paymentService := service.NewPaymentService(paymentRepository, outletRepository)
 
-	restAPI := api.NewPOSServer(userService, tokenService)
+	restAPI := api.NewPOSServer(
+		userService,
+		sessionService,
+		roleService,
+		passwordService,
+		invitationService,
+		twoFactorService,
+		apiKeyService,
+		merchantService,
+		outletService,
+		productService,
+		inventoryService,
+		saleService,
+		refundService,
+		shiftService,
+		paymentService,
+		tokenService,
+		trustedProxies,
+	)
 	srv, err := server.New(cfg.Port)
 	if err != nil {
 		return nil, err
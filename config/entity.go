@@ -1,11 +1,60 @@
 package config
 
 type Config struct {
-	Env       string   `mapstructure:"env"`
-	Port      string   `mapstructure:"port"`
-	JwtSecret string   `mapstructure:"jwtSecret"`
-	JwtIssuer string   `mapstructure:"jwtIssuer"`
-	Database  Database `mapstructure:"database"`
+	Env              string         `mapstructure:"env"`
+	Port             string         `mapstructure:"port"`
+	TrustedProxies   []string       `mapstructure:"trustedProxies"`
+	JwtSecret        string         `mapstructure:"jwtSecret"`
+	JwtIssuer        string         `mapstructure:"jwtIssuer"`
+	JWT              JWT            `mapstructure:"jwt"`
+	InviteURL        string         `mapstructure:"inviteURL"`
+	PasswordResetURL string         `mapstructure:"passwordResetURL"`
+	TwoFactorIssuer  string         `mapstructure:"twoFactorIssuer"`
+	PasswordHash     PasswordHash   `mapstructure:"passwordHash"`
+	PasswordPolicy   PasswordPolicy `mapstructure:"passwordPolicy"`
+	Database         Database       `mapstructure:"database"`
+	Mail             Mail           `mapstructure:"mail"`
+	PaymentGateway   PaymentGateway `mapstructure:"paymentGateway"`
+}
+
+// JWT picks how access tokens are signed. Algorithm "RS256" or "EdDSA" signs
+// with Keys and publishes them at /.well-known/jwks.json; anything else signs
+// with JwtSecret using HS256. A rotation is a new key with a later
+// ActiveFrom; the old key stays listed until its tokens have expired.
+type JWT struct {
+	Algorithm string   `mapstructure:"algorithm"`
+	Keys      []JWTKey `mapstructure:"keys"`
+}
+
+type JWTKey struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	PublicKeyFile  string `mapstructure:"publicKeyFile"`
+	ActiveFrom     string `mapstructure:"activeFrom"`
+}
+
+// PasswordHash picks how new passwords and PINs are hashed. Algorithm is
+// "bcrypt" or "argon2id"; zero parameters take the hasher's defaults. Stored
+// hashes made with other settings are upgraded when their user logs in.
+type PasswordHash struct {
+	Algorithm       string `mapstructure:"algorithm"`
+	BcryptCost      int    `mapstructure:"bcryptCost"`
+	Argon2Time      uint32 `mapstructure:"argon2Time"`
+	Argon2MemoryKiB uint32 `mapstructure:"argon2MemoryKiB"`
+	Argon2Threads   uint8  `mapstructure:"argon2Threads"`
+}
+
+// PasswordPolicy is what new passwords must satisfy. A minLength below 8 is
+// raised to 8 and a zero maxLength allows 72 bytes, bcrypt's limit.
+type PasswordPolicy struct {
+	MinLength          int  `mapstructure:"minLength"`
+	MaxLength          int  `mapstructure:"maxLength"`
+	RequireUppercase   bool `mapstructure:"requireUppercase"`
+	RequireLowercase   bool `mapstructure:"requireLowercase"`
+	RequireDigit       bool `mapstructure:"requireDigit"`
+	RequireSymbol      bool `mapstructure:"requireSymbol"`
+	RejectPersonalInfo bool `mapstructure:"rejectPersonalInfo"`
+	RejectCommon       bool `mapstructure:"rejectCommon"`
 }
 
 type Database struct {
@@ -15,3 +64,23 @@ type Database struct {
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
 }
+
+// Mail picks how email is delivered. Driver "smtp" sends through Host and
+// Port; anything else writes messages to File, or stdout when File is empty.
+type Mail struct {
+	Driver   string `mapstructure:"driver"`
+	File     string `mapstructure:"file"`
+	From     string `mapstructure:"from"`
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// PaymentGateway picks the acquirer for card and e-wallet payments. Driver
+// "fake" approves everything and keeps its transactions in memory, so it is
+// only allowed in the local env; "none" or an empty driver refuses those
+// payments.
+type PaymentGateway struct {
+	Driver string `mapstructure:"driver"`
+}
@@ -1,15 +1,102 @@
 CREATE TABLE "User" (
   "id" SERIAL PRIMARY KEY,
-  "email" varchar UNIQUE,
+  "email" varchar,
   "firstname" varchar,
   "lastname" varchar,
   "password" varchar,
+  "pin_hash" varchar,
+  "pin_failed_attempts" int NOT NULL DEFAULT 0,
+  "pin_locked_until" timestamp,
+  "pending" boolean NOT NULL DEFAULT false,
   "merchant_id" int,
+  "role_id" int NOT NULL,
+  "created_at" timestamp,
+  "updated_at" timestamp,
+  "deleted_at" timestamp,
+  "purged_at" timestamp
+);
+
+CREATE TABLE "Role" (
+  "id" SERIAL PRIMARY KEY,
+  "merchant_id" int,
+  "name" varchar,
+  "permissions" varchar[],
   "created_at" timestamp,
   "updated_at" timestamp,
   "deleted_at" timestamp
 );
 
+CREATE TABLE "Session" (
+  "id" uuid PRIMARY KEY,
+  "user_id" int,
+  "merchant_id" int,
+  "outlet_id" int,
+  "refresh_token_hash" varchar,
+  "created_at" timestamp,
+  "expires_at" timestamp,
+  "revoked_at" timestamp
+);
+
+CREATE TABLE "PasswordReset" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int,
+  "token_hash" varchar UNIQUE,
+  "created_at" timestamp,
+  "expires_at" timestamp,
+  "used_at" timestamp
+);
+
+CREATE TABLE "TwoFactor" (
+  "user_id" int PRIMARY KEY,
+  "secret" varchar,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamp,
+  "enabled_at" timestamp
+);
+
+CREATE TABLE "RecoveryCode" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int,
+  "code_hash" varchar,
+  "used_at" timestamp
+);
+
+CREATE TABLE "LoginChallenge" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int,
+  "merchant_id" int,
+  "token_hash" varchar UNIQUE,
+  "failed_attempts" int NOT NULL DEFAULT 0,
+  "created_at" timestamp,
+  "expires_at" timestamp,
+  "used_at" timestamp
+);
+
+CREATE TABLE "APIKey" (
+  "id" SERIAL PRIMARY KEY,
+  "merchant_id" int,
+  "created_by" int,
+  "name" varchar,
+  "prefix" varchar UNIQUE,
+  "secret_hash" varchar,
+  "permissions" varchar[],
+  "created_at" timestamp,
+  "expires_at" timestamp,
+  "last_used_at" timestamp,
+  "revoked_at" timestamp
+);
+
+CREATE TABLE "Invitation" (
+  "id" SERIAL PRIMARY KEY,
+  "merchant_id" int,
+  "user_id" int UNIQUE,
+  "invited_by" int,
+  "token_hash" varchar UNIQUE,
+  "created_at" timestamp,
+  "expires_at" timestamp,
+  "accepted_at" timestamp
+);
+
 CREATE TABLE "Merchant" (
   "id" SERIAL PRIMARY KEY,
   "name" varchar,
@@ -20,7 +107,10 @@ CREATE TABLE "Outlet" (
   "id" SERIAL PRIMARY KEY,
   "merchant_id" int,
   "name" varchar,
-  "location" varchar
+  "location" varchar,
+  "created_at" timestamp,
+  "updated_at" timestamp,
+  "deleted_at" timestamp
 );
 
 CREATE TABLE "Product" (
@@ -28,18 +118,146 @@ CREATE TABLE "Product" (
   "merchant_id" int,
   "sku" varchar,
   "name" varchar,
-  "display_image" varchar
+  "display_image" varchar,
+  "created_at" timestamp,
+  "updated_at" timestamp,
+  "deleted_at" timestamp
 );
 
 CREATE TABLE "OutletProduct" (
   "outlet_id" int,
   "product_id" int,
   "price" float4,
-  "stock" int
+  "stock" int,
+  PRIMARY KEY ("outlet_id", "product_id")
+);
+
+CREATE TABLE "StockMovement" (
+  "id" SERIAL PRIMARY KEY,
+  "outlet_id" int,
+  "product_id" int,
+  "user_id" int,
+  "quantity" int,
+  "stock_after" int,
+  "reason" varchar,
+  "note" varchar,
+  "created_at" timestamp
+);
+
+CREATE TABLE "Shift" (
+  "id" SERIAL PRIMARY KEY,
+  "merchant_id" int,
+  "outlet_id" int,
+  "user_id" int,
+  "opening_float" numeric(14, 2),
+  "expected_cash" numeric(14, 2),
+  "counted_cash" numeric(14, 2),
+  "difference" numeric(14, 2),
+  "note" varchar,
+  "opened_at" timestamp,
+  "closed_at" timestamp
+);
+
+CREATE TABLE "Sale" (
+  "id" SERIAL PRIMARY KEY,
+  "merchant_id" int,
+  "outlet_id" int,
+  "shift_id" int,
+  "cashier_id" int,
+  "status" varchar,
+  "total" numeric(14, 2),
+  "paid" numeric(14, 2),
+  "change_due" numeric(14, 2),
+  "created_at" timestamp
+);
+
+CREATE TABLE "SaleItem" (
+  "id" SERIAL PRIMARY KEY,
+  "sale_id" int,
+  "product_id" int,
+  "sku" varchar,
+  "name" varchar,
+  "quantity" int,
+  "price" numeric(14, 2),
+  "subtotal" numeric(14, 2)
+);
+
+CREATE TABLE "Payment" (
+  "id" SERIAL PRIMARY KEY,
+  "sale_id" int,
+  "method" varchar,
+  "amount" numeric(14, 2),
+  "reference" varchar,
+  "created_at" timestamp
+);
+
+CREATE TABLE "OutletPaymentMethod" (
+  "outlet_id" int,
+  "method" varchar,
+  "enabled" boolean,
+  PRIMARY KEY ("outlet_id", "method")
+);
+
+CREATE TABLE "Refund" (
+  "id" SERIAL PRIMARY KEY,
+  "sale_id" int,
+  "merchant_id" int,
+  "outlet_id" int,
+  "shift_id" int,
+  "user_id" int,
+  "type" varchar,
+  "reason" varchar,
+  "note" varchar,
+  "total" numeric(14, 2),
+  "created_at" timestamp
+);
+
+CREATE TABLE "RefundItem" (
+  "id" SERIAL PRIMARY KEY,
+  "refund_id" int,
+  "sale_item_id" int,
+  "product_id" int,
+  "quantity" int,
+  "amount" numeric(14, 2)
+);
+
+CREATE TABLE "RefundPayment" (
+  "id" SERIAL PRIMARY KEY,
+  "refund_id" int,
+  "method" varchar,
+  "amount" numeric(14, 2),
+  "reference" varchar,
+  "status" varchar NOT NULL DEFAULT 'refunded'
 );
 
 ALTER TABLE "User" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
 
+ALTER TABLE "User" ADD FOREIGN KEY ("role_id") REFERENCES "Role" ("id");
+
+ALTER TABLE "Role" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
+
+ALTER TABLE "Session" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "Session" ADD FOREIGN KEY ("outlet_id") REFERENCES "Outlet" ("id");
+
+ALTER TABLE "PasswordReset" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "TwoFactor" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "RecoveryCode" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "LoginChallenge" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "APIKey" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
+
+ALTER TABLE "APIKey" ADD FOREIGN KEY ("created_by") REFERENCES "User" ("id");
+
+ALTER TABLE "Invitation" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
+
+ALTER TABLE "Invitation" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "Invitation" ADD FOREIGN KEY ("invited_by") REFERENCES "User" ("id");
+
 ALTER TABLE "Outlet" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
 
 ALTER TABLE "Product" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
@@ -48,12 +266,68 @@ ALTER TABLE "OutletProduct" ADD FOREIGN KEY ("outlet_id") REFERENCES "Outlet" ("
 
 ALTER TABLE "OutletProduct" ADD FOREIGN KEY ("product_id") REFERENCES "Product" ("id");
 
+ALTER TABLE "StockMovement" ADD FOREIGN KEY ("outlet_id", "product_id") REFERENCES "OutletProduct" ("outlet_id", "product_id");
+
+ALTER TABLE "StockMovement" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "Shift" ADD FOREIGN KEY ("outlet_id") REFERENCES "Outlet" ("id");
+
+ALTER TABLE "Shift" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "Sale" ADD FOREIGN KEY ("merchant_id") REFERENCES "Merchant" ("id");
+
+ALTER TABLE "Sale" ADD FOREIGN KEY ("shift_id") REFERENCES "Shift" ("id");
+
+ALTER TABLE "Sale" ADD FOREIGN KEY ("outlet_id") REFERENCES "Outlet" ("id");
+
+ALTER TABLE "Sale" ADD FOREIGN KEY ("cashier_id") REFERENCES "User" ("id");
+
+ALTER TABLE "SaleItem" ADD FOREIGN KEY ("sale_id") REFERENCES "Sale" ("id");
+
+ALTER TABLE "SaleItem" ADD FOREIGN KEY ("product_id") REFERENCES "Product" ("id");
+
+ALTER TABLE "Payment" ADD FOREIGN KEY ("sale_id") REFERENCES "Sale" ("id");
+
+ALTER TABLE "OutletPaymentMethod" ADD FOREIGN KEY ("outlet_id") REFERENCES "Outlet" ("id");
+
+ALTER TABLE "Refund" ADD FOREIGN KEY ("sale_id") REFERENCES "Sale" ("id");
+
+ALTER TABLE "Refund" ADD FOREIGN KEY ("user_id") REFERENCES "User" ("id");
+
+ALTER TABLE "Refund" ADD FOREIGN KEY ("shift_id") REFERENCES "Shift" ("id");
+
+ALTER TABLE "RefundItem" ADD FOREIGN KEY ("refund_id") REFERENCES "Refund" ("id");
+
+ALTER TABLE "RefundItem" ADD FOREIGN KEY ("sale_item_id") REFERENCES "SaleItem" ("id");
+
+ALTER TABLE "RefundPayment" ADD FOREIGN KEY ("refund_id") REFERENCES "Refund" ("id");
+
 CREATE INDEX ON "User" ("id");
 
 CREATE INDEX ON "User" ("email");
 
+CREATE UNIQUE INDEX ON "User" ("email") WHERE "deleted_at" IS NULL;
+
 CREATE INDEX ON "User" ("merchant_id");
 
+CREATE INDEX ON "User" ("role_id");
+
+CREATE INDEX ON "Role" ("merchant_id");
+
+CREATE UNIQUE INDEX ON "Role" ("merchant_id", "name") WHERE "deleted_at" IS NULL;
+
+CREATE INDEX ON "Session" ("user_id");
+
+CREATE INDEX ON "PasswordReset" ("user_id");
+
+CREATE INDEX ON "RecoveryCode" ("user_id");
+
+CREATE INDEX ON "LoginChallenge" ("user_id");
+
+CREATE INDEX ON "APIKey" ("merchant_id");
+
+CREATE INDEX ON "Invitation" ("merchant_id");
+
 CREATE INDEX ON "Merchant" ("id");
 
 CREATE INDEX ON "Merchant" ("name");
@@ -72,6 +346,54 @@ CREATE INDEX ON "Product" ("sku");
 
 CREATE INDEX ON "Product" ("name");
 
+CREATE UNIQUE INDEX ON "Product" ("merchant_id", "sku") WHERE "deleted_at" IS NULL;
+
 CREATE INDEX ON "OutletProduct" ("outlet_id");
 
 CREATE INDEX ON "OutletProduct" ("product_id");
+
+CREATE INDEX ON "StockMovement" ("outlet_id", "product_id");
+
+CREATE INDEX ON "Shift" ("outlet_id");
+
+CREATE UNIQUE INDEX ON "Shift" ("outlet_id", "user_id") WHERE "closed_at" IS NULL;
+
+CREATE INDEX ON "Sale" ("merchant_id");
+
+CREATE INDEX ON "Sale" ("outlet_id");
+
+CREATE INDEX ON "Sale" ("shift_id");
+
+CREATE INDEX ON "SaleItem" ("sale_id");
+
+CREATE INDEX ON "Payment" ("sale_id");
+
+CREATE INDEX ON "Refund" ("sale_id");
+
+CREATE INDEX ON "Refund" ("shift_id");
+
+CREATE INDEX ON "RefundItem" ("refund_id");
+
+CREATE INDEX ON "RefundItem" ("sale_item_id");
+
+CREATE INDEX ON "RefundPayment" ("refund_id");
+
+INSERT INTO "Role" ("id", "merchant_id", "name", "permissions", "created_at", "updated_at") VALUES
+  (1, NULL, 'owner', ARRAY[
+    'user:read', 'user:write', 'user:purge', 'role:manage', 'api_key:manage', 'merchant:read', 'merchant:manage',
+    'outlet:read', 'outlet:write', 'product:read', 'product:write', 'inventory:read',
+    'inventory:write', 'payment_method:manage', 'shift:operate', 'shift:read',
+    'sale:create', 'sale:read', 'sale:void', 'sale:refund'
+  ], now(), now()),
+  (2, NULL, 'manager', ARRAY[
+    'user:read', 'user:write', 'merchant:read',
+    'outlet:read', 'outlet:write', 'product:read', 'product:write', 'inventory:read',
+    'inventory:write', 'payment_method:manage', 'shift:operate', 'shift:read',
+    'sale:create', 'sale:read', 'sale:void', 'sale:refund'
+  ], now(), now()),
+  (3, NULL, 'cashier', ARRAY[
+    'outlet:read', 'product:read', 'inventory:read', 'shift:operate',
+    'sale:create', 'sale:read', 'sale:void'
+  ], now(), now());
+
+SELECT setval(pg_get_serial_sequence('"Role"', 'id'), (SELECT MAX("id") FROM "Role"));
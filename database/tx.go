@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+// Executor is what *sql.DB and *sql.Tx have in common, so repositories can
+// run a statement inside or outside a transaction alike.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn returns the transaction WithinTransaction put in ctx, or db when
+// there is none.
+func Conn(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor lets services run several repository calls in one
+// transaction. Repositories take part by running their statements on Conn.
+func NewTransactor(db *sql.DB) *transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction runs fn in a transaction that is committed when fn
+// returns nil and rolled back otherwise.
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const ops = "database.transactor.WithinTransaction"
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin transaction %v", err)
+		return err
+	}
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error trying to commit transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
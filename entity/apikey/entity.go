@@ -0,0 +1,39 @@
+package apikey
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/role"
+)
+
+// TokenPrefix starts every API key, so leaked keys are easy to recognise in
+// logs and secret scanners.
+const TokenPrefix = "pos_"
+
+// Key is a credential a merchant gives to an integration. The full key is
+// TokenPrefix, Prefix, a dot and a secret; it is shown once when created and
+// only a hash of the secret is stored. Prefix identifies the key in lists.
+type Key struct {
+	ID          int               `db:"id" json:"id"`
+	MerchantID  int               `db:"merchant_id" json:"merchantID"`
+	CreatedBy   int               `db:"created_by" json:"createdBy"`
+	Name        string            `db:"name" json:"name"`
+	Prefix      string            `db:"prefix" json:"prefix"`
+	SecretHash  string            `db:"secret_hash" json:"-"`
+	Permissions []role.Permission `db:"permissions" json:"permissions"`
+	CreatedAt   time.Time         `db:"created_at" json:"created_at"`
+	ExpiresAt   *time.Time        `db:"expires_at" json:"expires_at"`
+	LastUsedAt  *time.Time        `db:"last_used_at" json:"last_used_at"`
+	RevokedAt   *time.Time        `db:"revoked_at" json:"revoked_at"`
+}
+
+func (k Key) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+var (
+	ErrInvalidAPIKeyParameters error = errors.New("API key must have a name, valid permissions and a future expiry")
+	ErrAPIKeyNotFound          error = errors.New("API key not found")
+	ErrInvalidAPIKey           error = errors.New("invalid API key")
+)
@@ -0,0 +1,14 @@
+package apikey
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entity Key) (id int64, err error)
+	Get(ctx context.Context, merchantID int) (keys []Key, err error)
+	GetByPrefix(ctx context.Context, prefix string) (Key, error)
+	Revoke(ctx context.Context, merchantID, keyID int) (err error)
+	MarkUsed(ctx context.Context, keyID int, at time.Time) (err error)
+}
@@ -0,0 +1,64 @@
+package inventory
+
+import (
+	"errors"
+	"time"
+)
+
+type OutletProduct struct {
+	OutletID  int     `db:"outlet_id" json:"outletID"`
+	ProductID int     `db:"product_id" json:"productID"`
+	SKU       string  `db:"sku" json:"sku"`
+	Name      string  `db:"name" json:"name"`
+	Price     float64 `db:"price" json:"price"`
+	Stock     int     `db:"stock" json:"stock"`
+}
+
+type StockMovement struct {
+	ID         int       `db:"id" json:"id"`
+	OutletID   int       `db:"outlet_id" json:"outletID"`
+	ProductID  int       `db:"product_id" json:"productID"`
+	UserID     int       `db:"user_id" json:"userID"`
+	Quantity   int       `db:"quantity" json:"quantity"`
+	StockAfter int       `db:"stock_after" json:"stockAfter"`
+	Reason     Reason    `db:"reason" json:"reason"`
+	Note       *string   `db:"note" json:"note"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// Reason explains why the stock of an outlet product changed.
+type Reason string
+
+const (
+	ReasonInitial    Reason = "initial"
+	ReasonRestock    Reason = "restock"
+	ReasonDamaged    Reason = "damaged"
+	ReasonLost       Reason = "lost"
+	ReasonCorrection Reason = "correction"
+	ReasonSale       Reason = "sale"
+	ReasonVoid       Reason = "void"
+	ReasonRefund     Reason = "refund"
+)
+
+// Valid reports whether r is a reason a user may give for a manual adjustment.
+func (r Reason) Valid() bool {
+	switch r {
+	case ReasonRestock, ReasonDamaged, ReasonLost, ReasonCorrection:
+		return true
+	}
+	return false
+}
+
+var (
+	ErrInvalidInventoryParameters error = errors.New("invalid price or stock parameters")
+	ErrInvalidStockReason         error = errors.New("invalid stock adjustment reason")
+	ErrProductAlreadyAssigned     error = errors.New("product is already assigned to this outlet")
+	ErrOutletProductNotFound      error = errors.New("product is not assigned to this outlet")
+	ErrInsufficientStock          error = errors.New("insufficient stock")
+)
+
+type RepositoryGetOutletProductPaginationOptions struct {
+	Page   int
+	Limit  int
+	Cursor int
+}
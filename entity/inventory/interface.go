@@ -0,0 +1,11 @@
+package inventory
+
+import "context"
+
+type Repository interface {
+	Assign(ctx context.Context, entity OutletProduct, userID int) (err error)
+	Get(ctx context.Context, outletID int, opts *RepositoryGetOutletProductPaginationOptions) (products []OutletProduct, totalData int, err error)
+	GetOutletProduct(ctx context.Context, outletID, productID int) (OutletProduct, error)
+	UpdatePrice(ctx context.Context, outletID, productID int, price float64) (err error)
+	AdjustStock(ctx context.Context, movement StockMovement) (stock int, err error)
+}
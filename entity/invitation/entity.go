@@ -0,0 +1,38 @@
+package invitation
+
+import (
+	"errors"
+	"time"
+)
+
+// TTL is how long an invite link stays usable after it is sent or resent.
+const TTL = 7 * 24 * time.Hour
+
+// Invitation brings a new employee into a merchant. Inviting creates a
+// pending user; the invitee picks their own password with the token mailed
+// to them, of which only a hash is stored. Email, FirstName and RoleID are
+// read from the pending user.
+type Invitation struct {
+	ID         int        `db:"id" json:"id"`
+	MerchantID int        `db:"merchant_id" json:"merchantID"`
+	UserID     int        `db:"user_id" json:"userID"`
+	InvitedBy  int        `db:"invited_by" json:"invitedBy"`
+	Email      string     `db:"email" json:"email"`
+	FirstName  string     `db:"firstname" json:"firstname"`
+	RoleID     int        `db:"role_id" json:"roleID"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at"`
+}
+
+// Expired reports whether the invite link no longer works. Expired
+// invitations can still be resent.
+func (i Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+var (
+	ErrInvitationNotFound error = errors.New("invitation not found")
+	ErrInvalidInviteToken error = errors.New("invite token is invalid or has expired")
+)
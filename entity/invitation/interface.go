@@ -0,0 +1,18 @@
+package invitation
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/user"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entity Invitation, invitee user.User) (invitationID, userID int64, err error)
+	Get(ctx context.Context, merchantID int) (invitations []Invitation, err error)
+	GetInvitation(ctx context.Context, merchantID, invitationID int) (entity Invitation, err error)
+	Renew(ctx context.Context, merchantID, invitationID int, tokenHash string, expiresAt time.Time) (err error)
+	Revoke(ctx context.Context, merchantID, invitationID int) (err error)
+	FindUser(ctx context.Context, tokenHash string) (entity user.User, err error)
+	Accept(ctx context.Context, tokenHash, hashedPassword string) (userID int, err error)
+}
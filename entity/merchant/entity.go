@@ -0,0 +1,21 @@
+package merchant
+
+import "errors"
+
+type Merchant struct {
+	ID   int     `db:"id" json:"id"`
+	Name string  `db:"name" json:"name"`
+	Logo *string `db:"logo" json:"logo"`
+}
+
+var (
+	ErrInvalidMerchantParameters error = errors.New("invalid merchant parameters")
+	ErrMerchantNotFound          error = errors.New("merchant not found")
+	ErrForbiddenMerchant         error = errors.New("not allowed to manage this merchant")
+)
+
+type RepositoryGetMerchantPaginationOptions struct {
+	Page   int
+	Limit  int
+	Cursor int
+}
@@ -0,0 +1,10 @@
+package merchant
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity Merchant) (id int64, err error)
+	Get(ctx context.Context, opts *RepositoryGetMerchantPaginationOptions) (merchants []Merchant, totalData int, err error)
+	GetMerchant(ctx context.Context, merchantID int) (Merchant, error)
+	Update(ctx context.Context, entity Merchant) (err error)
+}
@@ -0,0 +1,27 @@
+package outlet
+
+import (
+	"errors"
+	"time"
+)
+
+type Outlet struct {
+	ID         int        `db:"id" json:"id"`
+	MerchantID int        `db:"merchant_id" json:"merchantID"`
+	Name       string     `db:"name" json:"name"`
+	Location   *string    `db:"location" json:"location"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt  *time.Time `db:"deleted_at" json:"-"`
+}
+
+var (
+	ErrInvalidOutletParameters error = errors.New("invalid outlet parameters")
+	ErrOutletNotFound          error = errors.New("outlet not found")
+)
+
+type RepositoryGetOutletPaginationOptions struct {
+	Page   int
+	Limit  int
+	Cursor int
+}
@@ -0,0 +1,11 @@
+package outlet
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity Outlet) (id int64, err error)
+	Get(ctx context.Context, merchantID int, opts *RepositoryGetOutletPaginationOptions) (outlets []Outlet, totalData int, err error)
+	GetOutlet(ctx context.Context, merchantID, outletID int) (Outlet, error)
+	Update(ctx context.Context, entity Outlet) (err error)
+	Remove(ctx context.Context, merchantID, outletID int) (err error)
+}
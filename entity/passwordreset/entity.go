@@ -0,0 +1,33 @@
+package passwordreset
+
+import (
+	"errors"
+	"time"
+)
+
+// TTL is how long a reset link stays usable after it is requested.
+const TTL = time.Hour
+
+const (
+	// Reset requests are throttled per email and per client IP like password
+	// logins: once the free requests are used up, each further one has to
+	// wait longer. Requests are forgotten RequestWindow after the last one.
+	AccountFreeRequests = 3
+	IPFreeRequests      = 10
+	RequestWindow       = time.Hour
+)
+
+// Token is a password reset request. Only a hash of the token mailed to the
+// user is stored, and it can be redeemed once.
+type Token struct {
+	ID        int        `db:"id" json:"id"`
+	UserID    int        `db:"user_id" json:"userID"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at"`
+}
+
+var (
+	ErrInvalidResetToken error = errors.New("reset token is invalid or has expired")
+)
@@ -0,0 +1,13 @@
+package passwordreset
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/POS/entity/user"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entity Token) (id int64, err error)
+	FindUser(ctx context.Context, tokenHash string) (entity user.User, err error)
+	Redeem(ctx context.Context, tokenHash, hashedPassword string) (userID int, err error)
+}
@@ -0,0 +1,121 @@
+package payment
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Payment is one tender applied to a sale. Reference holds the gateway
+// transaction id for card and e-wallet payments, or the store credit number.
+// Token is the card or QR token handed to the gateway and is never stored.
+type Payment struct {
+	ID        int       `db:"id" json:"id"`
+	SaleID    int       `db:"sale_id" json:"saleID"`
+	Method    Method    `db:"method" json:"method"`
+	Amount    float64   `db:"amount" json:"amount"`
+	Reference *string   `db:"reference" json:"reference"`
+	Token     string    `json:"-"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// OutletPaymentMethod records whether an outlet accepts a payment method.
+type OutletPaymentMethod struct {
+	OutletID int    `db:"outlet_id" json:"outletID"`
+	Method   Method `db:"method" json:"method"`
+	Enabled  bool   `db:"enabled" json:"enabled"`
+}
+
+type Method string
+
+const (
+	MethodCash        Method = "cash"
+	MethodCard        Method = "card"
+	MethodEWallet     Method = "e_wallet"
+	MethodStoreCredit Method = "store_credit"
+)
+
+// Methods lists every payment method in the order they are presented.
+var Methods = []Method{MethodCash, MethodCard, MethodEWallet, MethodStoreCredit}
+
+func (m Method) Valid() bool {
+	switch m {
+	case MethodCash, MethodCard, MethodEWallet, MethodStoreCredit:
+		return true
+	}
+	return false
+}
+
+// RequiresGateway reports whether payments of m must be authorized by the
+// payment gateway before the sale is recorded.
+func (m Method) RequiresGateway() bool {
+	return m == MethodCard || m == MethodEWallet
+}
+
+// EnabledByDefault reports whether an outlet accepts m before the merchant
+// has configured it. Only cash is accepted out of the box.
+func (m Method) EnabledByDefault() bool {
+	return m == MethodCash
+}
+
+// GatewayTransaction is the gateway's view of a card or e-wallet payment.
+type GatewayTransaction struct {
+	ID       string        `json:"id"`
+	Method   Method        `json:"method"`
+	Amount   float64       `json:"amount"`
+	Captured float64       `json:"captured"`
+	Refunded float64       `json:"refunded"`
+	Status   GatewayStatus `json:"status"`
+}
+
+type GatewayStatus string
+
+const (
+	GatewayStatusAuthorized        GatewayStatus = "authorized"
+	GatewayStatusCaptured          GatewayStatus = "captured"
+	GatewayStatusPartiallyRefunded GatewayStatus = "partially_refunded"
+	GatewayStatusRefunded          GatewayStatus = "refunded"
+	GatewayStatusReleased          GatewayStatus = "released"
+)
+
+var (
+	ErrInvalidPaymentMethod  error = errors.New("invalid payment method")
+	ErrInvalidPayment        error = errors.New("payment must have a method and a positive amount")
+	ErrPaymentMethodDisabled error = errors.New("payment method is not enabled for this outlet")
+	ErrInsufficientPayment   error = errors.New("payments do not cover the sale total")
+	ErrOverpayment           error = errors.New("only cash payments may exceed the amount due")
+	ErrPaymentDeclined       error = errors.New("payment was declined")
+	ErrTransactionNotFound   error = errors.New("gateway transaction not found")
+	ErrInvalidTransition     error = errors.New("gateway transaction is not in a state that allows this operation")
+	ErrCaptureFailed         error = errors.New("payment gateway could not capture the payment")
+	ErrGatewayUnavailable    error = errors.New("no payment gateway is configured for card and e-wallet payments")
+)
+
+// Settle checks that payments cover total and returns the change due. Change
+// can only be handed back in cash, so the overpaid amount may not exceed the
+// cash tendered.
+func Settle(total float64, payments []Payment) (paid, change float64, err error) {
+	var cash float64
+
+	for _, p := range payments {
+		paid = round(paid + p.Amount)
+		if p.Method == MethodCash {
+			cash = round(cash + p.Amount)
+		}
+	}
+
+	if paid < total {
+		return 0, 0, ErrInsufficientPayment
+	}
+
+	change = round(paid - total)
+	if change > cash {
+		return 0, 0, ErrOverpayment
+	}
+
+	return paid, change, nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,8 @@
+package payment
+
+import "context"
+
+type Repository interface {
+	GetOutletMethods(ctx context.Context, outletID int) (methods []OutletPaymentMethod, err error)
+	SetOutletMethod(ctx context.Context, entity OutletPaymentMethod) (err error)
+}
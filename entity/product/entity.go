@@ -0,0 +1,29 @@
+package product
+
+import (
+	"errors"
+	"time"
+)
+
+type Product struct {
+	ID           int        `db:"id" json:"id"`
+	MerchantID   int        `db:"merchant_id" json:"merchantID"`
+	SKU          string     `db:"sku" json:"sku"`
+	Name         string     `db:"name" json:"name"`
+	DisplayImage *string    `db:"display_image" json:"displayImage"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt    *time.Time `db:"deleted_at" json:"-"`
+}
+
+var (
+	ErrInvalidProductParameters error = errors.New("invalid product parameters")
+	ErrProductNotFound          error = errors.New("product not found")
+	ErrSKUNotUnique             error = errors.New("sku is already used by another product")
+)
+
+type RepositoryGetProductPaginationOptions struct {
+	Page   int
+	Limit  int
+	Cursor int
+}
@@ -0,0 +1,12 @@
+package product
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity Product) (id int64, err error)
+	Get(ctx context.Context, merchantID int, opts *RepositoryGetProductPaginationOptions) (products []Product, totalData int, err error)
+	GetProduct(ctx context.Context, merchantID, productID int) (Product, error)
+	FindProductBySKU(ctx context.Context, merchantID int, sku string) (*Product, error)
+	Update(ctx context.Context, entity Product) (err error)
+	Remove(ctx context.Context, merchantID, productID int) (err error)
+}
@@ -0,0 +1,186 @@
+package refund
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/payment"
+)
+
+// Refund gives back some or all of a sale. Payments tells how the money goes
+// back to the customer, one entry per tender of the sale that pays out.
+type Refund struct {
+	ID         int        `db:"id" json:"id"`
+	SaleID     int        `db:"sale_id" json:"saleID"`
+	MerchantID int        `db:"merchant_id" json:"merchantID"`
+	OutletID   int        `db:"outlet_id" json:"outletID"`
+	ShiftID    *int       `db:"shift_id" json:"shiftID"`
+	UserID     int        `db:"user_id" json:"userID"`
+	Type       Type       `db:"type" json:"type"`
+	Reason     ReasonCode `db:"reason" json:"reason"`
+	Note       *string    `db:"note" json:"note"`
+	Total      float64    `db:"total" json:"total"`
+	Items      []Item     `json:"items,omitempty"`
+	Payments   []Payment  `json:"payments,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Payment is the part of a refund paid back in one tender. Reference is the
+// gateway transaction refunded for card and e-wallet payments, which stay
+// pending until the gateway has paid them back.
+type Payment struct {
+	ID        int            `db:"id" json:"id"`
+	RefundID  int            `db:"refund_id" json:"refundID"`
+	Method    payment.Method `db:"method" json:"method"`
+	Amount    float64        `db:"amount" json:"amount"`
+	Reference *string        `db:"reference" json:"reference"`
+	Status    PaymentStatus  `db:"status" json:"status"`
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending  PaymentStatus = "pending"
+	PaymentStatusRefunded PaymentStatus = "refunded"
+)
+
+// IdempotencyKey identifies the gateway refund of p, so the gateway pays it
+// back once however often it is sent.
+func (p Payment) IdempotencyKey() string {
+	return fmt.Sprintf("refund-payment-%d", p.ID)
+}
+
+// Cash is how much of the refund is paid out of the cash drawer.
+func (r Refund) Cash() float64 {
+	var cash float64
+	for _, p := range r.Payments {
+		if p.Method == payment.MethodCash {
+			cash = round(cash + p.Amount)
+		}
+	}
+	return cash
+}
+
+// Allocate splits a refund of total over the tenders of the sale, given the
+// payments earlier refunds of the sale already made. Money goes back the way
+// it came: card and e-wallet payments first, each to its own transaction,
+// then store credit and cash last. Cash counts net of the change handed back.
+// Card and e-wallet parts come back pending, everything else as refunded.
+func Allocate(total float64, paid []payment.Payment, change float64, earlier []Payment) ([]Payment, error) {
+	type tender struct {
+		method    payment.Method
+		reference *string
+		left      float64
+	}
+	var gateway []tender
+	pooled := map[payment.Method]float64{}
+
+	for _, p := range paid {
+		if p.Method.RequiresGateway() {
+			gateway = append(gateway, tender{method: p.Method, reference: p.Reference, left: p.Amount})
+			continue
+		}
+		pooled[p.Method] = round(pooled[p.Method] + p.Amount)
+	}
+	pooled[payment.MethodCash] = round(pooled[payment.MethodCash] - change)
+
+	for _, p := range earlier {
+		if !p.Method.RequiresGateway() {
+			pooled[p.Method] = round(pooled[p.Method] - p.Amount)
+			continue
+		}
+		for i := range gateway {
+			if gateway[i].reference != nil && p.Reference != nil && *gateway[i].reference == *p.Reference {
+				gateway[i].left = round(gateway[i].left - p.Amount)
+				break
+			}
+		}
+	}
+
+	tenders := append(gateway,
+		tender{method: payment.MethodStoreCredit, left: pooled[payment.MethodStoreCredit]},
+		tender{method: payment.MethodCash, left: pooled[payment.MethodCash]},
+	)
+
+	var payments []Payment
+	due := round(total)
+	for _, t := range tenders {
+		if due <= 0 {
+			break
+		}
+		if t.left <= 0 {
+			continue
+		}
+
+		status := PaymentStatusRefunded
+		if t.method.RequiresGateway() {
+			status = PaymentStatusPending
+		}
+
+		amount := math.Min(due, t.left)
+		payments = append(payments, Payment{Method: t.method, Amount: amount, Reference: t.reference, Status: status})
+		due = round(due - amount)
+	}
+
+	if due > 0 {
+		return nil, ErrRefundExceedsPaid
+	}
+	return payments, nil
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+type Item struct {
+	ID         int     `db:"id" json:"id"`
+	RefundID   int     `db:"refund_id" json:"refundID"`
+	SaleItemID int     `db:"sale_item_id" json:"saleItemID"`
+	ProductID  int     `db:"product_id" json:"productID"`
+	Quantity   int     `db:"quantity" json:"quantity"`
+	Amount     float64 `db:"amount" json:"amount"`
+}
+
+// Type tells a void, which cancels a whole sale, apart from a refund of some
+// or all of its lines.
+type Type string
+
+const (
+	TypeVoid   Type = "void"
+	TypeRefund Type = "refund"
+)
+
+type ReasonCode string
+
+const (
+	ReasonCustomerReturn ReasonCode = "customer_return"
+	ReasonDamaged        ReasonCode = "damaged"
+	ReasonWrongItem      ReasonCode = "wrong_item"
+	ReasonPricingError   ReasonCode = "pricing_error"
+	ReasonCashierError   ReasonCode = "cashier_error"
+	ReasonOther          ReasonCode = "other"
+)
+
+func (r ReasonCode) Valid() bool {
+	switch r {
+	case ReasonCustomerReturn, ReasonDamaged, ReasonWrongItem, ReasonPricingError, ReasonCashierError, ReasonOther:
+		return true
+	}
+	return false
+}
+
+var (
+	ErrInvalidReason       error = errors.New("invalid refund reason")
+	ErrEmptyRefund         error = errors.New("refund must have at least one item")
+	ErrInvalidRefundItem   error = errors.New("refund item must have a product and a positive quantity")
+	ErrItemNotInSale       error = errors.New("product was not sold in this sale")
+	ErrRefundExceedsSold   error = errors.New("refunded quantity exceeds the quantity sold")
+	ErrSaleAlreadyVoided   error = errors.New("sale is already voided")
+	ErrSaleAlreadyRefunded error = errors.New("sale already has refunds and can't be voided")
+	ErrVoidWindowExpired   error = errors.New("sale can only be voided while its shift is open")
+	ErrRefundExceedsPaid   error = errors.New("refund exceeds what is left of the sale's payments")
+	ErrGatewayRefundFailed error = errors.New("payment gateway could not refund the payment")
+	ErrRefundNotFound      error = errors.New("refund not found")
+)
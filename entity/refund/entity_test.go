@@ -0,0 +1,105 @@
+package refund_test
+
+import (
+	"testing"
+
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/refund"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocate(t *testing.T) {
+	card := "fake_card"
+	wallet := "fake_wallet"
+
+	testCases := []struct {
+		name     string
+		total    float64
+		paid     []payment.Payment
+		change   float64
+		earlier  []refund.Payment
+		expected []refund.Payment
+		err      error
+	}{
+		{
+			name:     "cash sale is refunded in cash",
+			total:    30,
+			paid:     []payment.Payment{{Method: payment.MethodCash, Amount: 50}},
+			change:   5,
+			expected: []refund.Payment{{Method: payment.MethodCash, Amount: 30, Status: refund.PaymentStatusRefunded}},
+		},
+		{
+			name:     "card sale is refunded to the card",
+			total:    30,
+			paid:     []payment.Payment{{Method: payment.MethodCard, Amount: 45, Reference: &card}},
+			expected: []refund.Payment{{Method: payment.MethodCard, Amount: 30, Reference: &card, Status: refund.PaymentStatusPending}},
+		},
+		{
+			name:  "split tender refunds the card before cash",
+			total: 50,
+			paid: []payment.Payment{
+				{Method: payment.MethodCash, Amount: 40},
+				{Method: payment.MethodCard, Amount: 30, Reference: &card},
+			},
+			change: 10,
+			expected: []refund.Payment{
+				{Method: payment.MethodCard, Amount: 30, Reference: &card, Status: refund.PaymentStatusPending},
+				{Method: payment.MethodCash, Amount: 20, Status: refund.PaymentStatusRefunded},
+			},
+		},
+		{
+			name:  "earlier refunds are taken off each tender",
+			total: 20,
+			paid: []payment.Payment{
+				{Method: payment.MethodCard, Amount: 30, Reference: &card},
+				{Method: payment.MethodEWallet, Amount: 20, Reference: &wallet},
+				{Method: payment.MethodCash, Amount: 10},
+			},
+			earlier: []refund.Payment{{Method: payment.MethodCard, Amount: 25, Reference: &card}},
+			expected: []refund.Payment{
+				{Method: payment.MethodCard, Amount: 5, Reference: &card, Status: refund.PaymentStatusPending},
+				{Method: payment.MethodEWallet, Amount: 15, Reference: &wallet, Status: refund.PaymentStatusPending},
+			},
+		},
+		{
+			name:  "store credit goes back before cash",
+			total: 15,
+			paid: []payment.Payment{
+				{Method: payment.MethodCash, Amount: 10},
+				{Method: payment.MethodStoreCredit, Amount: 10},
+			},
+			expected: []refund.Payment{
+				{Method: payment.MethodStoreCredit, Amount: 10, Status: refund.PaymentStatusRefunded},
+				{Method: payment.MethodCash, Amount: 5, Status: refund.PaymentStatusRefunded},
+			},
+		},
+		{
+			name:    "more than is left of the payments",
+			total:   20,
+			paid:    []payment.Payment{{Method: payment.MethodCash, Amount: 30}},
+			change:  5,
+			earlier: []refund.Payment{{Method: payment.MethodCash, Amount: 10}},
+			err:     refund.ErrRefundExceedsPaid,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			payments, err := refund.Allocate(tc.total, tc.paid, tc.change, tc.earlier)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, payments)
+		})
+	}
+}
+
+func TestRefund_Cash(t *testing.T) {
+	entity := refund.Refund{Payments: []refund.Payment{
+		{Method: payment.MethodCard, Amount: 12.5},
+		{Method: payment.MethodCash, Amount: 7.25},
+	}}
+
+	assert.Equal(t, 7.25, entity.Cash())
+}
@@ -0,0 +1,9 @@
+package refund
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity Refund) (Refund, error)
+	GetBySale(ctx context.Context, merchantID, saleID int) ([]Refund, error)
+	MarkPaymentRefunded(ctx context.Context, paymentID int) error
+}
@@ -0,0 +1,161 @@
+package role
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Permission is a single action a role allows. Routes require exactly one.
+type Permission string
+
+const (
+	PermissionUserRead            Permission = "user:read"
+	PermissionUserWrite           Permission = "user:write"
+	PermissionUserPurge           Permission = "user:purge"
+	PermissionRoleManage          Permission = "role:manage"
+	PermissionAPIKeyManage        Permission = "api_key:manage"
+	PermissionMerchantRead        Permission = "merchant:read"
+	PermissionMerchantManage      Permission = "merchant:manage"
+	PermissionOutletRead          Permission = "outlet:read"
+	PermissionOutletWrite         Permission = "outlet:write"
+	PermissionProductRead         Permission = "product:read"
+	PermissionProductWrite        Permission = "product:write"
+	PermissionInventoryRead       Permission = "inventory:read"
+	PermissionInventoryWrite      Permission = "inventory:write"
+	PermissionPaymentMethodManage Permission = "payment_method:manage"
+	PermissionShiftOperate        Permission = "shift:operate"
+	PermissionShiftRead           Permission = "shift:read"
+	PermissionSaleCreate          Permission = "sale:create"
+	PermissionSaleRead            Permission = "sale:read"
+	PermissionSaleVoid            Permission = "sale:void"
+	PermissionSaleRefund          Permission = "sale:refund"
+
+	// PermissionPlatformAdmin lets the platform operator create and list
+	// every merchant. It is not in Permissions, so no seeded or custom role
+	// of a tenant can hold it; operators grant it to their own role by hand.
+	PermissionPlatformAdmin Permission = "platform:admin"
+)
+
+// Permissions lists every permission a role can be given.
+var Permissions = []Permission{
+	PermissionUserRead,
+	PermissionUserWrite,
+	PermissionUserPurge,
+	PermissionRoleManage,
+	PermissionAPIKeyManage,
+	PermissionMerchantRead,
+	PermissionMerchantManage,
+	PermissionOutletRead,
+	PermissionOutletWrite,
+	PermissionProductRead,
+	PermissionProductWrite,
+	PermissionInventoryRead,
+	PermissionInventoryWrite,
+	PermissionPaymentMethodManage,
+	PermissionShiftOperate,
+	PermissionShiftRead,
+	PermissionSaleCreate,
+	PermissionSaleRead,
+	PermissionSaleVoid,
+	PermissionSaleRefund,
+}
+
+func (p Permission) Valid() bool {
+	for _, known := range Permissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// The built-in roles are seeded with these ids and shared by every merchant.
+const (
+	OwnerRoleID   = 1
+	ManagerRoleID = 2
+	CashierRoleID = 3
+)
+
+var builtinNames = []string{"owner", "manager", "cashier"}
+
+// Role is a named permission set. Built-in roles have no MerchantID; custom
+// roles belong to the merchant that created them.
+type Role struct {
+	ID          int          `db:"id" json:"id"`
+	MerchantID  *int         `db:"merchant_id" json:"merchantID"`
+	Name        string       `db:"name" json:"name"`
+	Permissions []Permission `db:"permissions" json:"permissions"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time   `db:"deleted_at" json:"-"`
+}
+
+func (r Role) Builtin() bool {
+	return r.MerchantID == nil
+}
+
+// Validate checks a custom role before it is stored. Custom roles may not
+// reuse a built-in name, which would make them indistinguishable in tokens.
+func (r Role) Validate() error {
+	name := strings.ToLower(strings.TrimSpace(r.Name))
+	if name == "" || len(r.Permissions) == 0 {
+		return ErrInvalidRoleParameters
+	}
+
+	for _, builtin := range builtinNames {
+		if name == builtin {
+			return ErrRoleNameNotUnique
+		}
+	}
+
+	for _, p := range r.Permissions {
+		if !p.Valid() {
+			return ErrInvalidRoleParameters
+		}
+	}
+	return nil
+}
+
+// Covers reports whether granted includes every permission in required. A
+// user may only hand out roles, or manage users, whose permissions they hold
+// themselves.
+func Covers(granted, required []Permission) bool {
+	held := make(map[Permission]bool, len(granted))
+	for _, p := range granted {
+		held[p] = true
+	}
+
+	for _, p := range required {
+		if !held[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect returns the permissions of a that are also in b.
+func Intersect(a, b []Permission) []Permission {
+	permissions := make([]Permission, 0, len(a))
+	for _, p := range a {
+		if Has(b, p) {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
+
+// Has reports whether p is among permissions.
+func Has(permissions []Permission, p Permission) bool {
+	return Covers(permissions, []Permission{p})
+}
+
+var (
+	ErrInvalidRoleParameters   error = errors.New("role must have a name and valid permissions")
+	ErrRoleNotFound            error = errors.New("role not found")
+	ErrRoleNameNotUnique       error = errors.New("role name is already used")
+	ErrBuiltinRole             error = errors.New("built-in roles cannot be changed")
+	ErrRoleInUse               error = errors.New("role is still assigned to users")
+	ErrInsufficientPermissions error = errors.New("cannot grant or manage permissions you do not hold")
+	ErrForbidden               error = errors.New("forbidden")
+)
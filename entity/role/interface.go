@@ -0,0 +1,11 @@
+package role
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity Role) (id int64, err error)
+	Get(ctx context.Context, merchantID int) (roles []Role, err error)
+	GetRole(ctx context.Context, merchantID, roleID int) (Role, error)
+	Update(ctx context.Context, entity Role) (err error)
+	Remove(ctx context.Context, merchantID, roleID int) (err error)
+}
@@ -0,0 +1,59 @@
+package sale
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/payment"
+)
+
+// Sale is a completed checkout. Paid is the sum of all payments and Change is
+// the cash handed back, so Paid - Change always equals Total.
+type Sale struct {
+	ID         int               `db:"id" json:"id"`
+	MerchantID int               `db:"merchant_id" json:"merchantID"`
+	OutletID   int               `db:"outlet_id" json:"outletID"`
+	ShiftID    int               `db:"shift_id" json:"shiftID"`
+	CashierID  int               `db:"cashier_id" json:"cashierID"`
+	Status     Status            `db:"status" json:"status"`
+	Total      float64           `db:"total" json:"total"`
+	Paid       float64           `db:"paid" json:"paid"`
+	Change     float64           `db:"change_due" json:"change"`
+	Items      []Item            `json:"items,omitempty"`
+	Payments   []payment.Payment `json:"payments,omitempty"`
+	CreatedAt  time.Time         `db:"created_at" json:"created_at"`
+}
+
+// Item is a sale line. SKU, Name and Price are copied from the product and
+// outlet price at checkout so later catalog changes do not rewrite history.
+type Item struct {
+	ID        int     `db:"id" json:"id"`
+	SaleID    int     `db:"sale_id" json:"saleID"`
+	ProductID int     `db:"product_id" json:"productID"`
+	SKU       string  `db:"sku" json:"sku"`
+	Name      string  `db:"name" json:"name"`
+	Quantity  int     `db:"quantity" json:"quantity"`
+	Price     float64 `db:"price" json:"price"`
+	Subtotal  float64 `db:"subtotal" json:"subtotal"`
+}
+
+type Status string
+
+const (
+	StatusCompleted         Status = "completed"
+	StatusPartiallyRefunded Status = "partially_refunded"
+	StatusRefunded          Status = "refunded"
+	StatusVoided            Status = "voided"
+)
+
+var (
+	ErrEmptySale       error = errors.New("sale must have at least one item")
+	ErrInvalidSaleItem error = errors.New("sale item must have a product and a positive quantity")
+	ErrSaleNotFound    error = errors.New("sale not found")
+)
+
+type RepositoryGetSalePaginationOptions struct {
+	Page   int
+	Limit  int
+	Cursor int
+}
@@ -0,0 +1,9 @@
+package sale
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, entity Sale) (Sale, error)
+	Get(ctx context.Context, merchantID, outletID int, opts *RepositoryGetSalePaginationOptions) (sales []Sale, totalData int, err error)
+	GetSale(ctx context.Context, merchantID, saleID int) (Sale, error)
+}
@@ -0,0 +1,60 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/role"
+)
+
+const (
+	// AccessTokenTTL keeps access tokens short-lived; clients renew them with
+	// the refresh token instead of logging in again.
+	AccessTokenTTL = 15 * time.Minute
+
+	// RefreshTokenTTL is how long a session survives without being refreshed.
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Session is a login of one user. Only a hash of the current refresh token is
+// stored, and it changes on every refresh. Sessions started by PIN login are
+// tied to an outlet and keep only cashier permissions across refreshes.
+type Session struct {
+	ID               string     `db:"id" json:"id"`
+	UserID           int        `db:"user_id" json:"userID"`
+	MerchantID       int        `db:"merchant_id" json:"merchantID"`
+	OutletID         *int       `db:"outlet_id" json:"outletID"`
+	RefreshTokenHash string     `db:"refresh_token_hash" json:"-"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt        time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt        *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Identity is what an access token asserts about its bearer. A non-zero
+// OutletID limits the token to that outlet.
+type Identity struct {
+	UserID      int
+	MerchantID  int
+	OutletID    int
+	Email       string
+	SessionID   string
+	Role        string
+	Permissions []role.Permission
+}
+
+// Token is the pair handed to a client when a session starts or is refreshed.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+var (
+	ErrSessionNotFound     error = errors.New("session not found")
+	ErrInvalidRefreshToken error = errors.New("invalid refresh token")
+	ErrSessionRevoked      error = errors.New("session is no longer active")
+)
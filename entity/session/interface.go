@@ -0,0 +1,16 @@
+package session
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, entity Session) (err error)
+	GetSession(ctx context.Context, sessionID string) (Session, error)
+	Rotate(ctx context.Context, sessionID, currentHash, newHash string, expiresAt time.Time) (err error)
+	Revoke(ctx context.Context, sessionID string) (err error)
+	RevokeUserSessions(ctx context.Context, userID int) (err error)
+	RevokeOtherSessions(ctx context.Context, userID int, keepSessionID string) (err error)
+	IsActive(ctx context.Context, sessionID string, userID int) (active bool, err error)
+}
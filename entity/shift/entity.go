@@ -0,0 +1,42 @@
+package shift
+
+import (
+	"errors"
+	"time"
+)
+
+// Shift is a cash drawer session of one user on one outlet. ExpectedCash is
+// the opening float plus cash taken minus cash refunded, and Difference is
+// CountedCash minus ExpectedCash: positive when the drawer is over, negative
+// when it is short.
+type Shift struct {
+	ID           int        `db:"id" json:"id"`
+	MerchantID   int        `db:"merchant_id" json:"merchantID"`
+	OutletID     int        `db:"outlet_id" json:"outletID"`
+	UserID       int        `db:"user_id" json:"userID"`
+	OpeningFloat float64    `db:"opening_float" json:"openingFloat"`
+	ExpectedCash float64    `db:"expected_cash" json:"expectedCash"`
+	CountedCash  *float64   `db:"counted_cash" json:"countedCash"`
+	Difference   *float64   `db:"difference" json:"difference"`
+	Note         *string    `db:"note" json:"note"`
+	OpenedAt     time.Time  `db:"opened_at" json:"openedAt"`
+	ClosedAt     *time.Time `db:"closed_at" json:"closedAt"`
+}
+
+func (s Shift) IsOpen() bool {
+	return s.ClosedAt == nil
+}
+
+var (
+	ErrInvalidShiftParameters error = errors.New("invalid shift parameters")
+	ErrShiftNotFound          error = errors.New("shift not found")
+	ErrShiftAlreadyOpen       error = errors.New("user already has an open shift on this outlet")
+	ErrShiftClosed            error = errors.New("shift is already closed")
+	ErrNoOpenShift            error = errors.New("no open shift for this user on this outlet")
+)
+
+type RepositoryGetShiftPaginationOptions struct {
+	Page   int
+	Limit  int
+	Cursor int
+}
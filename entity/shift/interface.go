@@ -0,0 +1,11 @@
+package shift
+
+import "context"
+
+type Repository interface {
+	Open(ctx context.Context, entity Shift) (id int64, err error)
+	Close(ctx context.Context, entity Shift) (Shift, error)
+	Get(ctx context.Context, merchantID, outletID int, opts *RepositoryGetShiftPaginationOptions) (shifts []Shift, totalData int, err error)
+	GetShift(ctx context.Context, merchantID, shiftID int) (Shift, error)
+	FindOpenShift(ctx context.Context, merchantID, outletID, userID int) (Shift, error)
+}
@@ -0,0 +1,86 @@
+package twofactor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/role"
+)
+
+const (
+	// ChallengeTTL is how long a user has to enter their code after the
+	// password step of a login.
+	ChallengeTTL = 5 * time.Minute
+
+	// MaxChallengeAttempts wrong codes void a challenge; the user has to log
+	// in with their password again.
+	MaxChallengeAttempts = 5
+
+	// MaxOpenChallenges caps the challenges a user can have open at once, so
+	// repeating the password step does not buy more guesses at the code.
+	// Wrong codes also count against the account's login lockout.
+	MaxOpenChallenges = 3
+
+	RecoveryCodeCount = 10
+)
+
+// Enrollment is a user's TOTP secret. It only guards logins once EnabledAt is
+// set, which happens when the user proves their authenticator app works.
+// LastUsedStep is the time step of the last accepted code, so a code cannot
+// be used twice.
+type Enrollment struct {
+	UserID       int        `db:"user_id" json:"userID"`
+	Secret       string     `db:"secret" json:"-"`
+	LastUsedStep int64      `db:"last_used_step" json:"-"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	EnabledAt    *time.Time `db:"enabled_at" json:"enabled_at"`
+}
+
+func (e Enrollment) Enabled() bool {
+	return e.EnabledAt != nil
+}
+
+// Setup is what a user needs to add the account to an authenticator app.
+// Recovery codes are only ever shown here; just their hashes are stored.
+type Setup struct {
+	Secret        string
+	URI           string
+	RecoveryCodes []string
+}
+
+// Challenge is the half-finished login between the password and the code.
+// Only a hash of the challenge token handed to the client is stored.
+type Challenge struct {
+	ID             int        `db:"id" json:"id"`
+	UserID         int        `db:"user_id" json:"userID"`
+	MerchantID     int        `db:"merchant_id" json:"merchantID"`
+	TokenHash      string     `db:"token_hash" json:"-"`
+	FailedAttempts int        `db:"failed_attempts" json:"-"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt         *time.Time `db:"used_at" json:"used_at"`
+}
+
+// LoginChallenge is returned by a password login that still needs a code.
+// EnrollmentRequired tells the client the user has to set up an
+// authenticator app before they can answer it.
+type LoginChallenge struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
+// Required reports whether users of the role must use two-factor
+// authentication. Owners and managers can change staff, prices and money
+// settings, so a leaked password alone must not be enough.
+func Required(roleID int) bool {
+	return roleID == role.OwnerRoleID || roleID == role.ManagerRoleID
+}
+
+var (
+	ErrNotEnrolled      error = errors.New("two-factor authentication is not set up")
+	ErrAlreadyEnabled   error = errors.New("two-factor authentication is already enabled")
+	ErrRequired         error = errors.New("two-factor authentication is required for this role")
+	ErrInvalidCode      error = errors.New("invalid authentication code")
+	ErrInvalidChallenge error = errors.New("login challenge is invalid or has expired")
+)
@@ -0,0 +1,16 @@
+package twofactor
+
+import "context"
+
+type Repository interface {
+	GetEnrollment(ctx context.Context, userID int) (Enrollment, error)
+	Enroll(ctx context.Context, entity Enrollment, recoveryCodeHashes []string) (err error)
+	Enable(ctx context.Context, userID int, step int64) (err error)
+	UseStep(ctx context.Context, userID int, step int64) (err error)
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (err error)
+	Disable(ctx context.Context, userID int) (err error)
+	CreateChallenge(ctx context.Context, entity Challenge) (err error)
+	GetChallenge(ctx context.Context, tokenHash string) (Challenge, error)
+	RecordChallengeFailure(ctx context.Context, challengeID int) (err error)
+	RedeemChallenge(ctx context.Context, challengeID int) (err error)
+}
@@ -3,18 +3,137 @@ package user
 import (
 	"errors"
 	"time"
+
+	"github.com/mhdiiilham/POS/entity/role"
 )
 
+// User is a member of a merchant's staff. Role and Permissions are read from
+// the user's role and are not stored on the user itself. A Pending user was
+// invited and has not chosen a password yet, so they cannot log in.
 type User struct {
-	ID         int        `db:"id" json:"id"`
-	MerchantID int        `db:"merchant_id" json:"merchantID"`
-	Email      string     `db:"email" json:"email"`
-	Password   string     `db:"password" json:"-"`
-	FirstName  string     `db:"firstname" json:"firstname"`
-	LastName   *string    `db:"lastname" json:"lastname"`
-	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt  *time.Time `db:"deleted_at" json:"-"`
+	ID             int               `db:"id" json:"id"`
+	MerchantID     int               `db:"merchant_id" json:"merchantID"`
+	RoleID         int               `db:"role_id" json:"roleID"`
+	Role           string            `json:"role"`
+	Permissions    []role.Permission `json:"-"`
+	Email          string            `db:"email" json:"email"`
+	Password       string            `db:"password" json:"-"`
+	PIN            string            `db:"pin_hash" json:"-"`
+	PINAttempts    int               `db:"pin_failed_attempts" json:"-"`
+	PINLockedUntil *time.Time        `db:"pin_locked_until" json:"-"`
+	FirstName      string            `db:"firstname" json:"firstname"`
+	LastName       *string           `db:"lastname" json:"lastname"`
+	Pending        bool              `db:"pending" json:"pending"`
+	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time         `db:"updated_at" json:"updated_at"`
+	DeletedAt      *time.Time        `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+const (
+	// MaxPINAttempts wrong PINs in a row lock PIN login for PINLockout. Every
+	// further wrong PIN doubles the lock, up to PINLockoutMax; the count only
+	// starts again after a successful PIN login or an unlock. A client IP gets
+	// IPFreePINAttempts wrong PINs, across all users, before it is backed off
+	// like a password login.
+	MaxPINAttempts    = 5
+	PINLockout        = 15 * time.Minute
+	PINLockoutMax     = 24 * time.Hour
+	IPFreePINAttempts = 20
+)
+
+const (
+	// Password logins are throttled per account and per client IP. Once the
+	// free attempts are used up, every further failure doubles the wait, from
+	// LoginBackoffBase up to LoginBackoffMax. Failures are forgotten
+	// LoginAttemptWindow after the last one.
+	AccountFreeLoginAttempts = 5
+	IPFreeLoginAttempts      = 20
+	LoginBackoffBase         = 30 * time.Second
+	LoginBackoffMax          = 15 * time.Minute
+	LoginAttemptWindow       = 24 * time.Hour
+)
+
+// LoginAttempts is the failed login record of one account or client IP.
+// Attempts are counted when they start and handed back when they succeed, so
+// Failures also includes attempts still in flight.
+type LoginAttempts struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// LockedUntil is when the next attempt is allowed, given how many failures
+// are free. It is the zero time when no wait is needed.
+func (a LoginAttempts) LockedUntil(free int) time.Time {
+	excess := a.Failures - free
+	if excess < 0 {
+		return time.Time{}
+	}
+
+	backoff := LoginBackoffMax
+	if excess < 16 {
+		if d := LoginBackoffBase << uint(excess); d < LoginBackoffMax {
+			backoff = d
+		}
+	}
+	return a.LastFailure.Add(backoff)
+}
+
+// ValidPIN reports whether pin is 4 to 6 digits.
+func ValidPIN(pin string) bool {
+	if len(pin) < 4 || len(pin) > 6 {
+		return false
+	}
+
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// LockoutError is returned while a login method is locked after too many
+// failed attempts. It matches ErrLockedOut with errors.Is.
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return ErrLockedOut.Error()
+}
+
+func (e *LockoutError) Is(target error) bool {
+	return target == ErrLockedOut
+}
+
+// PasswordViolation is one password policy rule a password failed.
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every password policy rule a password failed. It
+// matches ErrInvalidPassword with errors.Is.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation `json:"violations"`
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrInvalidPassword.Error()
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrInvalidPassword
+}
+
+// Profile holds the fields a profile update may change. Nil fields are left
+// as they are. CurrentPassword is not changed; it confirms a user changing
+// their own email.
+type Profile struct {
+	Email           *string
+	FirstName       *string
+	LastName        *string
+	CurrentPassword *string
 }
 
 var (
@@ -23,10 +142,78 @@ var (
 	ErrInvalidCreateParameters error = errors.New("failed creating user due to invalid parameters")
 	ErrEmailNotUnique          error = errors.New("email is already registered")
 	ErrUserNotFound            error = errors.New("user not found")
+	ErrInvalidUpdateParameters error = errors.New("failed updating user due to invalid parameters")
+	ErrInvalidPassword         error = errors.New("password does not meet the password policy")
+	ErrWrongPassword           error = errors.New("current password is incorrect")
+	ErrInvalidPINFormat        error = errors.New("PIN must be 4 to 6 digits")
+	ErrInvalidPIN              error = errors.New("invalid user or PIN")
+	ErrLockedOut               error = errors.New("too many failed attempts, try again later")
+	ErrInvalidStatus           error = errors.New("status must be active or deleted")
+	ErrInvalidSortField        error = errors.New("sort must be one of id, firstname, lastname, email or created_at")
+	ErrInvalidCursor           error = errors.New("invalid cursor")
+	ErrInvalidDateRange        error = errors.New("createdFrom must be before createdTo")
+)
+
+// Status picks which users a listing returns. Purged users are in neither.
+type Status string
+
+const (
+	StatusActive  Status = "active"
+	StatusDeleted Status = "deleted"
+)
+
+func (s Status) Valid() bool {
+	return s == StatusActive || s == StatusDeleted
+}
+
+// SortField is what a listing of users is ordered by. Ties are broken by ID,
+// so every order is total.
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByFirstName SortField = "firstname"
+	SortByLastName  SortField = "lastname"
+	SortByEmail     SortField = "email"
+	SortByCreatedAt SortField = "created_at"
 )
 
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByID, SortByFirstName, SortByLastName, SortByEmail, SortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+// Filter narrows a listing of users; zero fields match everyone. Search is a
+// case-insensitive substring of the full name or email, OutletID keeps users
+// who have worked a shift at the outlet, CreatedFrom is inclusive and
+// CreatedTo exclusive.
+type Filter struct {
+	Search      string
+	RoleID      int
+	OutletID    int
+	Status      Status
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+type Sort struct {
+	Field      SortField
+	Descending bool
+}
+
+// Cursor is where the previous page ended: the last user's value of the sort
+// field and their ID.
+type Cursor struct {
+	Value string
+	ID    int
+}
+
 type RepositoryGetUserPaginationOptions struct {
-	Page   int
 	Limit  int
-	Cursor int
+	Filter Filter
+	Sort   Sort
+	After  *Cursor
 }
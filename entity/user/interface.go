@@ -1,11 +1,24 @@
 package user
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 	FindUserByEmail(ctx context.Context, email string) (*User, error)
 	Create(ctx context.Context, entity User) (id int64, err error)
 	Get(ctx context.Context, merchantID int, opts *RepositoryGetUserPaginationOptions) (users []User, totalData int, err error)
-	Remove(ctx context.Context, userID int) (err error)
-	GetUser(ctx context.Context, userID int) (User, error)
+	Remove(ctx context.Context, merchantID, userID int) (err error)
+	Restore(ctx context.Context, merchantID, userID int) (err error)
+	Purge(ctx context.Context, merchantID, userID int) (err error)
+	GetUser(ctx context.Context, merchantID, userID int) (User, error)
+	GetRemovedUser(ctx context.Context, merchantID, userID int) (User, error)
+	UpdateRole(ctx context.Context, merchantID, userID, roleID int) (err error)
+	Update(ctx context.Context, entity User) (err error)
+	UpdatePassword(ctx context.Context, merchantID, userID int, hashedPassword string) (err error)
+	GetOutletUser(ctx context.Context, outletID, userID int) (User, error)
+	SetPIN(ctx context.Context, merchantID, userID int, hashedPIN string) (err error)
+	RecordPINFailure(ctx context.Context, userID int) (lockedUntil *time.Time, err error)
+	ResetPINFailures(ctx context.Context, userID int) (err error)
 }
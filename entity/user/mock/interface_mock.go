@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	user "github.com/mhdiiilham/POS/entity/user"
@@ -81,31 +82,174 @@ func (mr *MockRepositoryMockRecorder) Get(ctx, merchantID, opts interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), ctx, merchantID, opts)
 }
 
+// GetOutletUser mocks base method.
+func (m *MockRepository) GetOutletUser(ctx context.Context, outletID, userID int) (user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutletUser", ctx, outletID, userID)
+	ret0, _ := ret[0].(user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutletUser indicates an expected call of GetOutletUser.
+func (mr *MockRepositoryMockRecorder) GetOutletUser(ctx, outletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutletUser", reflect.TypeOf((*MockRepository)(nil).GetOutletUser), ctx, outletID, userID)
+}
+
+// GetRemovedUser mocks base method.
+func (m *MockRepository) GetRemovedUser(ctx context.Context, merchantID, userID int) (user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRemovedUser", ctx, merchantID, userID)
+	ret0, _ := ret[0].(user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRemovedUser indicates an expected call of GetRemovedUser.
+func (mr *MockRepositoryMockRecorder) GetRemovedUser(ctx, merchantID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemovedUser", reflect.TypeOf((*MockRepository)(nil).GetRemovedUser), ctx, merchantID, userID)
+}
+
 // GetUser mocks base method.
-func (m *MockRepository) GetUser(ctx context.Context, userID int) (user.User, error) {
+func (m *MockRepository) GetUser(ctx context.Context, merchantID, userID int) (user.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", ctx, userID)
+	ret := m.ctrl.Call(m, "GetUser", ctx, merchantID, userID)
 	ret0, _ := ret[0].(user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUser indicates an expected call of GetUser.
-func (mr *MockRepositoryMockRecorder) GetUser(ctx, userID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetUser(ctx, merchantID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, merchantID, userID)
+}
+
+// Purge mocks base method.
+func (m *MockRepository) Purge(ctx context.Context, merchantID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, merchantID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockRepositoryMockRecorder) Purge(ctx, merchantID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepository)(nil).Purge), ctx, merchantID, userID)
+}
+
+// RecordPINFailure mocks base method.
+func (m *MockRepository) RecordPINFailure(ctx context.Context, userID int) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPINFailure", ctx, userID)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPINFailure indicates an expected call of RecordPINFailure.
+func (mr *MockRepositoryMockRecorder) RecordPINFailure(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPINFailure", reflect.TypeOf((*MockRepository)(nil).RecordPINFailure), ctx, userID)
 }
 
 // Remove mocks base method.
-func (m *MockRepository) Remove(ctx context.Context, userID int) error {
+func (m *MockRepository) Remove(ctx context.Context, merchantID, userID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Remove", ctx, userID)
+	ret := m.ctrl.Call(m, "Remove", ctx, merchantID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Remove indicates an expected call of Remove.
-func (mr *MockRepositoryMockRecorder) Remove(ctx, userID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Remove(ctx, merchantID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockRepository)(nil).Remove), ctx, merchantID, userID)
+}
+
+// ResetPINFailures mocks base method.
+func (m *MockRepository) ResetPINFailures(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPINFailures", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPINFailures indicates an expected call of ResetPINFailures.
+func (mr *MockRepositoryMockRecorder) ResetPINFailures(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPINFailures", reflect.TypeOf((*MockRepository)(nil).ResetPINFailures), ctx, userID)
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(ctx context.Context, merchantID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, merchantID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(ctx, merchantID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, merchantID, userID)
+}
+
+// SetPIN mocks base method.
+func (m *MockRepository) SetPIN(ctx context.Context, merchantID, userID int, hashedPIN string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPIN", ctx, merchantID, userID, hashedPIN)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPIN indicates an expected call of SetPIN.
+func (mr *MockRepositoryMockRecorder) SetPIN(ctx, merchantID, userID, hashedPIN interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPIN", reflect.TypeOf((*MockRepository)(nil).SetPIN), ctx, merchantID, userID, hashedPIN)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(ctx context.Context, entity user.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(ctx, entity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, entity)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockRepository) UpdatePassword(ctx context.Context, merchantID, userID int, hashedPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, merchantID, userID, hashedPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockRepositoryMockRecorder) UpdatePassword(ctx, merchantID, userID, hashedPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepository)(nil).UpdatePassword), ctx, merchantID, userID, hashedPassword)
+}
+
+// UpdateRole mocks base method.
+func (m *MockRepository) UpdateRole(ctx context.Context, merchantID, userID, roleID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, merchantID, userID, roleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockRepositoryMockRecorder) UpdateRole(ctx, merchantID, userID, roleID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockRepository)(nil).Remove), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockRepository)(nil).UpdateRole), ctx, merchantID, userID, roleID)
 }
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/POS/entity/payment"
+)
+
+type disabled struct{}
+
+// NewDisabledGateway returns a gateway for deployments without an acquirer.
+// Every call fails with payment.ErrGatewayUnavailable, so card and e-wallet
+// payments are refused instead of being approved without reaching anyone.
+func NewDisabledGateway() *disabled {
+	return &disabled{}
+}
+
+func (disabled) Authorize(ctx context.Context, method payment.Method, amount float64, token string) (payment.GatewayTransaction, error) {
+	return payment.GatewayTransaction{}, payment.ErrGatewayUnavailable
+}
+
+func (disabled) Capture(ctx context.Context, transactionID string) (payment.GatewayTransaction, error) {
+	return payment.GatewayTransaction{}, payment.ErrGatewayUnavailable
+}
+
+func (disabled) Refund(ctx context.Context, transactionID string, amount float64, idempotencyKey string) (payment.GatewayTransaction, error) {
+	return payment.GatewayTransaction{}, payment.ErrGatewayUnavailable
+}
+
+func (disabled) Status(ctx context.Context, transactionID string) (payment.GatewayTransaction, error) {
+	return payment.GatewayTransaction{}, payment.ErrGatewayUnavailable
+}
@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+// DeclinedToken makes the fake gateway decline an authorization, so the
+// decline path can be exercised without a real acquirer.
+const DeclinedToken = "declined"
+
+type fake struct {
+	mu           sync.Mutex
+	transactions map[string]payment.GatewayTransaction
+	refunds      map[string]payment.GatewayTransaction
+}
+
+// NewFakeGateway returns an in-process gateway that keeps its transactions in
+// memory. It approves everything except authorizations with DeclinedToken.
+func NewFakeGateway() *fake {
+	return &fake{
+		transactions: make(map[string]payment.GatewayTransaction),
+		refunds:      make(map[string]payment.GatewayTransaction),
+	}
+}
+
+func (f *fake) Authorize(ctx context.Context, method payment.Method, amount float64, token string) (payment.GatewayTransaction, error) {
+	const ops = "pkg.gateway.fake.Authorize"
+
+	select {
+	case <-ctx.Done():
+		return payment.GatewayTransaction{}, ctx.Err()
+
+	default:
+		if token == DeclinedToken {
+			logger.Info(ctx, ops, "declining %s authorization of %.2f", method, amount)
+			return payment.GatewayTransaction{}, payment.ErrPaymentDeclined
+		}
+
+		tx := payment.GatewayTransaction{
+			ID:     "fake_" + uuid.New().String(),
+			Method: method,
+			Amount: amount,
+			Status: payment.GatewayStatusAuthorized,
+		}
+
+		f.mu.Lock()
+		f.transactions[tx.ID] = tx
+		f.mu.Unlock()
+
+		return tx, nil
+	}
+}
+
+func (f *fake) Capture(ctx context.Context, transactionID string) (payment.GatewayTransaction, error) {
+	select {
+	case <-ctx.Done():
+		return payment.GatewayTransaction{}, ctx.Err()
+
+	default:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		tx, ok := f.transactions[transactionID]
+		if !ok {
+			return payment.GatewayTransaction{}, payment.ErrTransactionNotFound
+		}
+
+		if tx.Status != payment.GatewayStatusAuthorized {
+			return payment.GatewayTransaction{}, payment.ErrInvalidTransition
+		}
+
+		tx.Captured = tx.Amount
+		tx.Status = payment.GatewayStatusCaptured
+		f.transactions[tx.ID] = tx
+		return tx, nil
+	}
+}
+
+// Refund answers a repeated idempotencyKey with the transaction as the first
+// refund left it, without refunding again.
+func (f *fake) Refund(ctx context.Context, transactionID string, amount float64, idempotencyKey string) (payment.GatewayTransaction, error) {
+	select {
+	case <-ctx.Done():
+		return payment.GatewayTransaction{}, ctx.Err()
+
+	default:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if tx, ok := f.refunds[idempotencyKey]; ok {
+			return tx, nil
+		}
+
+		tx, ok := f.transactions[transactionID]
+		if !ok {
+			return payment.GatewayTransaction{}, payment.ErrTransactionNotFound
+		}
+
+		switch tx.Status {
+		case payment.GatewayStatusAuthorized:
+			// A hold is released as a whole; part of it can't be refunded
+			// before anything was captured.
+			if amount != tx.Amount {
+				return payment.GatewayTransaction{}, payment.ErrInvalidTransition
+			}
+			tx.Status = payment.GatewayStatusReleased
+
+		case payment.GatewayStatusCaptured, payment.GatewayStatusPartiallyRefunded:
+			remaining := round(tx.Captured - tx.Refunded)
+			if amount <= 0 || amount > remaining {
+				return payment.GatewayTransaction{}, payment.ErrInvalidTransition
+			}
+
+			tx.Refunded = round(tx.Refunded + amount)
+			tx.Status = payment.GatewayStatusPartiallyRefunded
+			if tx.Refunded == tx.Captured {
+				tx.Status = payment.GatewayStatusRefunded
+			}
+
+		default:
+			return payment.GatewayTransaction{}, payment.ErrInvalidTransition
+		}
+
+		f.transactions[tx.ID] = tx
+		f.refunds[idempotencyKey] = tx
+		return tx, nil
+	}
+}
+
+func (f *fake) Status(ctx context.Context, transactionID string) (payment.GatewayTransaction, error) {
+	select {
+	case <-ctx.Done():
+		return payment.GatewayTransaction{}, ctx.Err()
+
+	default:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		tx, ok := f.transactions[transactionID]
+		if !ok {
+			return payment.GatewayTransaction{}, payment.ErrTransactionNotFound
+		}
+
+		return tx, nil
+	}
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
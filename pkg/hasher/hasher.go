@@ -2,18 +2,105 @@ package hasher
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/mhdiiilham/POS/pkg/logger"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+
+	DefaultBcryptCost      = 12
+	DefaultArgon2Time      = 3
+	DefaultArgon2MemoryKiB = 64 * 1024
+	DefaultArgon2Threads   = 4
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// ErrMismatchedHashAndPassword is returned by ComparePassword for a wrong
+// password whatever the algorithm of the hash, so callers check one error.
+var ErrMismatchedHashAndPassword = bcrypt.ErrMismatchedHashAndPassword
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// Options picks the algorithm and parameters of new hashes. Zero fields take
+// the defaults above.
+type Options struct {
+	Algorithm       string
+	BcryptCost      int
+	Argon2Time      uint32
+	Argon2MemoryKiB uint32
+	Argon2Threads   uint8
+}
+
+type argon2Params struct {
+	time      uint32
+	memoryKiB uint32
+	threads   uint8
+}
+
 type hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
 }
 
-func NewHasher() *hasher {
-	return &hasher{}
+func NewHasher(opts Options) (*hasher, error) {
+	h := &hasher{
+		algorithm:  opts.Algorithm,
+		bcryptCost: opts.BcryptCost,
+		argon2: argon2Params{
+			time:      opts.Argon2Time,
+			memoryKiB: opts.Argon2MemoryKiB,
+			threads:   opts.Argon2Threads,
+		},
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = AlgorithmBcrypt
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = DefaultBcryptCost
+	}
+	if h.argon2.time == 0 {
+		h.argon2.time = DefaultArgon2Time
+	}
+	if h.argon2.memoryKiB == 0 {
+		h.argon2.memoryKiB = DefaultArgon2MemoryKiB
+	}
+	if h.argon2.threads == 0 {
+		h.argon2.threads = DefaultArgon2Threads
+	}
+
+	switch h.algorithm {
+	case AlgorithmBcrypt:
+		if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case AlgorithmArgon2id:
+		if h.argon2.memoryKiB < 8*uint32(h.argon2.threads) {
+			return nil, fmt.Errorf("argon2 memory must be at least 8 KiB per thread, %d KiB for %d threads", 8*uint32(h.argon2.threads), h.argon2.threads)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", h.algorithm)
+	}
+
+	return h, nil
 }
 
+// HashPassword hashes with the configured algorithm. Hashes carry their
+// algorithm and parameters: bcrypt in its own $2a$ format, argon2id in the
+// PHC string format $argon2id$v=19$m=<KiB>,t=<time>,p=<threads>$<salt>$<key>.
 func (h *hasher) HashPassword(ctx context.Context, password string) (string, error) {
 	const ops = "pkg.hasher.HashPassword"
 
@@ -23,7 +110,16 @@ func (h *hasher) HashPassword(ctx context.Context, password string) (string, err
 		return "", ctx.Err()
 
 	default:
-		p, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if h.algorithm == AlgorithmArgon2id {
+			salt := make([]byte, argon2SaltLength)
+			if _, err := rand.Read(salt); err != nil {
+				logger.Error(ctx, ops, "error generating salt: %v", err)
+				return "", err
+			}
+			return encodeArgon2(h.argon2, salt, argon2.IDKey([]byte(password), salt, h.argon2.time, h.argon2.memoryKiB, h.argon2.threads, argon2KeyLength)), nil
+		}
+
+		p, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
 		if err != nil {
 			logger.Error(ctx, ops, "error trying to hash password: %v", err)
 			return "", err
@@ -32,6 +128,8 @@ func (h *hasher) HashPassword(ctx context.Context, password string) (string, err
 	}
 }
 
+// ComparePassword checks a password against a hash of either algorithm, so
+// hashes made before a change of algorithm keep working.
 func (h *hasher) ComparePassword(ctx context.Context, hashedPassword, password string) error {
 	const ops = "pkg.hasher.ComparePassword"
 
@@ -41,6 +139,74 @@ func (h *hasher) ComparePassword(ctx context.Context, hashedPassword, password s
 		return ctx.Err()
 
 	default:
-		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		if !strings.HasPrefix(hashedPassword, "$"+AlgorithmArgon2id+"$") {
+			return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		}
+
+		params, salt, key, err := decodeArgon2(hashedPassword)
+		if err != nil {
+			return err
+		}
+
+		other := argon2.IDKey([]byte(password), salt, params.time, params.memoryKiB, params.threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return ErrMismatchedHashAndPassword
+		}
+		return nil
+	}
+}
+
+// NeedsRehash reports whether a hash was made with another algorithm or
+// other parameters than the configured ones. Hashes it cannot read are left
+// alone; they fail ComparePassword anyway.
+func (h *hasher) NeedsRehash(hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$"+AlgorithmArgon2id+"$") {
+		params, _, _, err := decodeArgon2(hashedPassword)
+		if err != nil {
+			return false
+		}
+		return h.algorithm != AlgorithmArgon2id || params != h.argon2
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return false
+	}
+	return h.algorithm != AlgorithmBcrypt || cost != h.bcryptCost
+}
+
+func encodeArgon2(params argon2Params, salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version,
+		params.memoryKiB, params.time, params.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2(hashedPassword string) (params argon2Params, salt, key []byte, err error) {
+	var version int
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return argon2Params{}, nil, nil, errInvalidArgon2Hash
 	}
+
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memoryKiB, &params.time, &params.threads); err != nil || params.time == 0 || params.threads == 0 || params.memoryKiB < 8*uint32(params.threads) {
+		return argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	return params, salt, key, nil
 }
@@ -0,0 +1,168 @@
+package hasher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap parameters keep the tests fast; they are still valid hashes.
+var (
+	bcryptOptions = Options{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+	argon2Options = Options{Algorithm: AlgorithmArgon2id, Argon2Time: 1, Argon2MemoryKiB: 64, Argon2Threads: 2}
+)
+
+func newHasher(t *testing.T, opts Options) *hasher {
+	t.Helper()
+
+	h, err := NewHasher(opts)
+	require.NoError(t, err)
+	return h
+}
+
+func TestNewHasher(t *testing.T) {
+	testCases := []struct {
+		name  string
+		opts  Options
+		valid bool
+	}{
+		{name: "defaults", opts: Options{}, valid: true},
+		{name: "argon2id defaults", opts: Options{Algorithm: AlgorithmArgon2id}, valid: true},
+		{name: "bcrypt cost below the minimum", opts: Options{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost - 1}},
+		{name: "bcrypt cost above the maximum", opts: Options{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MaxCost + 1}},
+		{name: "argon2 memory below 8 KiB per thread", opts: Options{Algorithm: AlgorithmArgon2id, Argon2MemoryKiB: 31, Argon2Threads: 4}},
+		{name: "argon2 memory of exactly 8 KiB per thread", opts: Options{Algorithm: AlgorithmArgon2id, Argon2MemoryKiB: 32, Argon2Threads: 4}, valid: true},
+		{name: "unknown algorithm", opts: Options{Algorithm: "scrypt"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := NewHasher(tc.opts)
+			if tc.valid {
+				assert.NoError(t, err)
+				assert.NotNil(t, h)
+				return
+			}
+			assert.Error(t, err)
+			assert.Nil(t, h)
+		})
+	}
+}
+
+func TestHasher_ComparePassword(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("argon2id round trip", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHasher(t, argon2Options)
+		hashed, err := h.HashPassword(ctx, "correct horse")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=64,t=1,p=2$"))
+
+		assert.NoError(t, h.ComparePassword(ctx, hashed, "correct horse"))
+		assert.ErrorIs(t, h.ComparePassword(ctx, hashed, "wrong horse"), ErrMismatchedHashAndPassword)
+	})
+
+	t.Run("bcrypt hash under an argon2id hasher", func(t *testing.T) {
+		t.Parallel()
+
+		hashed, err := newHasher(t, bcryptOptions).HashPassword(ctx, "correct horse")
+		require.NoError(t, err)
+
+		h := newHasher(t, argon2Options)
+		assert.NoError(t, h.ComparePassword(ctx, hashed, "correct horse"))
+		assert.ErrorIs(t, h.ComparePassword(ctx, hashed, "wrong horse"), ErrMismatchedHashAndPassword)
+	})
+
+	t.Run("argon2id hash under a bcrypt hasher", func(t *testing.T) {
+		t.Parallel()
+
+		hashed, err := newHasher(t, argon2Options).HashPassword(ctx, "correct horse")
+		require.NoError(t, err)
+
+		h := newHasher(t, bcryptOptions)
+		assert.NoError(t, h.ComparePassword(ctx, hashed, "correct horse"))
+	})
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	ctx := context.Background()
+
+	bcryptHash, err := newHasher(t, bcryptOptions).HashPassword(ctx, "correct horse")
+	require.NoError(t, err)
+
+	argon2Hash, err := newHasher(t, argon2Options).HashPassword(ctx, "correct horse")
+	require.NoError(t, err)
+
+	with := func(mutate func(*Options)) Options {
+		opts := argon2Options
+		mutate(&opts)
+		return opts
+	}
+
+	testCases := []struct {
+		name     string
+		opts     Options
+		hashed   string
+		expected bool
+	}{
+		{name: "bcrypt, same cost", opts: bcryptOptions, hashed: bcryptHash},
+		{name: "bcrypt, other cost", opts: Options{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}, hashed: bcryptHash, expected: true},
+		{name: "bcrypt to argon2id", opts: argon2Options, hashed: bcryptHash, expected: true},
+		{name: "argon2id, same parameters", opts: argon2Options, hashed: argon2Hash},
+		{name: "argon2id to bcrypt", opts: bcryptOptions, hashed: argon2Hash, expected: true},
+		{name: "argon2id, other memory", opts: with(func(o *Options) { o.Argon2MemoryKiB = 128 }), hashed: argon2Hash, expected: true},
+		{name: "argon2id, other time", opts: with(func(o *Options) { o.Argon2Time = 2 }), hashed: argon2Hash, expected: true},
+		{name: "argon2id, other threads", opts: with(func(o *Options) { o.Argon2Threads = 1 }), hashed: argon2Hash, expected: true},
+		{name: "unreadable hash", opts: argon2Options, hashed: "$argon2id$garbage"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, newHasher(t, tc.opts).NeedsRehash(tc.hashed))
+		})
+	}
+}
+
+func TestDecodeArgon2(t *testing.T) {
+	const salt, key = "c29tZXNhbHRzb21lc2FsdA", "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+	params, decodedSalt, decodedKey, err := decodeArgon2("$argon2id$v=19$m=64,t=1,p=2$" + salt + "$" + key)
+	require.NoError(t, err)
+	assert.Equal(t, argon2Params{time: 1, memoryKiB: 64, threads: 2}, params)
+	assert.Equal(t, "somesaltsomesalt", string(decodedSalt))
+	assert.Len(t, decodedKey, 32)
+
+	for name, hashed := range map[string]string{
+		"other algorithm":         "$argon2i$v=19$m=64,t=1,p=2$" + salt + "$" + key,
+		"wrong version":           "$argon2id$v=16$m=64,t=1,p=2$" + salt + "$" + key,
+		"no version":              "$argon2id$m=64,t=1,p=2$" + salt + "$" + key,
+		"missing parameter":       "$argon2id$v=19$m=64,t=1$" + salt + "$" + key,
+		"zero time":               "$argon2id$v=19$m=64,t=0,p=2$" + salt + "$" + key,
+		"memory below 8 per lane": "$argon2id$v=19$m=8,t=1,p=2$" + salt + "$" + key,
+		"missing key":             "$argon2id$v=19$m=64,t=1,p=2$" + salt,
+		"empty key":               "$argon2id$v=19$m=64,t=1,p=2$" + salt + "$",
+		"bad salt base64":         "$argon2id$v=19$m=64,t=1,p=2$not*base64$" + key,
+		"bad key base64":          "$argon2id$v=19$m=64,t=1,p=2$" + salt + "$not*base64",
+		"padded base64":           "$argon2id$v=19$m=64,t=1,p=2$" + salt + "==$" + key,
+	} {
+		hashed := hashed
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, _, err := decodeArgon2(hashed)
+			assert.ErrorIs(t, err, errInvalidArgon2Hash)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type logMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLogMailer returns a mailer for local development that writes every
+// message to the file at path instead of sending it. An empty path writes to
+// stdout.
+func NewLogMailer(path string) *logMailer {
+	return &logMailer{path: path}
+}
+
+func (m *logMailer) Send(ctx context.Context, to, subject, body string) error {
+	const ops = "pkg.mailer.logMailer.Send"
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	default:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		var w io.Writer = os.Stdout
+		if m.path != "" {
+			f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			if err != nil {
+				logger.Error(ctx, ops, "error opening mail file: %v", err)
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		_, err := fmt.Fprintf(w, "Date: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n\r\n", time.Now().Format(time.RFC1123Z), to, subject, body)
+		if err != nil {
+			logger.Error(ctx, ops, "error writing mail: %v", err)
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mhdiiilham/POS/entity/user"
+)
+
+const (
+	// FloorMinLength is the shortest password any policy accepts.
+	FloorMinLength = 8
+	// DefaultMaxLength is in bytes: bcrypt ignores everything past 72.
+	DefaultMaxLength = 72
+
+	// personalTokenMinLength keeps short names and email parts from
+	// rejecting unrelated passwords.
+	personalTokenMinLength = 3
+)
+
+const (
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleUppercase    = "uppercase"
+	RuleLowercase    = "lowercase"
+	RuleDigit        = "digit"
+	RuleSymbol       = "symbol"
+	RulePersonalInfo = "personal_info"
+	RuleCommon       = "common"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// common holds the bundled list of passwords seen most often in breaches,
+// lower-cased.
+var common = func() map[string]struct{} {
+	set := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswordList))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// Policy decides which passwords users may choose. A MinLength below
+// FloorMinLength is raised to it and a zero MaxLength is DefaultMaxLength.
+type Policy struct {
+	MinLength          int
+	MaxLength          int
+	RequireUppercase   bool
+	RequireLowercase   bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	RejectPersonalInfo bool
+	RejectCommon       bool
+}
+
+// Check returns a *user.PasswordPolicyError listing every rule password
+// fails, or nil. personal holds the user's email and names, which the
+// password may not contain when RejectPersonalInfo is set.
+func (p Policy) Check(password string, personal ...string) error {
+	var violations []user.PasswordViolation
+	violate := func(rule, format string, args ...interface{}) {
+		violations = append(violations, user.PasswordViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	minLength, maxLength := p.MinLength, p.MaxLength
+	if minLength < FloorMinLength {
+		minLength = FloorMinLength
+	}
+	if maxLength == 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	if utf8.RuneCountInString(password) < minLength {
+		violate(RuleMinLength, "password must be at least %d characters long", minLength)
+	}
+	if len(password) > maxLength {
+		violate(RuleMaxLength, "password must be at most %d bytes long", maxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	if p.RequireUppercase && !upper {
+		violate(RuleUppercase, "password must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		violate(RuleLowercase, "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violate(RuleDigit, "password must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violate(RuleSymbol, "password must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if p.RejectPersonalInfo {
+		for _, token := range personalTokens(personal) {
+			if strings.Contains(lowered, token) {
+				violate(RulePersonalInfo, "password must not contain your email or name")
+				break
+			}
+		}
+	}
+
+	if p.RejectCommon {
+		if _, ok := common[lowered]; ok {
+			violate(RuleCommon, "password is too common")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &user.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// personalTokens splits emails and names into the lower-cased words a
+// password may not contain: the whole local part of an email and each of its
+// words, and every word of a name.
+func personalTokens(personal []string) []string {
+	var tokens []string
+	add := func(token string) {
+		if utf8.RuneCountInString(token) >= personalTokenMinLength {
+			tokens = append(tokens, token)
+		}
+	}
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			value = value[:at]
+			add(value)
+		}
+
+		for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			add(word)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,105 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/user"
+)
+
+// sweepInterval is how often Take drops expired entries, so keys that
+// are never seen again do not pile up.
+const sweepInterval = time.Minute
+
+type entry struct {
+	attempts  user.LoginAttempts
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an attempt store that lives in this process.
+// Counts are lost on restart and not shared between instances.
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string]entry)}
+}
+
+// Take counts an attempt at time at, unless key is locked out after free
+// attempts, in which case it returns a *user.LockoutError and counts nothing.
+// The check and the count happen under one lock. The key is kept for ttl
+// after the last attempt.
+func (m *memoryStore) Take(ctx context.Context, key string, free int, at time.Time, ttl time.Duration) (user.LoginAttempts, error) {
+	if err := ctx.Err(); err != nil {
+		return user.LoginAttempts{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, e := range m.entries {
+			if !now.Before(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	e, ok := m.entries[key]
+	if !ok || !now.Before(e.expiresAt) {
+		e = entry{}
+	}
+
+	if until := e.attempts.LockedUntil(free); at.Before(until) {
+		return e.attempts, &user.LockoutError{Until: until}
+	}
+
+	e.attempts.Failures++
+	e.attempts.LastFailure = at
+	e.expiresAt = at.Add(ttl)
+	m.entries[key] = e
+	return e.attempts, nil
+}
+
+// Release takes back one attempt Take counted, for an attempt that turned
+// out fine.
+func (m *memoryStore) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok || !time.Now().Before(e.expiresAt) {
+		return nil
+	}
+
+	if e.attempts.Failures <= 1 {
+		delete(m.entries, key)
+		return nil
+	}
+
+	e.attempts.Failures--
+	m.entries[key] = e
+	return nil
+}
+
+func (m *memoryStore) Reset(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
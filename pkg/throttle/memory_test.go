@@ -0,0 +1,147 @@
+package throttle_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/throttle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const free = 3
+
+func TestMemoryStore_Take(t *testing.T) {
+	t.Run("locks out once the free attempts are used", func(t *testing.T) {
+		ctx := context.Background()
+		store := throttle.NewMemoryStore()
+		at := time.Now()
+
+		for i := 1; i <= free; i++ {
+			attempts, err := store.Take(ctx, "account:a", free, at, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, i, attempts.Failures)
+		}
+
+		attempts, err := store.Take(ctx, "account:a", free, at, time.Hour)
+		var lockout *user.LockoutError
+		require.ErrorAs(t, err, &lockout)
+		assert.Equal(t, at.Add(user.LoginBackoffBase), lockout.Until)
+		assert.Equal(t, free, attempts.Failures, "a locked out attempt is not counted")
+
+		_, err = store.Take(ctx, "account:b", free, at, time.Hour)
+		assert.NoError(t, err, "other keys are not affected")
+	})
+
+	t.Run("backoff doubles after it is over", func(t *testing.T) {
+		ctx := context.Background()
+		store := throttle.NewMemoryStore()
+		at := time.Now()
+
+		for i := 0; i < free; i++ {
+			_, err := store.Take(ctx, "ip:10.0.0.1", free, at, time.Hour)
+			require.NoError(t, err)
+		}
+
+		at = at.Add(user.LoginBackoffBase)
+		_, err := store.Take(ctx, "ip:10.0.0.1", free, at, time.Hour)
+		require.NoError(t, err)
+
+		_, err = store.Take(ctx, "ip:10.0.0.1", free, at.Add(user.LoginBackoffBase), time.Hour)
+		var lockout *user.LockoutError
+		require.ErrorAs(t, err, &lockout)
+		assert.Equal(t, at.Add(2*user.LoginBackoffBase), lockout.Until)
+	})
+
+	t.Run("attempts are forgotten after the window", func(t *testing.T) {
+		ctx := context.Background()
+		store := throttle.NewMemoryStore()
+		at := time.Now().Add(-2 * time.Hour)
+
+		for i := 0; i < free; i++ {
+			_, err := store.Take(ctx, "account:a", free, at, time.Hour)
+			require.NoError(t, err)
+		}
+
+		attempts, err := store.Take(ctx, "account:a", free, time.Now(), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures)
+	})
+
+	t.Run("concurrent attempts cannot pass the check together", func(t *testing.T) {
+		ctx := context.Background()
+		store := throttle.NewMemoryStore()
+		at := time.Now()
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.Take(ctx, "account:a", free, at, time.Hour); err == nil {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, free, allowed)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := throttle.NewMemoryStore().Take(ctx, "account:a", free, time.Now(), time.Hour)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMemoryStore_Release(t *testing.T) {
+	ctx := context.Background()
+	store := throttle.NewMemoryStore()
+	at := time.Now()
+
+	for i := 0; i < free; i++ {
+		_, err := store.Take(ctx, "account:a", free, at, time.Hour)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, store.Release(ctx, "account:a"))
+	attempts, err := store.Take(ctx, "account:a", free, at, time.Hour)
+	require.NoError(t, err, "a released attempt frees a slot")
+	assert.Equal(t, free, attempts.Failures)
+
+	for i := 0; i < free; i++ {
+		require.NoError(t, store.Release(ctx, "account:a"))
+	}
+	require.NoError(t, store.Release(ctx, "account:a"), "releasing an unknown key is a no-op")
+
+	attempts, err = store.Take(ctx, "account:a", free, at, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+}
+
+func TestMemoryStore_Reset(t *testing.T) {
+	ctx := context.Background()
+	store := throttle.NewMemoryStore()
+	at := time.Now()
+
+	for i := 0; i < free; i++ {
+		_, err := store.Take(ctx, "account:a", free, at, time.Hour)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, store.Reset(ctx, "account:a"))
+
+	attempts, err := store.Take(ctx, "account:a", free, at, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+}
@@ -3,30 +3,123 @@ package token
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
 	"github.com/mhdiiilham/POS/pkg/logger"
 )
 
 type Claims struct {
 	jwt.StandardClaims
-	UserID     int    `json:"userID"`
-	Email      string `json:"email"`
-	MerchantID int    `json:"merchantID"`
+	UserID      int               `json:"userID"`
+	Email       string            `json:"email"`
+	MerchantID  int               `json:"merchantID"`
+	OutletID    int               `json:"outletID,omitempty"`
+	SessionID   string            `json:"sid"`
+	Role        string            `json:"role"`
+	Permissions []role.Permission `json:"perms"`
 }
 
 type service struct {
 	secret        string
 	issuer        string
-	signingMethod *jwt.SigningMethodHMAC
+	signingMethod jwt.SigningMethod
+	keys          []Key
 }
 
+// NewJWTService signs with HS256 and a secret shared with every verifier.
 func NewJWTService(secret, issuer string) *service {
 	return &service{secret: secret, issuer: issuer, signingMethod: jwt.SigningMethodHS256}
 }
 
-func (s *service) Sign(ctx context.Context, userID int, email string, merchantID int) (at string, err error) {
+// NewKeySetJWTService signs with RS256 or EdDSA keys. Tokens name their key
+// in the kid header, and every key in the set verifies, so tokens signed
+// before a rotation stay valid until they expire. New tokens are signed with
+// the private key whose ActiveFrom passed most recently; adding a key with a
+// future ActiveFrom publishes it ahead of the switch.
+func NewKeySetJWTService(issuer, algorithm string, keys []Key) (*service, error) {
+	var signingMethod jwt.SigningMethod
+	switch algorithm {
+	case AlgorithmRS256:
+		signingMethod = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		signingMethod = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	ids := make(map[string]bool, len(keys))
+	canSign := false
+	for _, key := range keys {
+		if ids[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		ids[key.ID] = true
+
+		if !key.fits(algorithm) {
+			return nil, fmt.Errorf("key %s cannot be used with %s", key.ID, algorithm)
+		}
+		canSign = canSign || key.private != nil
+	}
+
+	if !canSign {
+		return nil, errors.New("no private key to sign with")
+	}
+
+	return &service{issuer: issuer, signingMethod: signingMethod, keys: keys}, nil
+}
+
+// JWKS lists the public keys that verify our tokens. It is empty for HS256,
+// whose secret must never be published.
+func (s *service) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(s.keys))}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, key.jwk(s.signingMethod.Alg()))
+	}
+	return set
+}
+
+func (s *service) signingKey(now time.Time) (Key, error) {
+	var current Key
+	found := false
+	for _, key := range s.keys {
+		if key.private == nil || key.ActiveFrom.After(now) {
+			continue
+		}
+
+		if !found || key.ActiveFrom.After(current.ActiveFrom) {
+			current, found = key, true
+		}
+	}
+
+	if !found {
+		return Key{}, errors.New("no signing key is active yet")
+	}
+	return current, nil
+}
+
+func (s *service) verificationKey(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() != s.signingMethod.Alg() {
+		return nil, errors.New("signing method invalid")
+	}
+
+	if len(s.keys) == 0 {
+		return []byte(s.secret), nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key.public, nil
+		}
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+func (s *service) Sign(ctx context.Context, identity session.Identity) (at string, err error) {
 	const ops = "token.service.Sign"
 	now := time.Now()
 
@@ -37,19 +130,34 @@ func (s *service) Sign(ctx context.Context, userID int, email string, merchantID
 	default:
 		claims := Claims{
 			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: now.Add(12 * time.Hour).Unix(),
+				ExpiresAt: now.Add(session.AccessTokenTTL).Unix(),
 				IssuedAt:  now.Unix(),
 				Issuer:    s.issuer,
 				NotBefore: now.Unix(),
 				Subject:   "access-token",
 			},
-			UserID:     userID,
-			MerchantID: merchantID,
-			Email:      email,
+			UserID:      identity.UserID,
+			MerchantID:  identity.MerchantID,
+			OutletID:    identity.OutletID,
+			Email:       identity.Email,
+			SessionID:   identity.SessionID,
+			Role:        identity.Role,
+			Permissions: identity.Permissions,
 		}
 
 		jwtToken := jwt.NewWithClaims(s.signingMethod, claims)
-		signedToken, err := jwtToken.SignedString([]byte(s.secret))
+		var signingKey interface{} = []byte(s.secret)
+		if len(s.keys) > 0 {
+			key, err := s.signingKey(now)
+			if err != nil {
+				logger.Error(ctx, ops, "error picking signing key: %v", err)
+				return "", err
+			}
+			jwtToken.Header["kid"] = key.ID
+			signingKey = key.private
+		}
+
+		signedToken, err := jwtToken.SignedString(signingKey)
 		if err != nil {
 			logger.Error(ctx, ops, "error trying to get JWT signed string: %v", err)
 			return "", err
@@ -66,14 +174,7 @@ func (s *service) Extract(ctx context.Context, signedToken string) (jwt.MapClaim
 	case <-ctx.Done():
 		return jwt.MapClaims{}, ctx.Err()
 	default:
-		token, err := jwt.Parse(signedToken, func(t *jwt.Token) (interface{}, error) {
-			if method, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("signing method invalid")
-			} else if method != s.signingMethod {
-				return nil, errors.New("signing method invalid")
-			}
-			return []byte(s.secret), nil
-		})
+		token, err := jwt.Parse(signedToken, s.verificationKey)
 		if err != nil {
 			logger.Error(ctx, ops, "failed to parse signed token: %v", err)
 			return nil, err
@@ -0,0 +1,353 @@
+package token_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var identity = session.Identity{UserID: 7, MerchantID: 1, Email: "owner@shop.id", SessionID: "sid", Role: "owner"}
+
+// writePEM writes der as a PEM block of type typ and returns the path.
+func writePEM(t *testing.T, name, typ string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+	return path
+}
+
+func rsaKeyFiles(t *testing.T, bits int) (private, public string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return writePEM(t, "private.pem", "PRIVATE KEY", privateDER), writePEM(t, "public.pem", "PUBLIC KEY", publicDER)
+}
+
+func ed25519KeyFiles(t *testing.T) (private, public string) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	return writePEM(t, "private.pem", "PRIVATE KEY", privateDER), writePEM(t, "public.pem", "PUBLIC KEY", publicDER)
+}
+
+func loadKey(t *testing.T, id, private, public, activeFrom string) token.Key {
+	t.Helper()
+
+	key, err := token.LoadKey(id, private, public, activeFrom)
+	require.NoError(t, err)
+	return key
+}
+
+// kid returns the key id in the header of a signed token.
+func kid(t *testing.T, signed string) string {
+	t.Helper()
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(signed, jwt.MapClaims{})
+	require.NoError(t, err)
+	id, _ := parsed.Header["kid"].(string)
+	return id
+}
+
+func TestLoadKey(t *testing.T) {
+	rsaPrivate, rsaPublic := rsaKeyFiles(t, 2048)
+	edPrivate, edPublic := ed25519KeyFiles(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs1Private := writePEM(t, "pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	pkcs1Public := writePEM(t, "pkcs1.pub", "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))
+
+	notPEM := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0o600))
+
+	testCases := []struct {
+		name       string
+		id         string
+		private    string
+		public     string
+		activeFrom string
+		wantErr    bool
+	}{
+		{name: "RSA private key", id: "k1", private: rsaPrivate},
+		{name: "RSA public key only", id: "k1", public: rsaPublic},
+		{name: "PKCS #1 RSA private key", id: "k1", private: pkcs1Private},
+		{name: "PKCS #1 RSA public key", id: "k1", public: pkcs1Public},
+		{name: "Ed25519 key pair", id: "k1", private: edPrivate, public: edPublic},
+		{name: "active from", id: "k1", private: edPrivate, activeFrom: "2026-01-02T03:04:05Z"},
+		{name: "missing id", private: rsaPrivate, wantErr: true},
+		{name: "no key files", id: "k1", wantErr: true},
+		{name: "invalid active from", id: "k1", private: rsaPrivate, activeFrom: "tomorrow", wantErr: true},
+		{name: "missing file", id: "k1", private: filepath.Join(t.TempDir(), "nope.pem"), wantErr: true},
+		{name: "not PEM", id: "k1", private: notPEM, wantErr: true},
+		{name: "public key as private key", id: "k1", private: rsaPublic, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := token.LoadKey(tc.id, tc.private, tc.public, tc.activeFrom)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.id, key.ID)
+			if tc.activeFrom != "" {
+				assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), key.ActiveFrom.UTC())
+			}
+		})
+	}
+}
+
+func TestNewKeySetJWTService(t *testing.T) {
+	rsaPrivate, rsaPublic := rsaKeyFiles(t, 2048)
+	smallPrivate, _ := rsaKeyFiles(t, 1024)
+	edPrivate, _ := ed25519KeyFiles(t)
+
+	rsaKey := loadKey(t, "rsa", rsaPrivate, "", "")
+	edKey := loadKey(t, "ed", edPrivate, "", "")
+
+	testCases := []struct {
+		name      string
+		algorithm string
+		keys      []token.Key
+		wantErr   bool
+	}{
+		{name: "RS256", algorithm: token.AlgorithmRS256, keys: []token.Key{rsaKey}},
+		{name: "EdDSA", algorithm: token.AlgorithmEdDSA, keys: []token.Key{edKey}},
+		{name: "HS256 is not a key set", algorithm: token.AlgorithmHS256, keys: []token.Key{rsaKey}, wantErr: true},
+		{name: "key of another algorithm", algorithm: token.AlgorithmEdDSA, keys: []token.Key{edKey, rsaKey}, wantErr: true},
+		{name: "RSA key below 2048 bits", algorithm: token.AlgorithmRS256, keys: []token.Key{loadKey(t, "small", smallPrivate, "", "")}, wantErr: true},
+		{name: "duplicate key id", algorithm: token.AlgorithmRS256, keys: []token.Key{rsaKey, loadKey(t, "rsa", "", rsaPublic, "")}, wantErr: true},
+		{name: "public keys only", algorithm: token.AlgorithmRS256, keys: []token.Key{loadKey(t, "rsa", "", rsaPublic, "")}, wantErr: true},
+		{name: "no keys", algorithm: token.AlgorithmRS256, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := token.NewKeySetJWTService("POS", tc.algorithm, tc.keys)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_SignAndExtract(t *testing.T) {
+	ctx := context.Background()
+	rsaPrivate, _ := rsaKeyFiles(t, 2048)
+	edPrivate, _ := ed25519KeyFiles(t)
+
+	testCases := []struct {
+		name      string
+		algorithm string
+		private   string
+	}{
+		{name: "RS256", algorithm: token.AlgorithmRS256, private: rsaPrivate},
+		{name: "EdDSA", algorithm: token.AlgorithmEdDSA, private: edPrivate},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := token.NewKeySetJWTService("POS", tc.algorithm, []token.Key{loadKey(t, "k1", tc.private, "", "")})
+			require.NoError(t, err)
+
+			signed, err := s.Sign(ctx, identity)
+			require.NoError(t, err)
+			assert.Equal(t, "k1", kid(t, signed))
+
+			claims, err := s.Extract(ctx, signed)
+			require.NoError(t, err)
+			assert.Equal(t, float64(identity.UserID), claims["userID"])
+			assert.Equal(t, identity.SessionID, claims["sid"])
+			assert.Equal(t, "POS", claims["iss"])
+		})
+	}
+
+	t.Run("HS256", func(t *testing.T) {
+		s := token.NewJWTService("secret", "POS")
+
+		signed, err := s.Sign(ctx, identity)
+		require.NoError(t, err)
+		assert.Empty(t, kid(t, signed))
+
+		claims, err := s.Extract(ctx, signed)
+		require.NoError(t, err)
+		assert.Equal(t, identity.Email, claims["email"])
+	})
+
+	t.Run("unknown kid is rejected", func(t *testing.T) {
+		signer, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{loadKey(t, "other", edPrivate, "", "")})
+		require.NoError(t, err)
+		verifier, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{loadKey(t, "k1", edPrivate, "", "")})
+		require.NoError(t, err)
+
+		signed, err := signer.Sign(ctx, identity)
+		require.NoError(t, err)
+
+		_, err = verifier.Extract(ctx, signed)
+		assert.Error(t, err)
+	})
+
+	t.Run("other algorithm is rejected", func(t *testing.T) {
+		hs := token.NewJWTService("secret", "POS")
+		rs, err := token.NewKeySetJWTService("POS", token.AlgorithmRS256, []token.Key{loadKey(t, "k1", rsaPrivate, "", "")})
+		require.NoError(t, err)
+
+		signed, err := hs.Sign(ctx, identity)
+		require.NoError(t, err)
+
+		_, err = rs.Extract(ctx, signed)
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered token is rejected", func(t *testing.T) {
+		s, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{loadKey(t, "k1", edPrivate, "", "")})
+		require.NoError(t, err)
+
+		signed, err := s.Sign(ctx, identity)
+		require.NoError(t, err)
+
+		_, err = s.Extract(ctx, signed[:len(signed)-4]+"AAAA")
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Rotation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	oldPrivate, oldPublic := ed25519KeyFiles(t)
+	newPrivate, _ := ed25519KeyFiles(t)
+	nextPrivate, _ := ed25519KeyFiles(t)
+
+	old := loadKey(t, "old", oldPrivate, "", now.Add(-48*time.Hour).Format(time.RFC3339))
+	current := loadKey(t, "new", newPrivate, "", now.Add(-time.Hour).Format(time.RFC3339))
+	next := loadKey(t, "next", nextPrivate, "", now.Add(time.Hour).Format(time.RFC3339))
+
+	t.Run("signs with the key whose ActiveFrom passed most recently", func(t *testing.T) {
+		s, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{next, old, current})
+		require.NoError(t, err)
+
+		signed, err := s.Sign(ctx, identity)
+		require.NoError(t, err)
+		assert.Equal(t, "new", kid(t, signed))
+	})
+
+	t.Run("no key active yet", func(t *testing.T) {
+		s, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{next})
+		require.NoError(t, err)
+
+		_, err = s.Sign(ctx, identity)
+		assert.Error(t, err)
+	})
+
+	t.Run("tokens signed with the previous key still verify", func(t *testing.T) {
+		before, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{old})
+		require.NoError(t, err)
+
+		signed, err := before.Sign(ctx, identity)
+		require.NoError(t, err)
+		assert.Equal(t, "old", kid(t, signed))
+
+		retired := loadKey(t, "old", "", oldPublic, "")
+		after, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{retired, current})
+		require.NoError(t, err)
+
+		claims, err := after.Extract(ctx, signed)
+		require.NoError(t, err)
+		assert.Equal(t, float64(identity.UserID), claims["userID"])
+
+		signed, err = after.Sign(ctx, identity)
+		require.NoError(t, err)
+		assert.Equal(t, "new", kid(t, signed))
+	})
+}
+
+func TestService_JWKS(t *testing.T) {
+	rsaPrivate, _ := rsaKeyFiles(t, 2048)
+	edPrivate, edPublic := ed25519KeyFiles(t)
+
+	t.Run("RS256", func(t *testing.T) {
+		key := loadKey(t, "rsa", rsaPrivate, "", "")
+		s, err := token.NewKeySetJWTService("POS", token.AlgorithmRS256, []token.Key{key})
+		require.NoError(t, err)
+
+		set := s.JWKS()
+		require.Len(t, set.Keys, 1)
+		jwk := set.Keys[0]
+		assert.Equal(t, "RSA", jwk.KeyType)
+		assert.Equal(t, "rsa", jwk.KeyID)
+		assert.Equal(t, "sig", jwk.Use)
+		assert.Equal(t, "RS256", jwk.Algorithm)
+		assert.Equal(t, "AQAB", jwk.E)
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		require.NoError(t, err)
+		assert.Len(t, n, 256)
+		assert.Empty(t, jwk.X)
+	})
+
+	t.Run("EdDSA lists retired keys too", func(t *testing.T) {
+		retired := loadKey(t, "old", "", edPublic, "")
+		current := loadKey(t, "new", edPrivate, "", "")
+		s, err := token.NewKeySetJWTService("POS", token.AlgorithmEdDSA, []token.Key{retired, current})
+		require.NoError(t, err)
+
+		set := s.JWKS()
+		require.Len(t, set.Keys, 2)
+		assert.Equal(t, "old", set.Keys[0].KeyID)
+		assert.Equal(t, "new", set.Keys[1].KeyID)
+		for _, jwk := range set.Keys {
+			assert.Equal(t, "OKP", jwk.KeyType)
+			assert.Equal(t, "Ed25519", jwk.Curve)
+			assert.Equal(t, "EdDSA", jwk.Algorithm)
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			require.NoError(t, err)
+			assert.Len(t, x, ed25519.PublicKeySize)
+			assert.Empty(t, jwk.N)
+		}
+	})
+
+	t.Run("HS256 publishes nothing", func(t *testing.T) {
+		assert.Empty(t, token.NewJWTService("secret", "POS").JWKS().Keys)
+	})
+}
@@ -0,0 +1,143 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is one key of an asymmetric key set. A key without a private half only
+// verifies, for tokens signed before its private key was retired.
+type Key struct {
+	ID         string
+	ActiveFrom time.Time
+	private    crypto.PrivateKey
+	public     crypto.PublicKey
+}
+
+// LoadKey reads a PEM key pair. Either file may be empty: the public key is
+// derived from the private key when there is one. activeFrom is RFC 3339;
+// an empty one makes the key active immediately.
+func LoadKey(id, privateKeyFile, publicKeyFile, activeFrom string) (Key, error) {
+	key := Key{ID: id}
+	if id == "" {
+		return Key{}, errors.New("key id is required")
+	}
+
+	if activeFrom != "" {
+		t, err := time.Parse(time.RFC3339, activeFrom)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s: invalid activeFrom: %w", id, err)
+		}
+		key.ActiveFrom = t
+	}
+
+	if privateKeyFile != "" {
+		block, err := readPEM(privateKeyFile)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s: %w", id, err)
+		}
+
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				return Key{}, fmt.Errorf("key %s: unsupported private key: %w", id, err)
+			}
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return Key{}, fmt.Errorf("key %s: unsupported private key type %T", id, private)
+		}
+		key.private, key.public = private, signer.Public()
+	}
+
+	if publicKeyFile != "" && key.public == nil {
+		block, err := readPEM(publicKeyFile)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s: %w", id, err)
+		}
+
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			if public, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+				return Key{}, fmt.Errorf("key %s: unsupported public key: %w", id, err)
+			}
+		}
+		key.public = public
+	}
+
+	if key.public == nil {
+		return Key{}, fmt.Errorf("key %s: a private or public key file is required", id)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+	return block, nil
+}
+
+// fits reports whether the key can be used with algorithm.
+func (k Key) fits(algorithm string) bool {
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		return algorithm == AlgorithmRS256 && public.N.BitLen() >= 2048
+	case ed25519.PublicKey:
+		return algorithm == AlgorithmEdDSA
+	}
+	return false
+}
+
+// JSONWebKey is the RFC 7517 form of a public key.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func (k Key) jwk(algorithm string) JSONWebKey {
+	jwk := JSONWebKey{KeyID: k.ID, Use: "sig", Algorithm: algorithm}
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
@@ -0,0 +1,110 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mhdiiilham/POS/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890".
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// The RFC lists 8-digit codes; ours are their last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCode(t *testing.T) {
+	for _, tc := range rfcVectors {
+		tc := tc
+		t.Run(tc.code, func(t *testing.T) {
+			t.Parallel()
+
+			code, err := totp.Code(rfcSecret, totp.Step(time.Unix(tc.unix, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.code, code)
+		})
+	}
+
+	t.Run("lower case secret", func(t *testing.T) {
+		code, err := totp.Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", totp.Step(time.Unix(59, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, "287082", code)
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, err := totp.Code("not base32!", 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	step := totp.Step(at)
+
+	testCases := []struct {
+		name string
+		code string
+		at   time.Time
+		step int64
+		ok   bool
+	}{
+		{name: "current period", code: "050471", at: at, step: step, ok: true},
+		{name: "one period late", code: "050471", at: at.Add(totp.Period), step: step, ok: true},
+		{name: "one period early", code: "050471", at: at.Add(-totp.Period), step: step, ok: true},
+		{name: "two periods late", code: "050471", at: at.Add(2 * totp.Period)},
+		{name: "wrong code", code: "123456", at: at},
+		{name: "8-digit RFC code", code: "14050471", at: at},
+		{name: "empty", code: "", at: at},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			step, ok := totp.Validate(rfcSecret, tc.code, tc.at)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.step, step)
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := totp.NewSecret()
+	require.NoError(t, err)
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	assert.Len(t, code, totp.Digits)
+
+	other, err := totp.NewSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(totp.URI("POS", "owner@shop.id", rfcSecret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/POS:owner@shop.id", u.Path)
+	assert.Equal(t, rfcSecret, u.Query().Get("secret"))
+	assert.Equal(t, "POS", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
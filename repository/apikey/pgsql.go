@@ -0,0 +1,158 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/entity/apikey"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, entity apikey.Key) (id int64, err error) {
+	const ops = "repository.apikey.Create"
+
+	permissions := make([]string, 0, len(entity.Permissions))
+	for _, p := range entity.Permissions {
+		permissions = append(permissions, string(p))
+	}
+
+	err = r.db.QueryRowContext(
+		ctx,
+		insertKey,
+		entity.MerchantID,
+		entity.CreatedBy,
+		entity.Name,
+		entity.Prefix,
+		entity.SecretHash,
+		pq.Array(permissions),
+		entity.CreatedAt,
+		entity.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Get(ctx context.Context, merchantID int) (keys []apikey.Key, err error) {
+	const ops = "repository.apikey.Get"
+
+	rows, err := r.db.QueryContext(ctx, getKeysByMerchantID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity apikey.Key
+		if entity, err = scanKey(rows); err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		keys = append(keys, entity)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetByPrefix(ctx context.Context, prefix string) (entity apikey.Key, err error) {
+	const ops = "repository.apikey.GetByPrefix"
+
+	entity, err = scanKey(r.db.QueryRowContext(ctx, getKeyByPrefix, prefix))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = apikey.ErrAPIKeyNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+// Revoke is idempotent: revoking a revoked key keeps its first revocation
+// time.
+func (r *repository) Revoke(ctx context.Context, merchantID, keyID int) (err error) {
+	const ops = "repository.apikey.Revoke"
+
+	result, err := r.db.ExecContext(ctx, revokeKey, time.Now(), keyID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(ctx, ops, "error result.RowsAffected: %v", err)
+		return
+	}
+
+	if affected == 0 {
+		return apikey.ErrAPIKeyNotFound
+	}
+
+	return
+}
+
+func (r *repository) MarkUsed(ctx context.Context, keyID int, at time.Time) (err error) {
+	const ops = "repository.apikey.MarkUsed"
+
+	_, err = r.db.ExecContext(ctx, markKeyUsed, at, keyID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKey(row scanner) (entity apikey.Key, err error) {
+	var permissions []string
+
+	err = row.Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.CreatedBy,
+		&entity.Name,
+		&entity.Prefix,
+		&entity.SecretHash,
+		pq.Array(&permissions),
+		&entity.CreatedAt,
+		&entity.ExpiresAt,
+		&entity.LastUsedAt,
+		&entity.RevokedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	entity.Permissions = make([]role.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		entity.Permissions = append(entity.Permissions, role.Permission(p))
+	}
+	return
+}
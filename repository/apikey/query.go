@@ -0,0 +1,55 @@
+package apikey
+
+var (
+	insertKey = `
+		INSERT INTO public."APIKey" (merchant_id, created_by, "name", prefix, secret_hash, permissions, created_at, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;
+	`
+
+	getKeysByMerchantID = `
+		SELECT
+			id,
+			merchant_id,
+			created_by,
+			"name",
+			prefix,
+			secret_hash,
+			permissions,
+			created_at,
+			expires_at,
+			last_used_at,
+			revoked_at
+		FROM "APIKey"
+		WHERE "merchant_id" = $1
+		ORDER BY id
+	`
+
+	getKeyByPrefix = `
+		SELECT
+			id,
+			merchant_id,
+			created_by,
+			"name",
+			prefix,
+			secret_hash,
+			permissions,
+			created_at,
+			expires_at,
+			last_used_at,
+			revoked_at
+		FROM "APIKey"
+		WHERE "prefix" = $1 LIMIT 1
+	`
+
+	revokeKey = `
+		UPDATE "APIKey"
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE id = $2 AND "merchant_id" = $3;
+	`
+
+	markKeyUsed = `
+		UPDATE "APIKey"
+		SET last_used_at = $1
+		WHERE id = $2;
+	`
+)
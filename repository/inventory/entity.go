@@ -0,0 +1 @@
+package inventory
@@ -0,0 +1,215 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+const uniqueViolation pq.ErrorCode = "23505"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Assign(ctx context.Context, entity inventory.OutletProduct, userID int) (err error) {
+	const ops = "repository.inventory.Assign"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin transaction %v", err)
+		return
+	}
+
+	logger.Info(ctx, ops, "assigning product %d to outlet %d", entity.ProductID, entity.OutletID)
+	_, err = tx.ExecContext(ctx, insertOutletProduct, entity.OutletID, entity.ProductID, entity.Price, entity.Stock)
+	if err != nil {
+		tx.Rollback()
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return inventory.ErrProductAlreadyAssigned
+		}
+
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	if entity.Stock > 0 {
+		_, err = tx.ExecContext(
+			ctx,
+			insertStockMovement,
+			entity.OutletID,
+			entity.ProductID,
+			userID,
+			entity.Stock,
+			entity.Stock,
+			inventory.ReasonInitial,
+			nil,
+			time.Now(),
+		)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error trying to record initial stock: %v", err)
+			return
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) Get(ctx context.Context, outletID int, opts *inventory.RepositoryGetOutletProductPaginationOptions) (products []inventory.OutletProduct, totalData int, err error) {
+	const ops = "repository.inventory.Get"
+	var cursor int
+	var limit interface{}
+
+	if opts != nil {
+		cursor = opts.Cursor
+		limit = opts.Limit
+	}
+
+	err = r.db.QueryRowContext(ctx, countAllOutletProducts, outletID).Scan(&totalData)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getOutletProducts, outletID, cursor, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p inventory.OutletProduct
+		err = rows.Scan(&p.OutletID, &p.ProductID, &p.SKU, &p.Name, &p.Price, &p.Stock)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		products = append(products, p)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetOutletProduct(ctx context.Context, outletID, productID int) (entity inventory.OutletProduct, err error) {
+	const ops = "repository.inventory.GetOutletProduct"
+
+	err = r.db.QueryRowContext(ctx, getOutletProduct, outletID, productID).Scan(
+		&entity.OutletID,
+		&entity.ProductID,
+		&entity.SKU,
+		&entity.Name,
+		&entity.Price,
+		&entity.Stock,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = inventory.ErrOutletProductNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) UpdatePrice(ctx context.Context, outletID, productID int, price float64) (err error) {
+	const ops = "repository.inventory.UpdatePrice"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, updateOutletProductPrice, price, outletID, productID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to update price: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return inventory.ErrOutletProductNotFound
+	}
+
+	return nil
+}
+
+// AdjustStock applies movement.Quantity to the outlet product stock. The row
+// is locked with SELECT ... FOR UPDATE so concurrent adjustments serialize and
+// the stock never drops below zero.
+func (r *repository) AdjustStock(ctx context.Context, movement inventory.StockMovement) (stock int, err error) {
+	const ops = "repository.inventory.AdjustStock"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin transaction %v", err)
+		return
+	}
+
+	err = tx.QueryRowContext(ctx, lockOutletProductStock, movement.OutletID, movement.ProductID).Scan(&stock)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, inventory.ErrOutletProductNotFound
+		}
+
+		logger.Error(ctx, ops, "error trying to lock outlet product: %v", err)
+		return 0, err
+	}
+
+	stock += movement.Quantity
+	if stock < 0 {
+		tx.Rollback()
+		return 0, inventory.ErrInsufficientStock
+	}
+
+	_, err = tx.ExecContext(ctx, updateOutletProductStock, stock, movement.OutletID, movement.ProductID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to update stock: %v", err)
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		insertStockMovement,
+		movement.OutletID,
+		movement.ProductID,
+		movement.UserID,
+		movement.Quantity,
+		stock,
+		movement.Reason,
+		movement.Note,
+		time.Now(),
+	)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to record stock movement: %v", err)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error trying to commit transaction: %v", err)
+		return 0, err
+	}
+
+	return stock, nil
+}
@@ -0,0 +1,66 @@
+package inventory
+
+var (
+	insertOutletProduct = `
+		INSERT INTO public."OutletProduct" (outlet_id, product_id, price, stock)
+		VALUES($1, $2, $3, $4);
+	`
+
+	getOutletProducts = `
+		SELECT
+			op.outlet_id,
+			op.product_id,
+			p.sku,
+			p."name",
+			op.price,
+			op.stock
+		FROM "OutletProduct" op
+		JOIN "Product" p ON p.id = op.product_id AND p."deleted_at" IS NULL
+		WHERE op.outlet_id = $1 AND op.product_id > $2
+		ORDER BY op.product_id
+		LIMIT $3
+	`
+
+	countAllOutletProducts = `
+		SELECT COUNT(op.product_id) as "totalProducts"
+		FROM "OutletProduct" op
+		JOIN "Product" p ON p.id = op.product_id AND p."deleted_at" IS NULL
+		WHERE op.outlet_id = $1
+	`
+
+	getOutletProduct = `
+		SELECT
+			op.outlet_id,
+			op.product_id,
+			p.sku,
+			p."name",
+			op.price,
+			op.stock
+		FROM "OutletProduct" op
+		JOIN "Product" p ON p.id = op.product_id AND p."deleted_at" IS NULL
+		WHERE op.outlet_id = $1 AND op.product_id = $2 LIMIT 1
+	`
+
+	updateOutletProductPrice = `
+		UPDATE "OutletProduct"
+		SET price = $1
+		WHERE outlet_id = $2 AND product_id = $3;
+	`
+
+	lockOutletProductStock = `
+		SELECT stock FROM "OutletProduct"
+		WHERE outlet_id = $1 AND product_id = $2
+		FOR UPDATE
+	`
+
+	updateOutletProductStock = `
+		UPDATE "OutletProduct"
+		SET stock = $1
+		WHERE outlet_id = $2 AND product_id = $3;
+	`
+
+	insertStockMovement = `
+		INSERT INTO public."StockMovement" (outlet_id, product_id, user_id, quantity, stock_after, reason, note, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+)
@@ -0,0 +1,263 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/entity/invitation"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+const uniqueViolation pq.ErrorCode = "23505"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// Create adds the invitee as a pending user together with their invitation.
+func (r *repository) Create(ctx context.Context, entity invitation.Invitation, invitee user.User) (invitationID, userID int64, err error) {
+	const ops = "repository.invitation.Create"
+	var tx *sql.Tx
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	err = tx.QueryRowContext(ctx, insertPendingUser, invitee.Email, invitee.FirstName, invitee.LastName, invitee.MerchantID, invitee.RoleID, entity.CreatedAt).Scan(&userID)
+	if err != nil {
+		tx.Rollback()
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, 0, user.ErrEmailNotUnique
+		}
+
+		logger.Error(ctx, ops, "error inserting pending user: %v", err)
+		return 0, 0, err
+	}
+
+	err = tx.QueryRowContext(ctx, insertInvitation, entity.MerchantID, userID, entity.InvitedBy, entity.TokenHash, entity.CreatedAt, entity.ExpiresAt).Scan(&invitationID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error inserting invitation: %v", err)
+		return 0, 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error committing tx: %v", err)
+		return 0, 0, err
+	}
+
+	return invitationID, userID, nil
+}
+
+// Get returns the merchant's invitations that have not been accepted yet,
+// expired ones included.
+func (r *repository) Get(ctx context.Context, merchantID int) (invitations []invitation.Invitation, err error) {
+	const ops = "repository.invitation.Get"
+
+	rows, err := r.db.QueryContext(ctx, getInvitations, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error querying invitations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entity, err := scanInvitation(rows)
+		if err != nil {
+			logger.Error(ctx, ops, "error scanning invitation: %v", err)
+			return nil, err
+		}
+		invitations = append(invitations, entity)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (r *repository) GetInvitation(ctx context.Context, merchantID, invitationID int) (entity invitation.Invitation, err error) {
+	const ops = "repository.invitation.GetInvitation"
+
+	entity, err = scanInvitation(r.db.QueryRowContext(ctx, getInvitation, invitationID, merchantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invitation.Invitation{}, invitation.ErrInvitationNotFound
+		}
+
+		logger.Error(ctx, ops, "error getting invitation: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	return entity, nil
+}
+
+// Renew replaces the token of an open invitation, so the link sent before
+// stops working, and moves its expiry.
+func (r *repository) Renew(ctx context.Context, merchantID, invitationID int, tokenHash string, expiresAt time.Time) (err error) {
+	const ops = "repository.invitation.Renew"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, renewInvitation, tokenHash, expiresAt, invitationID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error renewing invitation: %v", err)
+		return err
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return invitation.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// Revoke deletes an open invitation and its pending user. The user row is
+// removed rather than soft deleted so the email can be invited again.
+func (r *repository) Revoke(ctx context.Context, merchantID, invitationID int) (err error) {
+	const ops = "repository.invitation.Revoke"
+	var tx *sql.Tx
+	var userID int
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, deleteInvitation, invitationID, merchantID).Scan(&userID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return invitation.ErrInvitationNotFound
+		}
+
+		logger.Error(ctx, ops, "error deleting invitation: %v", err)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, deletePendingUser, userID); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error deleting pending user: %v", err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error committing tx: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindUser returns the pending user an open, unexpired invite token belongs
+// to, without spending the token.
+func (r *repository) FindUser(ctx context.Context, tokenHash string) (entity user.User, err error) {
+	const ops = "repository.invitation.FindUser"
+
+	err = r.db.QueryRowContext(ctx, findInviteUser, tokenHash, time.Now()).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.Email,
+		&entity.FirstName,
+		&entity.LastName,
+		&entity.Pending,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, invitation.ErrInvalidInviteToken
+		}
+
+		logger.Error(ctx, ops, "error finding invite user: %v", err)
+		return user.User{}, err
+	}
+
+	return entity, nil
+}
+
+// Accept spends the token and activates its user with the password in one
+// transaction, so a token can never be used twice.
+func (r *repository) Accept(ctx context.Context, tokenHash, hashedPassword string) (userID int, err error) {
+	const ops = "repository.invitation.Accept"
+	var tx *sql.Tx
+	var res sql.Result
+	var rowsAffected int64
+	now := time.Now()
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	err = tx.QueryRowContext(ctx, acceptInvitation, now, tokenHash).Scan(&userID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, invitation.ErrInvalidInviteToken
+		}
+
+		logger.Error(ctx, ops, "error accepting invitation: %v", err)
+		return 0, err
+	}
+
+	res, err = tx.ExecContext(ctx, activateUser, hashedPassword, now, userID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error activating user: %v", err)
+		return 0, err
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return 0, invitation.ErrInvalidInviteToken
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error committing tx: %v", err)
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvitation(row scanner) (entity invitation.Invitation, err error) {
+	err = row.Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.UserID,
+		&entity.InvitedBy,
+		&entity.Email,
+		&entity.FirstName,
+		&entity.RoleID,
+		&entity.CreatedAt,
+		&entity.ExpiresAt,
+		&entity.AcceptedAt,
+	)
+	return
+}
@@ -0,0 +1,65 @@
+package invitation
+
+var (
+	insertPendingUser = `
+		INSERT INTO public."User" (email, firstname, lastname, "password", pending, merchant_id, role_id, created_at, updated_at, deleted_at)
+		VALUES($1, $2, $3, '', true, $4, $5, $6, $6, null) RETURNING id;
+	`
+
+	insertInvitation = `
+		INSERT INTO public."Invitation" (merchant_id, user_id, invited_by, token_hash, created_at, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6) RETURNING id;
+	`
+
+	selectInvitation = `
+		SELECT i.id, i.merchant_id, i.user_id, i.invited_by, u.email, u.firstname, u.role_id, i.created_at, i.expires_at, i.accepted_at
+		FROM "Invitation" i
+		JOIN "User" u ON u.id = i.user_id
+	`
+
+	getInvitations = selectInvitation + `
+		WHERE i.merchant_id = $1 AND i.accepted_at IS NULL
+		ORDER BY i.id;
+	`
+
+	getInvitation = selectInvitation + `
+		WHERE i.id = $1 AND i.merchant_id = $2 AND i.accepted_at IS NULL;
+	`
+
+	renewInvitation = `
+		UPDATE "Invitation"
+		SET token_hash = $1, expires_at = $2
+		WHERE id = $3 AND merchant_id = $4 AND accepted_at IS NULL;
+	`
+
+	deleteInvitation = `
+		DELETE FROM "Invitation"
+		WHERE id = $1 AND merchant_id = $2 AND accepted_at IS NULL
+		RETURNING user_id;
+	`
+
+	deletePendingUser = `
+		DELETE FROM "User"
+		WHERE id = $1 AND pending;
+	`
+
+	findInviteUser = `
+		SELECT u.id, u.merchant_id, u.email, u.firstname, u.lastname, u.pending
+		FROM "Invitation" i
+		JOIN "User" u ON u.id = i.user_id
+		WHERE i.token_hash = $1 AND i.accepted_at IS NULL AND i.expires_at > $2 AND u.pending AND u.deleted_at IS NULL;
+	`
+
+	acceptInvitation = `
+		UPDATE "Invitation"
+		SET accepted_at = $1
+		WHERE token_hash = $2 AND accepted_at IS NULL AND expires_at > $1
+		RETURNING user_id;
+	`
+
+	activateUser = `
+		UPDATE "User"
+		SET "password" = $1, pending = false, updated_at = $2
+		WHERE id = $3 AND pending AND "deleted_at" IS NULL;
+	`
+)
@@ -0,0 +1,116 @@
+package merchant
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mhdiiilham/POS/database"
+	"github.com/mhdiiilham/POS/entity/merchant"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// Create inserts a merchant, inside the caller's transaction when there is
+// one.
+func (r *repository) Create(ctx context.Context, entity merchant.Merchant) (id int64, err error) {
+	const ops = "repository.merchant.Create"
+
+	logger.Info(ctx, ops, "creating new merchant")
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, insertMerchant, entity.Name, entity.Logo).Scan(&id)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Get(ctx context.Context, opts *merchant.RepositoryGetMerchantPaginationOptions) (merchants []merchant.Merchant, totalData int, err error) {
+	const ops = "repository.merchant.Get"
+	var cursor int
+	var limit interface{}
+
+	if opts != nil {
+		cursor = opts.Cursor
+		limit = opts.Limit
+	}
+
+	err = r.db.QueryRowContext(ctx, countAllMerchants).Scan(&totalData)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getMerchants, cursor, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m merchant.Merchant
+		if err = rows.Scan(&m.ID, &m.Name, &m.Logo); err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		merchants = append(merchants, m)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetMerchant(ctx context.Context, merchantID int) (entity merchant.Merchant, err error) {
+	const ops = "repository.merchant.GetMerchant"
+
+	err = r.db.QueryRowContext(ctx, getMerchant, merchantID).Scan(
+		&entity.ID,
+		&entity.Name,
+		&entity.Logo,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = merchant.ErrMerchantNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Update(ctx context.Context, entity merchant.Merchant) (err error) {
+	const ops = "repository.merchant.Update"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, updateMerchant, entity.Name, entity.Logo, entity.ID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to update merchant: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return merchant.ErrMerchantNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package merchant
+
+var (
+	insertMerchant = `
+		INSERT INTO public."Merchant" ("name", logo)
+		VALUES($1, $2) RETURNING id;
+	`
+
+	getMerchants = `
+		SELECT
+			id,
+			"name",
+			logo
+		FROM "Merchant"
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	countAllMerchants = `
+		SELECT COUNT(id) as "totalMerchants" FROM "Merchant"
+	`
+
+	getMerchant = `
+		SELECT
+			id,
+			"name",
+			logo
+		FROM "Merchant"
+		WHERE id = $1 LIMIT 1
+	`
+
+	updateMerchant = `
+		UPDATE "Merchant"
+		SET "name" = $1, logo = $2
+		WHERE id = $3;
+	`
+)
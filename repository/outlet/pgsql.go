@@ -0,0 +1,165 @@
+package outlet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, entity outlet.Outlet) (id int64, err error) {
+	const ops = "repository.outlet.Create"
+
+	logger.Info(ctx, ops, "creating new outlet for merchant %d", entity.MerchantID)
+	err = r.db.QueryRowContext(
+		ctx,
+		insertOutlet,
+		entity.MerchantID,
+		entity.Name,
+		entity.Location,
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Get(ctx context.Context, merchantID int, opts *outlet.RepositoryGetOutletPaginationOptions) (outlets []outlet.Outlet, totalData int, err error) {
+	const ops = "repository.outlet.Get"
+	var cursor int
+	var limit interface{}
+
+	if opts != nil {
+		cursor = opts.Cursor
+		limit = opts.Limit
+	}
+
+	logger.Info(ctx, ops, "get outlets of merchant %d", merchantID)
+	err = r.db.QueryRowContext(ctx, countAllOutletsInMerchantID, merchantID).Scan(&totalData)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getOutletsByMerchantID, merchantID, cursor, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o outlet.Outlet
+		err = rows.Scan(
+			&o.ID,
+			&o.MerchantID,
+			&o.Name,
+			&o.Location,
+			&o.CreatedAt,
+			&o.UpdatedAt,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		outlets = append(outlets, o)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetOutlet(ctx context.Context, merchantID, outletID int) (entity outlet.Outlet, err error) {
+	const ops = "repository.outlet.GetOutlet"
+
+	err = r.db.QueryRowContext(ctx, getOutlet, outletID, merchantID).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.Name,
+		&entity.Location,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+		&entity.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = outlet.ErrOutletNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Update(ctx context.Context, entity outlet.Outlet) (err error) {
+	const ops = "repository.outlet.Update"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(
+		ctx,
+		updateOutlet,
+		entity.Name,
+		entity.Location,
+		time.Now(),
+		entity.ID,
+		entity.MerchantID,
+	)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to update outlet: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return outlet.ErrOutletNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) Remove(ctx context.Context, merchantID, outletID int) (err error) {
+	const ops = "repository.outlet.Remove"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, deleteOutletFromID, time.Now(), outletID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to delete outlet: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return outlet.ErrOutletNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package outlet
+
+var (
+	insertOutlet = `
+		INSERT INTO public."Outlet" (merchant_id, "name", "location", created_at, updated_at, deleted_at)
+		VALUES($1, $2, $3, $4, $4, null) RETURNING id;
+	`
+
+	getOutletsByMerchantID = `
+		SELECT
+			id,
+			merchant_id,
+			"name",
+			"location",
+			created_at,
+			updated_at
+		FROM "Outlet"
+		WHERE "merchant_id" = $1 AND "deleted_at" IS NULL AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	countAllOutletsInMerchantID = `
+		SELECT COUNT(id) as "totalOutlets" FROM "Outlet" WHERE "merchant_id" = $1 AND "deleted_at" IS NULL
+	`
+
+	getOutlet = `
+		SELECT
+			id,
+			merchant_id,
+			"name",
+			"location",
+			created_at,
+			updated_at,
+			deleted_at
+		FROM "Outlet"
+		WHERE id = $1 AND "merchant_id" = $2 AND "deleted_at" IS NULL LIMIT 1
+	`
+
+	updateOutlet = `
+		UPDATE "Outlet"
+		SET "name" = $1, "location" = $2, "updated_at" = $3
+		WHERE id = $4 AND "merchant_id" = $5 AND "deleted_at" IS NULL;
+	`
+
+	deleteOutletFromID = `
+		UPDATE "Outlet"
+		SET "deleted_at" = $1
+		WHERE id = $2 AND "merchant_id" = $3 AND "deleted_at" IS NULL;
+	`
+)
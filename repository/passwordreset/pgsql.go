@@ -0,0 +1,127 @@
+package passwordreset
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/passwordreset"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// Create stores a new reset token and retires the user's earlier ones, so
+// only the most recent link works.
+func (r *repository) Create(ctx context.Context, entity passwordreset.Token) (id int64, err error) {
+	const ops = "repository.passwordreset.Create"
+	var tx *sql.Tx
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, expireUserTokens, entity.CreatedAt, entity.UserID); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error expiring earlier tokens: %v", err)
+		return
+	}
+
+	err = tx.QueryRowContext(ctx, insertToken, entity.UserID, entity.TokenHash, entity.CreatedAt, entity.ExpiresAt).Scan(&id)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	err = tx.Commit()
+	return
+}
+
+// FindUser returns the user an unused, unexpired token belongs to, without
+// spending the token.
+func (r *repository) FindUser(ctx context.Context, tokenHash string) (entity user.User, err error) {
+	const ops = "repository.passwordreset.FindUser"
+
+	err = r.db.QueryRowContext(ctx, findTokenUser, tokenHash, time.Now()).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.Email,
+		&entity.FirstName,
+		&entity.LastName,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, passwordreset.ErrInvalidResetToken
+		}
+
+		logger.Error(ctx, ops, "error finding token user: %v", err)
+		return user.User{}, err
+	}
+
+	return entity, nil
+}
+
+// Redeem marks the token used and sets the user's password in one
+// transaction, so a token can never be spent twice.
+func (r *repository) Redeem(ctx context.Context, tokenHash, hashedPassword string) (userID int, err error) {
+	const ops = "repository.passwordreset.Redeem"
+	var tx *sql.Tx
+	var res sql.Result
+	var rowsAffected int64
+	now := time.Now()
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	err = tx.QueryRowContext(ctx, redeemToken, now, tokenHash).Scan(&userID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, passwordreset.ErrInvalidResetToken
+		}
+
+		logger.Error(ctx, ops, "error redeeming token: %v", err)
+		return 0, err
+	}
+
+	res, err = tx.ExecContext(ctx, updateUserPassword, hashedPassword, now, userID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error updating password: %v", err)
+		return 0, err
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return 0, passwordreset.ErrInvalidResetToken
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error committing tx: %v", err)
+		return 0, err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,34 @@
+package passwordreset
+
+var (
+	expireUserTokens = `
+		UPDATE "PasswordReset"
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL;
+	`
+
+	insertToken = `
+		INSERT INTO public."PasswordReset" (user_id, token_hash, created_at, expires_at)
+		VALUES($1, $2, $3, $4) RETURNING id;
+	`
+
+	findTokenUser = `
+		SELECT u.id, u.merchant_id, u.email, u.firstname, u.lastname
+		FROM "PasswordReset" pr
+		JOIN "User" u ON u.id = pr.user_id
+		WHERE pr.token_hash = $1 AND pr.used_at IS NULL AND pr.expires_at > $2 AND u.deleted_at IS NULL;
+	`
+
+	redeemToken = `
+		UPDATE "PasswordReset"
+		SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING user_id;
+	`
+
+	updateUserPassword = `
+		UPDATE "User"
+		SET "password" = $1, updated_at = $2
+		WHERE id = $3 AND "deleted_at" IS NULL;
+	`
+)
@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// GetOutletMethods returns only the methods the outlet has configured;
+// callers fall back to Method.EnabledByDefault for the rest.
+func (r *repository) GetOutletMethods(ctx context.Context, outletID int) (methods []payment.OutletPaymentMethod, err error) {
+	const ops = "repository.payment.GetOutletMethods"
+
+	rows, err := r.db.QueryContext(ctx, getOutletPaymentMethods, outletID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m payment.OutletPaymentMethod
+		if err = rows.Scan(&m.OutletID, &m.Method, &m.Enabled); err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		methods = append(methods, m)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) SetOutletMethod(ctx context.Context, entity payment.OutletPaymentMethod) (err error) {
+	const ops = "repository.payment.SetOutletMethod"
+
+	_, err = r.db.ExecContext(ctx, upsertOutletPaymentMethod, entity.OutletID, entity.Method, entity.Enabled)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,15 @@
+package payment
+
+var (
+	getOutletPaymentMethods = `
+		SELECT outlet_id, method, enabled
+		FROM "OutletPaymentMethod"
+		WHERE outlet_id = $1
+	`
+
+	upsertOutletPaymentMethod = `
+		INSERT INTO public."OutletPaymentMethod" (outlet_id, method, enabled)
+		VALUES($1, $2, $3)
+		ON CONFLICT (outlet_id, method) DO UPDATE SET enabled = EXCLUDED.enabled;
+	`
+)
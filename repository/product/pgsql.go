@@ -0,0 +1,206 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+const uniqueViolation pq.ErrorCode = "23505"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, entity product.Product) (id int64, err error) {
+	const ops = "repository.product.Create"
+
+	logger.Info(ctx, ops, "creating new product for merchant %d", entity.MerchantID)
+	err = r.db.QueryRowContext(
+		ctx,
+		insertProduct,
+		entity.MerchantID,
+		entity.SKU,
+		entity.Name,
+		entity.DisplayImage,
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, product.ErrSKUNotUnique
+		}
+
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Get(ctx context.Context, merchantID int, opts *product.RepositoryGetProductPaginationOptions) (products []product.Product, totalData int, err error) {
+	const ops = "repository.product.Get"
+	var cursor int
+	var limit interface{}
+
+	if opts != nil {
+		cursor = opts.Cursor
+		limit = opts.Limit
+	}
+
+	logger.Info(ctx, ops, "get products of merchant %d", merchantID)
+	err = r.db.QueryRowContext(ctx, countAllProductsInMerchantID, merchantID).Scan(&totalData)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getProductsByMerchantID, merchantID, cursor, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p product.Product
+		err = rows.Scan(
+			&p.ID,
+			&p.MerchantID,
+			&p.SKU,
+			&p.Name,
+			&p.DisplayImage,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		products = append(products, p)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetProduct(ctx context.Context, merchantID, productID int) (entity product.Product, err error) {
+	const ops = "repository.product.GetProduct"
+
+	err = r.db.QueryRowContext(ctx, getProduct, productID, merchantID).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.SKU,
+		&entity.Name,
+		&entity.DisplayImage,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+		&entity.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = product.ErrProductNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) FindProductBySKU(ctx context.Context, merchantID int, sku string) (*product.Product, error) {
+	const ops = "repository.product.FindProductBySKU"
+	var entity product.Product
+
+	err := r.db.QueryRowContext(ctx, findProductBySKU, merchantID, sku).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.SKU,
+		&entity.Name,
+		&entity.DisplayImage,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+		&entity.DeletedAt,
+	)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error(ctx, ops, "trying to find product by sku err: %v", err)
+		}
+		return nil, err
+	}
+
+	return &entity, nil
+}
+
+func (r *repository) Update(ctx context.Context, entity product.Product) (err error) {
+	const ops = "repository.product.Update"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(
+		ctx,
+		updateProduct,
+		entity.SKU,
+		entity.Name,
+		entity.DisplayImage,
+		time.Now(),
+		entity.ID,
+		entity.MerchantID,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return product.ErrSKUNotUnique
+		}
+
+		logger.Error(ctx, ops, "error trying to update product: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return product.ErrProductNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) Remove(ctx context.Context, merchantID, productID int) (err error) {
+	const ops = "repository.product.Remove"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, deleteProductFromID, time.Now(), productID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to delete product: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return product.ErrProductNotFound
+	}
+
+	return nil
+}
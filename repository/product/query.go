@@ -0,0 +1,67 @@
+package product
+
+var (
+	insertProduct = `
+		INSERT INTO public."Product" (merchant_id, sku, "name", display_image, created_at, updated_at, deleted_at)
+		VALUES($1, $2, $3, $4, $5, $5, null) RETURNING id;
+	`
+
+	getProductsByMerchantID = `
+		SELECT
+			id,
+			merchant_id,
+			sku,
+			"name",
+			display_image,
+			created_at,
+			updated_at
+		FROM "Product"
+		WHERE "merchant_id" = $1 AND "deleted_at" IS NULL AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	countAllProductsInMerchantID = `
+		SELECT COUNT(id) as "totalProducts" FROM "Product" WHERE "merchant_id" = $1 AND "deleted_at" IS NULL
+	`
+
+	getProduct = `
+		SELECT
+			id,
+			merchant_id,
+			sku,
+			"name",
+			display_image,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM "Product"
+		WHERE id = $1 AND "merchant_id" = $2 AND "deleted_at" IS NULL LIMIT 1
+	`
+
+	findProductBySKU = `
+		SELECT
+			id,
+			merchant_id,
+			sku,
+			"name",
+			display_image,
+			created_at,
+			updated_at,
+			deleted_at
+		FROM "Product"
+		WHERE "merchant_id" = $1 AND "sku" = $2 AND "deleted_at" IS NULL LIMIT 1
+	`
+
+	updateProduct = `
+		UPDATE "Product"
+		SET sku = $1, "name" = $2, display_image = $3, "updated_at" = $4
+		WHERE id = $5 AND "merchant_id" = $6 AND "deleted_at" IS NULL;
+	`
+
+	deleteProductFromID = `
+		UPDATE "Product"
+		SET "deleted_at" = $1
+		WHERE id = $2 AND "merchant_id" = $3 AND "deleted_at" IS NULL;
+	`
+)
@@ -0,0 +1,442 @@
+package refund
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mhdiiilham/POS/database"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/refund"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+type refundableItem struct {
+	saleItemID int
+	productID  int
+	sold       int
+	refunded   int
+	price      float64
+}
+
+// Create records a void or refund against a sale. It must run inside the
+// caller's transaction: the sale row is locked first so concurrent refunds of
+// the same sale serialize until the caller commits, and the shift the refund
+// is booked against is share-locked so it can't close before then. A shift
+// that closed meanwhile fails a void with ErrVoidWindowExpired and a refund
+// with shift.ErrNoOpenShift. Every returned quantity is then checked against
+// what is still refundable and put back on the outlet stock, and the refund is
+// allocated over the sale's tenders with refund.Allocate. Its card and
+// e-wallet payments are stored pending; the caller refunds them through the
+// gateway once the refund is committed.
+func (r *repository) Create(ctx context.Context, entity refund.Refund) (refund.Refund, error) {
+	const ops = "repository.refund.Create"
+	var status sale.Status
+	var change float64
+	now := time.Now()
+
+	tx := database.Conn(ctx, r.db)
+
+	err := tx.QueryRowContext(ctx, lockSale, entity.SaleID, entity.MerchantID).Scan(&entity.OutletID, &status, &change)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return refund.Refund{}, sale.ErrSaleNotFound
+		}
+
+		logger.Error(ctx, ops, "error trying to lock sale: %v", err)
+		return refund.Refund{}, err
+	}
+
+	if status == sale.StatusVoided {
+		return refund.Refund{}, refund.ErrSaleAlreadyVoided
+	}
+
+	if entity.Type == refund.TypeVoid && status != sale.StatusCompleted {
+		return refund.Refund{}, refund.ErrSaleAlreadyRefunded
+	}
+
+	if entity.ShiftID != nil {
+		var shiftOpen bool
+
+		err = tx.QueryRowContext(ctx, lockOpenShift, *entity.ShiftID, entity.OutletID).Scan(&shiftOpen)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Error(ctx, ops, "error trying to lock shift: %v", err)
+			return refund.Refund{}, err
+		}
+
+		if !shiftOpen {
+			if entity.Type == refund.TypeVoid {
+				return refund.Refund{}, refund.ErrVoidWindowExpired
+			}
+			return refund.Refund{}, shift.ErrNoOpenShift
+		}
+	}
+
+	refundable, err := refundableItems(ctx, tx, entity.SaleID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to get sale items: %v", err)
+		return refund.Refund{}, err
+	}
+
+	items, err := resolveItems(entity, refundable)
+	if err != nil {
+		return refund.Refund{}, err
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	reason := inventory.ReasonRefund
+	if entity.Type == refund.TypeVoid {
+		reason = inventory.ReasonVoid
+	}
+
+	entity.Total = 0
+	for _, item := range items {
+		var stock int
+
+		err = tx.QueryRowContext(ctx, lockOutletProductStock, entity.OutletID, item.ProductID).Scan(&stock)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return refund.Refund{}, fmt.Errorf("%w: product %d", inventory.ErrOutletProductNotFound, item.ProductID)
+			}
+
+			logger.Error(ctx, ops, "error trying to lock outlet product: %v", err)
+			return refund.Refund{}, err
+		}
+
+		stock += item.Quantity
+		if _, err = tx.ExecContext(ctx, updateOutletProductStock, stock, entity.OutletID, item.ProductID); err != nil {
+			logger.Error(ctx, ops, "error trying to update stock: %v", err)
+			return refund.Refund{}, err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			insertStockMovement,
+			entity.OutletID,
+			item.ProductID,
+			entity.UserID,
+			item.Quantity,
+			stock,
+			reason,
+			nil,
+			now,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "error trying to record stock movement: %v", err)
+			return refund.Refund{}, err
+		}
+
+		entity.Total = roundCurrency(entity.Total + item.Amount)
+	}
+
+	paid, err := salePayments(ctx, tx, entity.SaleID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to get sale payments: %v", err)
+		return refund.Refund{}, err
+	}
+
+	earlier, err := refundPayments(ctx, tx, entity.SaleID, entity.MerchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to get earlier refund payments: %v", err)
+		return refund.Refund{}, err
+	}
+
+	payments, err := refund.Allocate(entity.Total, paid, change, earlier)
+	if err != nil {
+		return refund.Refund{}, err
+	}
+
+	entity.CreatedAt = now
+	err = tx.QueryRowContext(
+		ctx,
+		insertRefund,
+		entity.SaleID,
+		entity.MerchantID,
+		entity.OutletID,
+		entity.ShiftID,
+		entity.UserID,
+		entity.Type,
+		entity.Reason,
+		entity.Note,
+		entity.Total,
+		now,
+	).Scan(&entity.ID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to insert refund: %v", err)
+		return refund.Refund{}, err
+	}
+
+	for i, item := range items {
+		item.RefundID = entity.ID
+		err = tx.QueryRowContext(
+			ctx,
+			insertRefundItem,
+			item.RefundID,
+			item.SaleItemID,
+			item.ProductID,
+			item.Quantity,
+			item.Amount,
+		).Scan(&item.ID)
+		if err != nil {
+			logger.Error(ctx, ops, "error trying to insert refund item: %v", err)
+			return refund.Refund{}, err
+		}
+		items[i] = item
+	}
+
+	for i, p := range payments {
+		p.RefundID = entity.ID
+		err = tx.QueryRowContext(ctx, insertRefundPayment, p.RefundID, p.Method, p.Amount, p.Reference, p.Status).Scan(&p.ID)
+		if err != nil {
+			logger.Error(ctx, ops, "error trying to insert refund payment: %v", err)
+			return refund.Refund{}, err
+		}
+		payments[i] = p
+	}
+
+	if _, err = tx.ExecContext(ctx, updateSaleStatus, saleStatusAfter(entity.Type, refundable, items), entity.SaleID); err != nil {
+		logger.Error(ctx, ops, "error trying to update sale status: %v", err)
+		return refund.Refund{}, err
+	}
+
+	entity.Items = items
+	entity.Payments = payments
+	return entity, nil
+}
+
+func (r *repository) GetBySale(ctx context.Context, merchantID, saleID int) (refunds []refund.Refund, err error) {
+	const ops = "repository.refund.GetBySale"
+	positions := make(map[int]int)
+
+	rows, err := r.db.QueryContext(ctx, getRefundsBySaleID, saleID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rf refund.Refund
+		err = rows.Scan(
+			&rf.ID,
+			&rf.SaleID,
+			&rf.MerchantID,
+			&rf.OutletID,
+			&rf.ShiftID,
+			&rf.UserID,
+			&rf.Type,
+			&rf.Reason,
+			&rf.Note,
+			&rf.Total,
+			&rf.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		positions[rf.ID] = len(refunds)
+		refunds = append(refunds, rf)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	itemRows, err := r.db.QueryContext(ctx, getRefundItemsBySaleID, saleID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item refund.Item
+		err = itemRows.Scan(
+			&item.ID,
+			&item.RefundID,
+			&item.SaleItemID,
+			&item.ProductID,
+			&item.Quantity,
+			&item.Amount,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		if i, ok := positions[item.RefundID]; ok {
+			refunds[i].Items = append(refunds[i].Items, item)
+		}
+	}
+
+	if err = itemRows.Err(); err != nil {
+		return
+	}
+
+	payments, err := refundPayments(ctx, r.db, saleID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+
+	for _, p := range payments {
+		if i, ok := positions[p.RefundID]; ok {
+			refunds[i].Payments = append(refunds[i].Payments, p)
+		}
+	}
+
+	return
+}
+
+// MarkPaymentRefunded records that the gateway has paid a pending refund
+// payment back.
+func (r *repository) MarkPaymentRefunded(ctx context.Context, paymentID int) error {
+	const ops = "repository.refund.MarkPaymentRefunded"
+
+	if _, err := r.db.ExecContext(ctx, markRefundPaymentRefunded, refund.PaymentStatusRefunded, paymentID); err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// querier is what *sql.DB and *sql.Tx have in common for reads.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func salePayments(ctx context.Context, q querier, saleID int) (payments []payment.Payment, err error) {
+	rows, err := q.QueryContext(ctx, getSalePayments, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p payment.Payment
+		if err = rows.Scan(&p.Method, &p.Amount, &p.Reference); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+func refundPayments(ctx context.Context, q querier, saleID, merchantID int) (payments []refund.Payment, err error) {
+	rows, err := q.QueryContext(ctx, getRefundPaymentsBySaleID, saleID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p refund.Payment
+		if err = rows.Scan(&p.ID, &p.RefundID, &p.Method, &p.Amount, &p.Reference, &p.Status); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+func refundableItems(ctx context.Context, q querier, saleID int) (items []refundableItem, err error) {
+	rows, err := q.QueryContext(ctx, getRefundableSaleItems, saleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item refundableItem
+		if err = rows.Scan(&item.saleItemID, &item.productID, &item.sold, &item.price, &item.refunded); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// resolveItems turns the requested refund lines into refund items priced from
+// the sale. A void returns every remaining unit of every line.
+func resolveItems(entity refund.Refund, refundable []refundableItem) ([]refund.Item, error) {
+	var items []refund.Item
+
+	if entity.Type == refund.TypeVoid {
+		for _, line := range refundable {
+			items = append(items, refund.Item{
+				SaleItemID: line.saleItemID,
+				ProductID:  line.productID,
+				Quantity:   line.sold,
+				Amount:     roundCurrency(line.price * float64(line.sold)),
+			})
+		}
+		return items, nil
+	}
+
+	byProduct := make(map[int]refundableItem, len(refundable))
+	for _, line := range refundable {
+		byProduct[line.productID] = line
+	}
+
+	for _, requested := range entity.Items {
+		line, ok := byProduct[requested.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %d", refund.ErrItemNotInSale, requested.ProductID)
+		}
+
+		if requested.Quantity > line.sold-line.refunded {
+			return nil, fmt.Errorf("%w: product %d has %d refundable", refund.ErrRefundExceedsSold, requested.ProductID, line.sold-line.refunded)
+		}
+
+		items = append(items, refund.Item{
+			SaleItemID: line.saleItemID,
+			ProductID:  line.productID,
+			Quantity:   requested.Quantity,
+			Amount:     roundCurrency(line.price * float64(requested.Quantity)),
+		})
+	}
+
+	return items, nil
+}
+
+func saleStatusAfter(refundType refund.Type, refundable []refundableItem, items []refund.Item) sale.Status {
+	if refundType == refund.TypeVoid {
+		return sale.StatusVoided
+	}
+
+	returned := make(map[int]int, len(items))
+	for _, item := range items {
+		returned[item.SaleItemID] += item.Quantity
+	}
+
+	for _, line := range refundable {
+		if line.refunded+returned[line.saleItemID] < line.sold {
+			return sale.StatusPartiallyRefunded
+		}
+	}
+
+	return sale.StatusRefunded
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,58 @@
+package refund
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mhdiiilham/POS/entity/refund"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The service checks the shift before the transaction starts; a shift that
+// closes before Create locks it must still fail the refund.
+func TestRepository_Create_shiftClosedMeanwhile(t *testing.T) {
+	const merchantID, outletID, saleID, shiftID = 1, 2, 4, 6
+
+	testCases := []struct {
+		name       string
+		refundType refund.Type
+		err        error
+	}{
+		{name: "void", refundType: refund.TypeVoid, err: refund.ErrVoidWindowExpired},
+		{name: "refund", refundType: refund.TypeRefund, err: shift.ErrNoOpenShift},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			m.ExpectQuery(lockSale).
+				WithArgs(saleID, merchantID).
+				WillReturnRows(sqlmock.NewRows([]string{"outlet_id", "status", "change_due"}).AddRow(outletID, sale.StatusCompleted, 0))
+			m.ExpectQuery(lockOpenShift).
+				WithArgs(shiftID, outletID).
+				WillReturnRows(sqlmock.NewRows([]string{"open"}).AddRow(false))
+
+			id := shiftID
+			_, err = NewRepository(db).Create(context.Background(), refund.Refund{
+				SaleID:     saleID,
+				MerchantID: merchantID,
+				OutletID:   outletID,
+				ShiftID:    &id,
+				Type:       tc.refundType,
+				Reason:     refund.ReasonCashierError,
+			})
+			assert.ErrorIs(t, err, tc.err)
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
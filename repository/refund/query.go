@@ -0,0 +1,126 @@
+package refund
+
+var (
+	lockSale = `
+		SELECT outlet_id, status, change_due FROM "Sale"
+		WHERE id = $1 AND merchant_id = $2
+		FOR UPDATE
+	`
+
+	lockOpenShift = `
+		SELECT closed_at IS NULL FROM "Shift"
+		WHERE id = $1 AND outlet_id = $2
+		FOR SHARE
+	`
+
+	getRefundableSaleItems = `
+		SELECT
+			si.id,
+			si.product_id,
+			si.quantity,
+			si.price,
+			COALESCE(SUM(ri.quantity), 0) AS refunded
+		FROM "SaleItem" si
+		LEFT JOIN "RefundItem" ri ON ri.sale_item_id = si.id
+		WHERE si.sale_id = $1
+		GROUP BY si.id
+		ORDER BY si.product_id
+	`
+
+	lockOutletProductStock = `
+		SELECT stock FROM "OutletProduct"
+		WHERE outlet_id = $1 AND product_id = $2
+		FOR UPDATE
+	`
+
+	updateOutletProductStock = `
+		UPDATE "OutletProduct"
+		SET stock = $1
+		WHERE outlet_id = $2 AND product_id = $3;
+	`
+
+	insertStockMovement = `
+		INSERT INTO public."StockMovement" (outlet_id, product_id, user_id, quantity, stock_after, reason, note, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	insertRefund = `
+		INSERT INTO public."Refund" (sale_id, merchant_id, outlet_id, shift_id, user_id, "type", reason, note, total, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id;
+	`
+
+	insertRefundItem = `
+		INSERT INTO public."RefundItem" (refund_id, sale_item_id, product_id, quantity, amount)
+		VALUES($1, $2, $3, $4, $5) RETURNING id;
+	`
+
+	getSalePayments = `
+		SELECT method, amount, reference
+		FROM "Payment"
+		WHERE sale_id = $1
+		ORDER BY id
+	`
+
+	getRefundPaymentsBySaleID = `
+		SELECT
+			rp.id,
+			rp.refund_id,
+			rp.method,
+			rp.amount,
+			rp.reference,
+			rp.status
+		FROM "RefundPayment" rp
+		JOIN "Refund" r ON r.id = rp.refund_id
+		WHERE r.sale_id = $1 AND r.merchant_id = $2
+		ORDER BY rp.id
+	`
+
+	insertRefundPayment = `
+		INSERT INTO public."RefundPayment" (refund_id, method, amount, reference, status)
+		VALUES($1, $2, $3, $4, $5) RETURNING id;
+	`
+
+	markRefundPaymentRefunded = `
+		UPDATE "RefundPayment"
+		SET status = $1
+		WHERE id = $2;
+	`
+
+	updateSaleStatus = `
+		UPDATE "Sale"
+		SET status = $1
+		WHERE id = $2;
+	`
+
+	getRefundsBySaleID = `
+		SELECT
+			id,
+			sale_id,
+			merchant_id,
+			outlet_id,
+			shift_id,
+			user_id,
+			"type",
+			reason,
+			note,
+			total,
+			created_at
+		FROM "Refund"
+		WHERE sale_id = $1 AND merchant_id = $2
+		ORDER BY id
+	`
+
+	getRefundItemsBySaleID = `
+		SELECT
+			ri.id,
+			ri.refund_id,
+			ri.sale_item_id,
+			ri.product_id,
+			ri.quantity,
+			ri.amount
+		FROM "RefundItem" ri
+		JOIN "Refund" r ON r.id = ri.refund_id
+		WHERE r.sale_id = $1 AND r.merchant_id = $2
+		ORDER BY ri.id
+	`
+)
@@ -0,0 +1,207 @@
+package role
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+const uniqueViolation pq.ErrorCode = "23505"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, entity role.Role) (id int64, err error) {
+	const ops = "repository.role.Create"
+
+	err = r.db.QueryRowContext(
+		ctx,
+		insertRole,
+		entity.MerchantID,
+		entity.Name,
+		pq.Array(permissionStrings(entity.Permissions)),
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, role.ErrRoleNameNotUnique
+		}
+
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	return
+}
+
+// Get returns the built-in roles followed by the merchant's own roles.
+func (r *repository) Get(ctx context.Context, merchantID int) (roles []role.Role, err error) {
+	const ops = "repository.role.Get"
+
+	rows, err := r.db.QueryContext(ctx, getRolesByMerchantID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity role.Role
+		if entity, err = scanRole(rows); err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		roles = append(roles, entity)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetRole(ctx context.Context, merchantID, roleID int) (entity role.Role, err error) {
+	const ops = "repository.role.GetRole"
+
+	entity, err = scanRole(r.db.QueryRowContext(ctx, getRole, roleID, merchantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = role.ErrRoleNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) Update(ctx context.Context, entity role.Role) (err error) {
+	const ops = "repository.role.Update"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(
+		ctx,
+		updateRole,
+		entity.Name,
+		pq.Array(permissionStrings(entity.Permissions)),
+		time.Now(),
+		entity.ID,
+		entity.MerchantID,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return role.ErrRoleNameNotUnique
+		}
+
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return role.ErrRoleNotFound
+	}
+
+	return
+}
+
+// Remove deletes a custom role, refusing while any user still holds it.
+func (r *repository) Remove(ctx context.Context, merchantID, roleID int) (err error) {
+	const ops = "repository.role.Remove"
+	var tx *sql.Tx
+	var res sql.Result
+	var rowsAffected int64
+	var users int
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	res, err = tx.ExecContext(ctx, deleteRoleFromID, time.Now(), roleID, merchantID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error tx.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return role.ErrRoleNotFound
+	}
+
+	if err = tx.QueryRowContext(ctx, countRoleUsers, roleID).Scan(&users); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error counting role users: %v", err)
+		return
+	}
+
+	if users > 0 {
+		tx.Rollback()
+		return role.ErrRoleInUse
+	}
+
+	return tx.Commit()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRole(row scanner) (entity role.Role, err error) {
+	var permissions []string
+
+	err = row.Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.Name,
+		pq.Array(&permissions),
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	entity.Permissions = make([]role.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		entity.Permissions = append(entity.Permissions, role.Permission(p))
+	}
+	return
+}
+
+// permissionStrings converts permissions to the varchar[] the Role table
+// stores.
+func permissionStrings(permissions []role.Permission) []string {
+	values := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		values = append(values, string(p))
+	}
+	return values
+}
@@ -0,0 +1,49 @@
+package role
+
+var (
+	insertRole = `
+		INSERT INTO public."Role" (merchant_id, "name", permissions, created_at, updated_at, deleted_at)
+		VALUES($1, $2, $3, $4, $4, null) RETURNING id;
+	`
+
+	getRolesByMerchantID = `
+		SELECT
+			id,
+			merchant_id,
+			"name",
+			permissions,
+			created_at,
+			updated_at
+		FROM "Role"
+		WHERE ("merchant_id" IS NULL OR "merchant_id" = $1) AND "deleted_at" IS NULL
+		ORDER BY id
+	`
+
+	getRole = `
+		SELECT
+			id,
+			merchant_id,
+			"name",
+			permissions,
+			created_at,
+			updated_at
+		FROM "Role"
+		WHERE id = $1 AND ("merchant_id" IS NULL OR "merchant_id" = $2) AND "deleted_at" IS NULL LIMIT 1
+	`
+
+	updateRole = `
+		UPDATE "Role"
+		SET "name" = $1, permissions = $2, "updated_at" = $3
+		WHERE id = $4 AND "merchant_id" = $5 AND "deleted_at" IS NULL;
+	`
+
+	countRoleUsers = `
+		SELECT COUNT(id) FROM "User" WHERE role_id = $1 AND "deleted_at" IS NULL
+	`
+
+	deleteRoleFromID = `
+		UPDATE "Role"
+		SET "deleted_at" = $1
+		WHERE id = $2 AND "merchant_id" = $3 AND "deleted_at" IS NULL;
+	`
+)
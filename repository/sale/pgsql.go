@@ -0,0 +1,328 @@
+package sale
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// Create stores a sale with its items and payments in a single transaction.
+// The shift is share-locked so it can't be closed underneath the sale, then
+// every outlet product is locked in product id order, its price is copied onto
+// the item and its stock decremented. The sale is only finalized once the
+// payments cover the resulting total, so any failing line or short payment
+// rolls the whole sale back.
+func (r *repository) Create(ctx context.Context, entity sale.Sale) (sale.Sale, error) {
+	const ops = "repository.sale.Create"
+	var shiftOpen bool
+	now := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin transaction %v", err)
+		return sale.Sale{}, err
+	}
+
+	err = tx.QueryRowContext(ctx, lockOpenShift, entity.ShiftID, entity.OutletID).Scan(&shiftOpen)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to lock shift: %v", err)
+		return sale.Sale{}, err
+	}
+
+	if !shiftOpen {
+		tx.Rollback()
+		return sale.Sale{}, shift.ErrNoOpenShift
+	}
+
+	items := make([]sale.Item, len(entity.Items))
+	copy(items, entity.Items)
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	entity.Total = 0
+	for i, item := range items {
+		var stock int
+
+		err = tx.QueryRowContext(ctx, lockOutletProduct, entity.OutletID, item.ProductID).Scan(
+			&item.SKU,
+			&item.Name,
+			&item.Price,
+			&stock,
+		)
+		if err != nil {
+			tx.Rollback()
+			if errors.Is(err, sql.ErrNoRows) {
+				return sale.Sale{}, fmt.Errorf("%w: product %d", inventory.ErrOutletProductNotFound, item.ProductID)
+			}
+
+			logger.Error(ctx, ops, "error trying to lock outlet product: %v", err)
+			return sale.Sale{}, err
+		}
+
+		if stock < item.Quantity {
+			tx.Rollback()
+			return sale.Sale{}, fmt.Errorf("%w: product %d", inventory.ErrInsufficientStock, item.ProductID)
+		}
+
+		stock -= item.Quantity
+		if _, err = tx.ExecContext(ctx, updateOutletProductStock, stock, entity.OutletID, item.ProductID); err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error trying to update stock: %v", err)
+			return sale.Sale{}, err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			insertStockMovement,
+			entity.OutletID,
+			item.ProductID,
+			entity.CashierID,
+			-item.Quantity,
+			stock,
+			inventory.ReasonSale,
+			nil,
+			now,
+		)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error trying to record stock movement: %v", err)
+			return sale.Sale{}, err
+		}
+
+		item.Subtotal = roundCurrency(item.Price * float64(item.Quantity))
+		entity.Total = roundCurrency(entity.Total + item.Subtotal)
+		items[i] = item
+	}
+
+	entity.Paid, entity.Change, err = payment.Settle(entity.Total, entity.Payments)
+	if err != nil {
+		tx.Rollback()
+		return sale.Sale{}, err
+	}
+
+	entity.CreatedAt = now
+	err = tx.QueryRowContext(
+		ctx,
+		insertSale,
+		entity.MerchantID,
+		entity.OutletID,
+		entity.ShiftID,
+		entity.CashierID,
+		entity.Status,
+		entity.Total,
+		entity.Paid,
+		entity.Change,
+		now,
+	).Scan(&entity.ID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to insert sale: %v", err)
+		return sale.Sale{}, err
+	}
+
+	for i, item := range items {
+		item.SaleID = entity.ID
+		err = tx.QueryRowContext(
+			ctx,
+			insertSaleItem,
+			item.SaleID,
+			item.ProductID,
+			item.SKU,
+			item.Name,
+			item.Quantity,
+			item.Price,
+			item.Subtotal,
+		).Scan(&item.ID)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error trying to insert sale item: %v", err)
+			return sale.Sale{}, err
+		}
+		items[i] = item
+	}
+
+	payments := make([]payment.Payment, len(entity.Payments))
+	for i, p := range entity.Payments {
+		p.SaleID = entity.ID
+		p.CreatedAt = now
+		err = tx.QueryRowContext(
+			ctx,
+			insertPayment,
+			p.SaleID,
+			p.Method,
+			p.Amount,
+			p.Reference,
+			p.CreatedAt,
+		).Scan(&p.ID)
+		if err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error trying to insert payment: %v", err)
+			return sale.Sale{}, err
+		}
+		payments[i] = p
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error trying to commit transaction: %v", err)
+		return sale.Sale{}, err
+	}
+
+	entity.Items = items
+	entity.Payments = payments
+	return entity, nil
+}
+
+func (r *repository) Get(ctx context.Context, merchantID, outletID int, opts *sale.RepositoryGetSalePaginationOptions) (sales []sale.Sale, totalData int, err error) {
+	const ops = "repository.sale.Get"
+	var cursor int
+	var limit interface{}
+
+	if opts != nil {
+		cursor = opts.Cursor
+		limit = opts.Limit
+	}
+
+	err = r.db.QueryRowContext(ctx, countAllSalesInOutletID, merchantID, outletID).Scan(&totalData)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getSalesByOutletID, merchantID, outletID, cursor, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s sale.Sale
+		err = rows.Scan(
+			&s.ID,
+			&s.MerchantID,
+			&s.OutletID,
+			&s.ShiftID,
+			&s.CashierID,
+			&s.Status,
+			&s.Total,
+			&s.Paid,
+			&s.Change,
+			&s.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		sales = append(sales, s)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetSale(ctx context.Context, merchantID, saleID int) (entity sale.Sale, err error) {
+	const ops = "repository.sale.GetSale"
+
+	err = r.db.QueryRowContext(ctx, getSale, saleID, merchantID).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.OutletID,
+		&entity.ShiftID,
+		&entity.CashierID,
+		&entity.Status,
+		&entity.Total,
+		&entity.Paid,
+		&entity.Change,
+		&entity.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = sale.ErrSaleNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getSaleItems, entity.ID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting sale items: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item sale.Item
+		err = rows.Scan(
+			&item.ID,
+			&item.SaleID,
+			&item.ProductID,
+			&item.SKU,
+			&item.Name,
+			&item.Quantity,
+			&item.Price,
+			&item.Subtotal,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		entity.Items = append(entity.Items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	paymentRows, err := r.db.QueryContext(ctx, getSalePayments, entity.ID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting sale payments: %v", err)
+		return
+	}
+	defer paymentRows.Close()
+
+	for paymentRows.Next() {
+		var p payment.Payment
+		err = paymentRows.Scan(
+			&p.ID,
+			&p.SaleID,
+			&p.Method,
+			&p.Amount,
+			&p.Reference,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		entity.Payments = append(entity.Payments, p)
+	}
+
+	err = paymentRows.Err()
+	return
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
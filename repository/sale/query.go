@@ -0,0 +1,113 @@
+package sale
+
+var (
+	lockOpenShift = `
+		SELECT closed_at IS NULL FROM "Shift"
+		WHERE id = $1 AND outlet_id = $2
+		FOR SHARE
+	`
+
+	lockOutletProduct = `
+		SELECT
+			p.sku,
+			p."name",
+			op.price,
+			op.stock
+		FROM "OutletProduct" op
+		JOIN "Product" p ON p.id = op.product_id AND p."deleted_at" IS NULL
+		WHERE op.outlet_id = $1 AND op.product_id = $2
+		FOR UPDATE OF op
+	`
+
+	updateOutletProductStock = `
+		UPDATE "OutletProduct"
+		SET stock = $1
+		WHERE outlet_id = $2 AND product_id = $3;
+	`
+
+	insertStockMovement = `
+		INSERT INTO public."StockMovement" (outlet_id, product_id, user_id, quantity, stock_after, reason, note, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	insertSale = `
+		INSERT INTO public."Sale" (merchant_id, outlet_id, shift_id, cashier_id, status, total, paid, change_due, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;
+	`
+
+	insertSaleItem = `
+		INSERT INTO public."SaleItem" (sale_id, product_id, sku, "name", quantity, price, subtotal)
+		VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id;
+	`
+
+	insertPayment = `
+		INSERT INTO public."Payment" (sale_id, method, amount, reference, created_at)
+		VALUES($1, $2, $3, $4, $5) RETURNING id;
+	`
+
+	getSalesByOutletID = `
+		SELECT
+			id,
+			merchant_id,
+			outlet_id,
+			shift_id,
+			cashier_id,
+			status,
+			total,
+			paid,
+			change_due,
+			created_at
+		FROM "Sale"
+		WHERE merchant_id = $1 AND outlet_id = $2 AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+
+	countAllSalesInOutletID = `
+		SELECT COUNT(id) as "totalSales" FROM "Sale" WHERE merchant_id = $1 AND outlet_id = $2
+	`
+
+	getSale = `
+		SELECT
+			id,
+			merchant_id,
+			outlet_id,
+			shift_id,
+			cashier_id,
+			status,
+			total,
+			paid,
+			change_due,
+			created_at
+		FROM "Sale"
+		WHERE id = $1 AND merchant_id = $2 LIMIT 1
+	`
+
+	getSaleItems = `
+		SELECT
+			id,
+			sale_id,
+			product_id,
+			sku,
+			"name",
+			quantity,
+			price,
+			subtotal
+		FROM "SaleItem"
+		WHERE sale_id = $1
+		ORDER BY id
+	`
+
+	getSalePayments = `
+		SELECT
+			id,
+			sale_id,
+			method,
+			amount,
+			reference,
+			created_at
+		FROM "Payment"
+		WHERE sale_id = $1
+		ORDER BY id
+	`
+)
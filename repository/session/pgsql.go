@@ -0,0 +1,145 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, entity session.Session) (err error) {
+	const ops = "repository.session.Create"
+
+	_, err = r.db.ExecContext(
+		ctx,
+		insertSession,
+		entity.ID,
+		entity.UserID,
+		entity.MerchantID,
+		entity.OutletID,
+		entity.RefreshTokenHash,
+		entity.CreatedAt,
+		entity.ExpiresAt,
+	)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) GetSession(ctx context.Context, sessionID string) (entity session.Session, err error) {
+	const ops = "repository.session.GetSession"
+
+	err = r.db.QueryRowContext(ctx, getSession, sessionID).Scan(
+		&entity.ID,
+		&entity.UserID,
+		&entity.MerchantID,
+		&entity.OutletID,
+		&entity.RefreshTokenHash,
+		&entity.CreatedAt,
+		&entity.ExpiresAt,
+		&entity.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = session.ErrSessionNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+// Rotate swaps the refresh token hash only if it still holds currentHash, so
+// two concurrent refreshes with the same token can't both succeed.
+func (r *repository) Rotate(ctx context.Context, sessionID, currentHash, newHash string, expiresAt time.Time) (err error) {
+	const ops = "repository.session.Rotate"
+
+	result, err := r.db.ExecContext(ctx, rotateSession, newHash, expiresAt, sessionID, currentHash)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(ctx, ops, "error result.RowsAffected: %v", err)
+		return
+	}
+
+	if affected == 0 {
+		return session.ErrInvalidRefreshToken
+	}
+
+	return
+}
+
+func (r *repository) Revoke(ctx context.Context, sessionID string) (err error) {
+	const ops = "repository.session.Revoke"
+
+	_, err = r.db.ExecContext(ctx, revokeSession, time.Now(), sessionID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) RevokeUserSessions(ctx context.Context, userID int) (err error) {
+	const ops = "repository.session.RevokeUserSessions"
+
+	_, err = r.db.ExecContext(ctx, revokeUserSessions, time.Now(), userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+// RevokeOtherSessions ends every session of the user except keepSessionID,
+// the one the request came from.
+func (r *repository) RevokeOtherSessions(ctx context.Context, userID int, keepSessionID string) (err error) {
+	const ops = "repository.session.RevokeOtherSessions"
+
+	_, err = r.db.ExecContext(ctx, revokeOtherSessions, time.Now(), userID, keepSessionID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+// IsActive reports whether the session is unrevoked, unexpired and belongs to
+// a user that has not been deleted.
+func (r *repository) IsActive(ctx context.Context, sessionID string, userID int) (active bool, err error) {
+	const ops = "repository.session.IsActive"
+
+	err = r.db.QueryRowContext(ctx, isSessionActive, sessionID, userID, time.Now()).Scan(&active)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,54 @@
+package session
+
+var (
+	insertSession = `
+		INSERT INTO public."Session" (id, user_id, merchant_id, outlet_id, refresh_token_hash, created_at, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	getSession = `
+		SELECT
+			id,
+			user_id,
+			merchant_id,
+			outlet_id,
+			refresh_token_hash,
+			created_at,
+			expires_at,
+			revoked_at
+		FROM "Session"
+		WHERE id = $1 LIMIT 1
+	`
+
+	rotateSession = `
+		UPDATE "Session"
+		SET refresh_token_hash = $1, expires_at = $2
+		WHERE id = $3 AND refresh_token_hash = $4 AND revoked_at IS NULL;
+	`
+
+	revokeSession = `
+		UPDATE "Session"
+		SET revoked_at = $1
+		WHERE id = $2 AND revoked_at IS NULL;
+	`
+
+	revokeUserSessions = `
+		UPDATE "Session"
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL;
+	`
+
+	revokeOtherSessions = `
+		UPDATE "Session"
+		SET revoked_at = $1
+		WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL;
+	`
+
+	isSessionActive = `
+		SELECT EXISTS (
+			SELECT 1 FROM "Session" s
+			JOIN "User" u ON u.id = s.user_id AND u."deleted_at" IS NULL
+			WHERE s.id = $1 AND s.user_id = $2 AND s.revoked_at IS NULL AND s.expires_at > $3
+		)
+	`
+)
@@ -0,0 +1,210 @@
+package shift
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+const uniqueViolation pq.ErrorCode = "23505"
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Open(ctx context.Context, entity shift.Shift) (id int64, err error) {
+	const ops = "repository.shift.Open"
+
+	logger.Info(ctx, ops, "opening shift for user %d on outlet %d", entity.UserID, entity.OutletID)
+	err = r.db.QueryRowContext(
+		ctx,
+		insertShift,
+		entity.MerchantID,
+		entity.OutletID,
+		entity.UserID,
+		entity.OpeningFloat,
+		time.Now(),
+	).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, shift.ErrShiftAlreadyOpen
+		}
+
+		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
+		return
+	}
+
+	return
+}
+
+// Close locks the shift row, freezes the expected cash and records the
+// counted cash. Checkout takes a share lock on the same row, so no sale can
+// slip into a shift while it is being closed. The totals are read after the
+// lock is granted so they include every sale committed while waiting.
+func (r *repository) Close(ctx context.Context, entity shift.Shift) (shift.Shift, error) {
+	const ops = "repository.shift.Close"
+	var closed shift.Shift
+	now := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin transaction %v", err)
+		return shift.Shift{}, err
+	}
+
+	err = tx.QueryRowContext(ctx, lockShift, entity.ID, entity.MerchantID).Scan(&closed.ID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return shift.Shift{}, shift.ErrShiftNotFound
+		}
+
+		logger.Error(ctx, ops, "error trying to lock shift: %v", err)
+		return shift.Shift{}, err
+	}
+
+	closed, err = scanShift(tx.QueryRowContext(ctx, getShift, entity.ID, entity.MerchantID))
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to get shift: %v", err)
+		return shift.Shift{}, err
+	}
+
+	if !closed.IsOpen() {
+		tx.Rollback()
+		return shift.Shift{}, shift.ErrShiftClosed
+	}
+
+	difference := math.Round((*entity.CountedCash-closed.ExpectedCash)*100) / 100
+	closed.CountedCash = entity.CountedCash
+	closed.Difference = &difference
+	closed.Note = entity.Note
+	closed.ClosedAt = &now
+
+	_, err = tx.ExecContext(
+		ctx,
+		closeShift,
+		closed.ExpectedCash,
+		closed.CountedCash,
+		closed.Difference,
+		closed.Note,
+		now,
+		closed.ID,
+	)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error trying to close shift: %v", err)
+		return shift.Shift{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Error(ctx, ops, "error trying to commit transaction: %v", err)
+		return shift.Shift{}, err
+	}
+
+	return closed, nil
+}
+
+func (r *repository) Get(ctx context.Context, merchantID, outletID int, opts *shift.RepositoryGetShiftPaginationOptions) (shifts []shift.Shift, totalData int, err error) {
+	const ops = "repository.shift.Get"
+	var cursor int
+	var limit interface{}
+
+	if opts != nil {
+		cursor = opts.Cursor
+		limit = opts.Limit
+	}
+
+	err = r.db.QueryRowContext(ctx, countAllShiftsInOutletID, merchantID, outletID).Scan(&totalData)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	rows, err := r.db.QueryContext(ctx, getShiftsByOutletID, merchantID, outletID, cursor, limit)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s shift.Shift
+		if s, err = scanShift(rows); err != nil {
+			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
+			return
+		}
+		shifts = append(shifts, s)
+	}
+
+	err = rows.Err()
+	return
+}
+
+func (r *repository) GetShift(ctx context.Context, merchantID, shiftID int) (entity shift.Shift, err error) {
+	const ops = "repository.shift.GetShift"
+
+	entity, err = scanShift(r.db.QueryRowContext(ctx, getShift, shiftID, merchantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = shift.ErrShiftNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) FindOpenShift(ctx context.Context, merchantID, outletID, userID int) (entity shift.Shift, err error) {
+	const ops = "repository.shift.FindOpenShift"
+
+	entity, err = scanShift(r.db.QueryRowContext(ctx, findOpenShift, merchantID, outletID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = shift.ErrNoOpenShift
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanShift(row scanner) (entity shift.Shift, err error) {
+	err = row.Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.OutletID,
+		&entity.UserID,
+		&entity.OpeningFloat,
+		&entity.ExpectedCash,
+		&entity.CountedCash,
+		&entity.Difference,
+		&entity.Note,
+		&entity.OpenedAt,
+		&entity.ClosedAt,
+	)
+	return
+}
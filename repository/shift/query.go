@@ -0,0 +1,76 @@
+package shift
+
+var (
+	// shiftColumns computes the expected cash live while the shift is open
+	// and uses the figure frozen at closing time afterwards. Only the cash
+	// tendered net of change lands in the drawer, and only the cash part of
+	// voids and refunds leaves it; card and e-wallet refunds go back through
+	// the gateway.
+	shiftColumns = `
+			s.id,
+			s.merchant_id,
+			s.outlet_id,
+			s.user_id,
+			s.opening_float,
+			CASE WHEN s.closed_at IS NULL THEN
+				s.opening_float
+				+ COALESCE((
+					SELECT SUM(p.amount) FROM "Payment" p
+					JOIN "Sale" sa ON sa.id = p.sale_id
+					WHERE sa.shift_id = s.id AND p.method = 'cash'
+				), 0)
+				- COALESCE((SELECT SUM(change_due) FROM "Sale" WHERE shift_id = s.id), 0)
+				- COALESCE((
+					SELECT SUM(rp.amount) FROM "RefundPayment" rp
+					JOIN "Refund" r ON r.id = rp.refund_id
+					WHERE r.shift_id = s.id AND rp.method = 'cash'
+				), 0)
+			ELSE s.expected_cash END AS expected_cash,
+			s.counted_cash,
+			s.difference,
+			s.note,
+			s.opened_at,
+			s.closed_at
+	`
+
+	insertShift = `
+		INSERT INTO public."Shift" (merchant_id, outlet_id, user_id, opening_float, opened_at)
+		VALUES($1, $2, $3, $4, $5) RETURNING id;
+	`
+
+	getShiftsByOutletID = `
+		SELECT` + shiftColumns + `
+		FROM "Shift" s
+		WHERE s.merchant_id = $1 AND s.outlet_id = $2 AND s.id > $3
+		ORDER BY s.id
+		LIMIT $4
+	`
+
+	countAllShiftsInOutletID = `
+		SELECT COUNT(id) as "totalShifts" FROM "Shift" WHERE merchant_id = $1 AND outlet_id = $2
+	`
+
+	getShift = `
+		SELECT` + shiftColumns + `
+		FROM "Shift" s
+		WHERE s.id = $1 AND s.merchant_id = $2 LIMIT 1
+	`
+
+	findOpenShift = `
+		SELECT` + shiftColumns + `
+		FROM "Shift" s
+		WHERE s.merchant_id = $1 AND s.outlet_id = $2 AND s.user_id = $3 AND s.closed_at IS NULL LIMIT 1
+	`
+
+	lockShift = `
+		SELECT id FROM "Shift"
+		WHERE id = $1 AND merchant_id = $2
+		FOR UPDATE
+	`
+
+	closeShift = `
+		UPDATE "Shift"
+		SET expected_cash = $1, counted_cash = $2, difference = $3, note = $4, closed_at = $5
+		WHERE id = $6;
+	`
+)
@@ -0,0 +1,278 @@
+package twofactor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/twofactor"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) GetEnrollment(ctx context.Context, userID int) (entity twofactor.Enrollment, err error) {
+	const ops = "repository.twofactor.GetEnrollment"
+
+	err = r.db.QueryRowContext(ctx, getEnrollment, userID).Scan(
+		&entity.UserID,
+		&entity.Secret,
+		&entity.LastUsedStep,
+		&entity.CreatedAt,
+		&entity.EnabledAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = twofactor.ErrNotEnrolled
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+// Enroll stores a new, not yet enabled secret and replaces the user's
+// recovery codes. An enabled enrollment is left untouched; it has to be
+// disabled first.
+func (r *repository) Enroll(ctx context.Context, entity twofactor.Enrollment, recoveryCodeHashes []string) (err error) {
+	const ops = "repository.twofactor.Enroll"
+	var tx *sql.Tx
+	var res sql.Result
+	var rowsAffected int64
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	res, err = tx.ExecContext(ctx, upsertEnrollment, entity.UserID, entity.Secret, entity.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error storing enrollment: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return twofactor.ErrAlreadyEnabled
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteRecoveryCodes, entity.UserID); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error deleting recovery codes: %v", err)
+		return
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		if _, err = tx.ExecContext(ctx, insertRecoveryCode, entity.UserID, codeHash); err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error inserting recovery code: %v", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	return
+}
+
+// Enable turns on a pending enrollment with the time step of the code that
+// confirmed it.
+func (r *repository) Enable(ctx context.Context, userID int, step int64) (err error) {
+	const ops = "repository.twofactor.Enable"
+
+	result, err := r.db.ExecContext(ctx, enableEnrollment, time.Now(), step, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(ctx, ops, "error result.RowsAffected: %v", err)
+		return
+	}
+
+	if affected == 0 {
+		return twofactor.ErrNotEnrolled
+	}
+
+	return
+}
+
+// UseStep records step as spent. It fails with ErrInvalidCode when step is
+// not newer than the last accepted one, which stops a code being replayed.
+func (r *repository) UseStep(ctx context.Context, userID int, step int64) (err error) {
+	const ops = "repository.twofactor.UseStep"
+
+	result, err := r.db.ExecContext(ctx, useStep, step, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(ctx, ops, "error result.RowsAffected: %v", err)
+		return
+	}
+
+	if affected == 0 {
+		return twofactor.ErrInvalidCode
+	}
+
+	return
+}
+
+func (r *repository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (err error) {
+	const ops = "repository.twofactor.UseRecoveryCode"
+
+	result, err := r.db.ExecContext(ctx, useRecoveryCode, time.Now(), userID, codeHash)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(ctx, ops, "error result.RowsAffected: %v", err)
+		return
+	}
+
+	if affected == 0 {
+		return twofactor.ErrInvalidCode
+	}
+
+	return
+}
+
+func (r *repository) Disable(ctx context.Context, userID int) (err error) {
+	const ops = "repository.twofactor.Disable"
+	var tx *sql.Tx
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteRecoveryCodes, userID); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error deleting recovery codes: %v", err)
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteEnrollment, userID); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error deleting enrollment: %v", err)
+		return
+	}
+
+	err = tx.Commit()
+	return
+}
+
+// CreateChallenge stores a new challenge and expires the oldest open ones of
+// the user, so no more than MaxOpenChallenges can be answered at once.
+func (r *repository) CreateChallenge(ctx context.Context, entity twofactor.Challenge) (err error) {
+	const ops = "repository.twofactor.CreateChallenge"
+
+	_, err = r.db.ExecContext(
+		ctx,
+		insertChallenge,
+		entity.UserID,
+		entity.MerchantID,
+		entity.TokenHash,
+		entity.CreatedAt,
+		entity.ExpiresAt,
+		twofactor.MaxOpenChallenges-1,
+	)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+// GetChallenge returns the challenge only while it can still be answered:
+// unused, unexpired and below the attempt limit.
+func (r *repository) GetChallenge(ctx context.Context, tokenHash string) (entity twofactor.Challenge, err error) {
+	const ops = "repository.twofactor.GetChallenge"
+
+	err = r.db.QueryRowContext(ctx, getChallenge, tokenHash, time.Now(), twofactor.MaxChallengeAttempts).Scan(
+		&entity.ID,
+		&entity.UserID,
+		&entity.MerchantID,
+		&entity.TokenHash,
+		&entity.FailedAttempts,
+		&entity.CreatedAt,
+		&entity.ExpiresAt,
+		&entity.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = twofactor.ErrInvalidChallenge
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) RecordChallengeFailure(ctx context.Context, challengeID int) (err error) {
+	const ops = "repository.twofactor.RecordChallengeFailure"
+
+	_, err = r.db.ExecContext(ctx, recordChallengeFailure, challengeID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+// RedeemChallenge marks the challenge used. Of two requests answering the
+// same challenge only one gets through.
+func (r *repository) RedeemChallenge(ctx context.Context, challengeID int) (err error) {
+	const ops = "repository.twofactor.RedeemChallenge"
+
+	result, err := r.db.ExecContext(ctx, redeemChallenge, time.Now(), challengeID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.Error(ctx, ops, "error result.RowsAffected: %v", err)
+		return
+	}
+
+	if affected == 0 {
+		return twofactor.ErrInvalidChallenge
+	}
+
+	return
+}
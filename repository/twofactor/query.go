@@ -0,0 +1,83 @@
+package twofactor
+
+var (
+	getEnrollment = `
+		SELECT "user_id", "secret", "last_used_step", "created_at", "enabled_at"
+		FROM "TwoFactor"
+		WHERE "user_id" = $1;
+	`
+
+	upsertEnrollment = `
+		INSERT INTO public."TwoFactor" (user_id, secret, last_used_step, created_at)
+		VALUES($1, $2, 0, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = EXCLUDED.created_at
+		WHERE "TwoFactor".enabled_at IS NULL;
+	`
+
+	deleteRecoveryCodes = `
+		DELETE FROM "RecoveryCode"
+		WHERE user_id = $1;
+	`
+
+	insertRecoveryCode = `
+		INSERT INTO public."RecoveryCode" (user_id, code_hash)
+		VALUES($1, $2);
+	`
+
+	enableEnrollment = `
+		UPDATE "TwoFactor"
+		SET enabled_at = $1, last_used_step = $2
+		WHERE user_id = $3 AND enabled_at IS NULL;
+	`
+
+	useStep = `
+		UPDATE "TwoFactor"
+		SET last_used_step = $1
+		WHERE user_id = $2 AND enabled_at IS NOT NULL AND last_used_step < $1;
+	`
+
+	useRecoveryCode = `
+		UPDATE "RecoveryCode"
+		SET used_at = $1
+		WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL;
+	`
+
+	deleteEnrollment = `
+		DELETE FROM "TwoFactor"
+		WHERE user_id = $1;
+	`
+
+	insertChallenge = `
+		WITH expired AS (
+			UPDATE "LoginChallenge"
+			SET expires_at = $4
+			WHERE id IN (
+				SELECT id FROM "LoginChallenge"
+				WHERE user_id = $1 AND used_at IS NULL AND expires_at > $4
+				ORDER BY created_at DESC, id DESC
+				OFFSET $6
+			)
+		)
+		INSERT INTO public."LoginChallenge" (user_id, merchant_id, token_hash, created_at, expires_at)
+		VALUES($1, $2, $3, $4, $5);
+	`
+
+	getChallenge = `
+		SELECT "id", "user_id", "merchant_id", "token_hash", "failed_attempts", "created_at", "expires_at", "used_at"
+		FROM "LoginChallenge"
+		WHERE "token_hash" = $1 AND "used_at" IS NULL AND "expires_at" > $2 AND "failed_attempts" < $3;
+	`
+
+	recordChallengeFailure = `
+		UPDATE "LoginChallenge"
+		SET failed_attempts = failed_attempts + 1
+		WHERE id = $1;
+	`
+
+	redeemChallenge = `
+		UPDATE "LoginChallenge"
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL;
+	`
+)
@@ -5,12 +5,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/POS/database"
+	"github.com/mhdiiilham/POS/entity/role"
 	"github.com/mhdiiilham/POS/entity/user"
 	"github.com/mhdiiilham/POS/pkg/logger"
 )
 
+const uniqueViolation pq.ErrorCode = "23505"
+
 type repository struct {
 	db *sql.DB
 }
@@ -24,15 +30,20 @@ func NewRepository(db *sql.DB) *repository {
 func (r *repository) FindUserByEmail(ctx context.Context, email string) (*user.User, error) {
 	const ops = "repository.user.FindUserByEmail"
 	var entity user.User
+	var permissions []string
 
 	row := r.db.QueryRowContext(ctx, findUserByEmail, email)
 	err := row.Scan(
 		&entity.ID,
 		&entity.MerchantID,
+		&entity.RoleID,
+		&entity.Role,
+		pq.Array(&permissions),
 		&entity.Email,
 		&entity.Password,
 		&entity.FirstName,
 		&entity.LastName,
+		&entity.Pending,
 		&entity.CreatedAt,
 		&entity.UpdatedAt,
 		&entity.DeletedAt,
@@ -41,21 +52,18 @@ func (r *repository) FindUserByEmail(ctx context.Context, email string) (*user.U
 		logger.Error(ctx, ops, "trying to find user by email err: %v", err)
 		return nil, err
 	}
+
+	entity.Permissions = toPermissions(permissions)
 	return &entity, nil
 }
 
+// Create inserts a user, inside the caller's transaction when there is one.
 func (r *repository) Create(ctx context.Context, entity user.User) (id int64, err error) {
 	const ops = "repository.user.Create"
 	now := time.Now()
 
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		logger.Error(ctx, ops, "error trying to begin transaction %v", err)
-		return
-	}
-
 	logger.Info(ctx, ops, "creating new user")
-	err = tx.QueryRowContext(
+	err = database.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		insertUser,
 		entity.Email,
@@ -63,39 +71,65 @@ func (r *repository) Create(ctx context.Context, entity user.User) (id int64, er
 		entity.LastName,
 		entity.Password,
 		entity.MerchantID,
+		entity.RoleID,
 		now,
 	).Scan(&id)
 	if err != nil {
-		tx.Rollback()
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, user.ErrEmailNotUnique
+		}
+
 		logger.Error(ctx, ops, "error trying to insert to db: %v", err)
 		return
 	}
 
-	tx.Commit()
 	return
 }
 
+// Get lists the merchant's users matching opts.Filter, ordered by opts.Sort
+// and starting after opts.After. totalData counts every matching user.
+// Values are always passed as parameters; only placeholder numbers and
+// columns from sortColumns are formatted into the query.
 func (r *repository) Get(ctx context.Context, merchantID int, opts *user.RepositoryGetUserPaginationOptions) (users []user.User, totalData int, err error) {
 	const ops = "user.repository.Get"
-	total := struct {
-		totalUser int64 `db:"totalUsers"`
-	}{}
-
-	logger.Info(ctx, ops, "get users of merchant %d", merchantID)
-	query := getUserByMerchantID
+	var limit interface{}
 
-	if opts != nil {
-		query = fmt.Sprintf(`%s AND "User".id > %d LIMIT %d`, getUserByMerchantID, opts.Cursor, opts.Limit)
+	if opts == nil {
+		opts = &user.RepositoryGetUserPaginationOptions{}
 	}
 
-	row := r.db.QueryRowContext(ctx, countAllUsersInMerchantID, merchantID)
-	err = row.Scan(&total.totalUser)
+	logger.Info(ctx, ops, "get users of merchant %d", merchantID)
+	where, args := userFilter(merchantID, opts.Filter)
+
+	err = r.db.QueryRowContext(ctx, countAllUsersInMerchantID+where, args...).Scan(&totalData)
 	if err != nil {
 		logger.Error(ctx, ops, "unexpected error %v", err)
 		return
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, merchantID)
+	column, ok := sortColumns[opts.Sort.Field]
+	if !ok {
+		column = sortColumns[user.SortByID]
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Sort.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if opts.After != nil {
+		args = append(args, opts.After.Value, opts.After.ID)
+		where += fmt.Sprintf(usersAfter, column, comparison, len(args)-1, len(args))
+	}
+
+	if opts.Limit > 0 {
+		limit = opts.Limit
+	}
+	args = append(args, limit)
+	query := getUserByMerchantID + where + fmt.Sprintf(orderUsers, column, direction, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Error(ctx, ops, "unexpected error: %v", err)
 		return
@@ -105,27 +139,72 @@ func (r *repository) Get(ctx context.Context, merchantID int, opts *user.Reposit
 	logger.Info(ctx, ops, "scanning get users result rows")
 	for rows.Next() {
 		var u user.User
-		errScan := rows.Scan(
+		err = rows.Scan(
 			&u.ID,
 			&u.MerchantID,
+			&u.RoleID,
+			&u.Role,
 			&u.Email,
 			&u.FirstName,
 			&u.LastName,
+			&u.Pending,
+			&u.CreatedAt,
+			&u.DeletedAt,
 		)
-		if errScan != nil {
+		if err != nil {
 			logger.Error(ctx, ops, "unexpected error while scanning rows %v", err)
-			err = errScan
 			return
 		}
 		users = append(users, u)
 	}
 	logger.Info(ctx, ops, "scanning rows completed")
 
-	totalData = int(total.totalUser)
+	err = rows.Err()
 	return
 }
 
-func (r *repository) Remove(ctx context.Context, userID int) (err error) {
+// userFilter returns the conditions of filter, to be appended to a query
+// whose $1 is the merchant ID, with their arguments.
+func userFilter(merchantID int, filter user.Filter) (where string, args []interface{}) {
+	args = []interface{}{merchantID}
+
+	where = activeUsers
+	if filter.Status == user.StatusDeleted {
+		where = deletedUsers
+	}
+
+	if filter.Search != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Search)+"%")
+		where += fmt.Sprintf(searchUsers, len(args))
+	}
+
+	if filter.RoleID != 0 {
+		args = append(args, filter.RoleID)
+		where += fmt.Sprintf(usersWithRole, len(args))
+	}
+
+	if filter.OutletID != 0 {
+		args = append(args, filter.OutletID)
+		where += fmt.Sprintf(usersOfOutlet, len(args))
+	}
+
+	if filter.CreatedFrom != nil {
+		args = append(args, *filter.CreatedFrom)
+		where += fmt.Sprintf(usersCreatedFrom, len(args))
+	}
+
+	if filter.CreatedTo != nil {
+		args = append(args, *filter.CreatedTo)
+		where += fmt.Sprintf(usersCreatedBefore, len(args))
+	}
+
+	return where, args
+}
+
+// likeEscaper makes a search term match literally inside ILIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *repository) Remove(ctx context.Context, merchantID, userID int) (err error) {
 	const ops = "repository.user.Remove"
 	var tx *sql.Tx
 	var res sql.Result
@@ -137,7 +216,7 @@ func (r *repository) Remove(ctx context.Context, userID int) (err error) {
 		return
 	}
 
-	res, err = tx.ExecContext(ctx, deleteUserFromID, time.Now(), userID)
+	res, err = tx.ExecContext(ctx, deleteUserFromID, time.Now(), userID, merchantID)
 	if err != nil {
 		tx.Rollback()
 		return
@@ -158,15 +237,31 @@ func (r *repository) Remove(ctx context.Context, userID int) (err error) {
 	return tx.Commit()
 }
 
-func (r *repository) GetUser(ctx context.Context, userID int) (entity user.User, err error) {
+func (r *repository) GetUser(ctx context.Context, merchantID, userID int) (entity user.User, err error) {
 	const ops = "repository.user.GetUser"
+	return r.getUser(ctx, ops, getUser, merchantID, userID)
+}
 
-	err = r.db.QueryRowContext(ctx, getUser, userID).Scan(
+// GetRemovedUser returns a removed user that has not been purged yet.
+func (r *repository) GetRemovedUser(ctx context.Context, merchantID, userID int) (entity user.User, err error) {
+	const ops = "repository.user.GetRemovedUser"
+	return r.getUser(ctx, ops, getRemovedUser, merchantID, userID)
+}
+
+func (r *repository) getUser(ctx context.Context, ops, query string, merchantID, userID int) (entity user.User, err error) {
+	var permissions []string
+
+	err = r.db.QueryRowContext(ctx, query, userID, merchantID).Scan(
 		&entity.ID,
 		&entity.MerchantID,
+		&entity.RoleID,
+		&entity.Role,
+		pq.Array(&permissions),
 		&entity.Email,
+		&entity.Password,
 		&entity.FirstName,
 		&entity.LastName,
+		&entity.Pending,
 		&entity.CreatedAt,
 		&entity.UpdatedAt,
 		&entity.DeletedAt,
@@ -182,5 +277,264 @@ func (r *repository) GetUser(ctx context.Context, userID int) (entity user.User,
 		return
 	}
 
+	entity.Permissions = toPermissions(permissions)
+	return
+}
+
+func (r *repository) UpdateRole(ctx context.Context, merchantID, userID, roleID int) (err error) {
+	const ops = "repository.user.UpdateRole"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, updateUserRole, roleID, time.Now(), userID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
 	return
 }
+
+func (r *repository) Update(ctx context.Context, entity user.User) (err error) {
+	const ops = "repository.user.Update"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(
+		ctx,
+		updateUser,
+		entity.Email,
+		entity.FirstName,
+		entity.LastName,
+		entity.UpdatedAt,
+		entity.ID,
+		entity.MerchantID,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return user.ErrEmailNotUnique
+		}
+
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return
+}
+
+func (r *repository) UpdatePassword(ctx context.Context, merchantID, userID int, hashedPassword string) (err error) {
+	const ops = "repository.user.UpdatePassword"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, updateUserPassword, hashedPassword, time.Now(), userID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return
+}
+
+// GetOutletUser returns the user with their PIN state, provided the outlet
+// belongs to the user's merchant.
+func (r *repository) GetOutletUser(ctx context.Context, outletID, userID int) (entity user.User, err error) {
+	const ops = "repository.user.GetOutletUser"
+	var permissions []string
+	var pin sql.NullString
+
+	err = r.db.QueryRowContext(ctx, getOutletUser, userID, outletID).Scan(
+		&entity.ID,
+		&entity.MerchantID,
+		&entity.RoleID,
+		&entity.Role,
+		pq.Array(&permissions),
+		&entity.Email,
+		&pin,
+		&entity.PINAttempts,
+		&entity.PINLockedUntil,
+		&entity.FirstName,
+		&entity.LastName,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = user.ErrUserNotFound
+			return
+		}
+
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	entity.PIN = pin.String
+	entity.Permissions = toPermissions(permissions)
+	return
+}
+
+func (r *repository) SetPIN(ctx context.Context, merchantID, userID int, hashedPIN string) (err error) {
+	const ops = "repository.user.SetPIN"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, updateUserPIN, hashedPIN, time.Now(), userID, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return
+}
+
+// RecordPINFailure counts a wrong PIN. The MaxPINAttempts-th failure in a row
+// locks PIN login for PINLockout and each one after doubles the lock, up to
+// PINLockoutMax; the returned time is when the current lock, if any, ends.
+func (r *repository) RecordPINFailure(ctx context.Context, userID int) (lockedUntil *time.Time, err error) {
+	const ops = "repository.user.RecordPINFailure"
+
+	err = r.db.QueryRowContext(ctx, recordPINFailure, user.MaxPINAttempts, time.Now(), user.PINLockout.Seconds(), user.PINLockoutMax.Seconds(), userID).Scan(&lockedUntil)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.QueryRowContext %v", err)
+		return
+	}
+
+	return
+}
+
+func (r *repository) ResetPINFailures(ctx context.Context, userID int) (err error) {
+	const ops = "repository.user.ResetPINFailures"
+
+	_, err = r.db.ExecContext(ctx, resetPINFailures, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	return
+}
+
+func toPermissions(values []string) []role.Permission {
+	permissions := make([]role.Permission, 0, len(values))
+	for _, v := range values {
+		permissions = append(permissions, role.Permission(v))
+	}
+	return permissions
+}
+
+// Restore undoes Remove. It fails with ErrEmailNotUnique when another active
+// user has taken the email since.
+func (r *repository) Restore(ctx context.Context, merchantID, userID int) (err error) {
+	const ops = "repository.user.Restore"
+	var res sql.Result
+	var rowsAffected int64
+
+	res, err = r.db.ExecContext(ctx, restoreUser, time.Now(), userID, merchantID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return user.ErrEmailNotUnique
+		}
+
+		logger.Error(ctx, ops, "error r.db.ExecContext: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return
+}
+
+// Purge erases the personal data of a removed user for good. The row itself
+// is kept, anonymized, because sales and shifts refer to it; the user's
+// sessions, two-factor secrets and pending tokens are deleted and the API
+// keys they created are revoked.
+func (r *repository) Purge(ctx context.Context, merchantID, userID int) (err error) {
+	const ops = "repository.user.Purge"
+	var tx *sql.Tx
+	var res sql.Result
+	var rowsAffected int64
+	now := time.Now()
+
+	tx, err = r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to begin db tx: %v", err)
+		return
+	}
+
+	res, err = tx.ExecContext(ctx, anonymizeUser, now, userID, merchantID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error anonymizing user: %v", err)
+		return
+	}
+
+	rowsAffected, err = res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return user.ErrUserNotFound
+	}
+
+	for _, query := range purgeUserData {
+		if _, err = tx.ExecContext(ctx, query, userID); err != nil {
+			tx.Rollback()
+			logger.Error(ctx, ops, "error erasing user data: %v", err)
+			return
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, revokeUserAPIKeys, now, userID); err != nil {
+		tx.Rollback()
+		logger.Error(ctx, ops, "error revoking API keys: %v", err)
+		return
+	}
+
+	return tx.Commit()
+}
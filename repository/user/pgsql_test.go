@@ -0,0 +1,69 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GetUser and Remove must bind the caller's merchant_id, so a user of another
+// merchant is not found.
+func TestRepository_MerchantScope(t *testing.T) {
+	const merchantID, userID = 3, 15
+
+	testCases := []struct {
+		name   string
+		expect func(m sqlmock.Sqlmock)
+		call   func(ctx context.Context, r *repository) error
+		err    error
+	}{
+		{
+			name: "GetUser",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(getUser).
+					WithArgs(userID, merchantID).
+					WillReturnError(sql.ErrNoRows)
+			},
+			call: func(ctx context.Context, r *repository) error {
+				_, err := r.GetUser(ctx, merchantID, userID)
+				return err
+			},
+			err: user.ErrUserNotFound,
+		},
+		{
+			name: "Remove",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec(deleteUserFromID).
+					WithArgs(sqlmock.AnyArg(), userID, merchantID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+			call: func(ctx context.Context, r *repository) error {
+				return r.Remove(ctx, merchantID, userID)
+			},
+			err: sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, m, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			tc.expect(m)
+			err = tc.call(context.Background(), NewRepository(db))
+			assert.ErrorIs(t, err, tc.err)
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
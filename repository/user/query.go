@@ -1,58 +1,216 @@
 package user
 
+import "github.com/mhdiiilham/POS/entity/user"
+
 var (
 	findUserByEmail = `
 		SELECT
-			id,
-			merchant_id,
+			"User".id,
+			"User".merchant_id,
+			"User".role_id,
+			r."name",
+			r.permissions,
 			email,
 			password,
 			firstname,
 			lastname,
-			created_at,
-			updated_at,
-			deleted_at
+			"User".pending,
+			"User".created_at,
+			"User".updated_at,
+			"User".deleted_at
 		FROM "User"
-		Where "email"=$1 AND "deleted_at" IS NULL LIMIT 1
+		JOIN "Role" r ON r.id = "User".role_id
+		Where "email"=$1 AND "User"."deleted_at" IS NULL LIMIT 1
 	`
 
 	insertUser = `
-		INSERT INTO public."User" (email, firstname, lastname, "password", merchant_id, created_at, updated_at, deleted_at)
-		VALUES($1, $2, $3, $4, $5, $6, $6, null) RETURNING id;
+		INSERT INTO public."User" (email, firstname, lastname, "password", merchant_id, role_id, created_at, updated_at, deleted_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $7, null) RETURNING id;
 	`
 
 	getUserByMerchantID = `
 		SELECT
-			id,
-			merchant_id,
+			"User".id,
+			"User".merchant_id,
+			"User".role_id,
+			r."name",
 			email,
 			firstname,
-			lastname
+			lastname,
+			"User".pending,
+			"User".created_at,
+			"User".deleted_at
 		FROM "User"
-		WHERE "merchant_id" = $1
+		JOIN "Role" r ON r.id = "User".role_id
+		WHERE "User"."merchant_id" = $1
 	`
 
 	countAllUsersInMerchantID = `
-		SELECT COUNT(id) as "totalUsers" FROM "User" Where "merchant_id" = $1
+		SELECT COUNT("User".id) as "totalUsers" FROM "User" WHERE "User"."merchant_id" = $1
 	`
 
 	deleteUserFromID = `
 		UPDATE "User"
 		SET "deleted_at" = $1
-		WHERE id = $2;
+		WHERE id = $2 AND "merchant_id" = $3 AND "deleted_at" IS NULL;
 	`
 
 	getUser = `
 		SELECT
-		id,
-		merchant_id,
+		"User".id,
+		"User".merchant_id,
+		"User".role_id,
+		r."name",
+		r.permissions,
 		email,
+		password,
 		firstname,
 		lastname,
-		created_at,
-		updated_at,
-		deleted_at
+		"User".pending,
+		"User".created_at,
+		"User".updated_at,
+		"User".deleted_at
+	FROM "User"
+	JOIN "Role" r ON r.id = "User".role_id
+	Where "User".id = $1 AND "User".merchant_id = $2 AND "User"."deleted_at" IS NULL LIMIT 1
+	`
+
+	getRemovedUser = `
+		SELECT
+		"User".id,
+		"User".merchant_id,
+		"User".role_id,
+		r."name",
+		r.permissions,
+		email,
+		password,
+		firstname,
+		lastname,
+		"User".pending,
+		"User".created_at,
+		"User".updated_at,
+		"User".deleted_at
+	FROM "User"
+	JOIN "Role" r ON r.id = "User".role_id
+	Where "User".id = $1 AND "User".merchant_id = $2 AND "User"."deleted_at" IS NOT NULL AND "User"."purged_at" IS NULL LIMIT 1
+	`
+
+	updateUserRole = `
+		UPDATE "User"
+		SET role_id = $1, updated_at = $2
+		WHERE id = $3 AND "merchant_id" = $4 AND "deleted_at" IS NULL;
+	`
+
+	updateUser = `
+		UPDATE "User"
+		SET email = $1, firstname = $2, lastname = $3, updated_at = $4
+		WHERE id = $5 AND "merchant_id" = $6 AND "deleted_at" IS NULL;
+	`
+
+	getOutletUser = `
+		SELECT
+		"User".id,
+		"User".merchant_id,
+		"User".role_id,
+		r."name",
+		r.permissions,
+		email,
+		pin_hash,
+		pin_failed_attempts,
+		pin_locked_until,
+		firstname,
+		lastname
 	FROM "User"
-	Where id = $1 AND "deleted_at" IS NULL LIMIT 1
+	JOIN "Role" r ON r.id = "User".role_id
+	JOIN "Outlet" o ON o.merchant_id = "User".merchant_id AND o."deleted_at" IS NULL
+	Where "User".id = $1 AND o.id = $2 AND "User"."deleted_at" IS NULL LIMIT 1
+	`
+
+	updateUserPIN = `
+		UPDATE "User"
+		SET pin_hash = $1, pin_failed_attempts = 0, pin_locked_until = null, updated_at = $2
+		WHERE id = $3 AND "merchant_id" = $4 AND "deleted_at" IS NULL;
+	`
+
+	recordPINFailure = `
+		UPDATE "User"
+		SET
+			pin_failed_attempts = pin_failed_attempts + 1,
+			pin_locked_until = CASE
+				WHEN pin_failed_attempts + 1 < $1 THEN pin_locked_until
+				ELSE $2::timestamp + LEAST($3 * power(2, LEAST(pin_failed_attempts + 1 - $1, 16)), $4) * interval '1 second'
+			END
+		WHERE id = $5
+		RETURNING pin_locked_until;
+	`
+
+	resetPINFailures = `
+		UPDATE "User"
+		SET pin_failed_attempts = 0, pin_locked_until = null
+		WHERE id = $1;
+	`
+
+	updateUserPassword = `
+		UPDATE "User"
+		SET "password" = $1, updated_at = $2
+		WHERE id = $3 AND "merchant_id" = $4 AND "deleted_at" IS NULL;
+	`
+
+	activeUsers = ` AND "User"."deleted_at" IS NULL`
+
+	deletedUsers = ` AND "User"."deleted_at" IS NOT NULL AND "User"."purged_at" IS NULL`
+
+	// The filters below take the number of their placeholder.
+	searchUsers = ` AND (("User".firstname || ' ' || COALESCE("User".lastname, '')) ILIKE $%[1]d OR "User".email ILIKE $%[1]d)`
+
+	usersWithRole = ` AND "User".role_id = $%d`
+
+	usersOfOutlet = ` AND EXISTS (SELECT 1 FROM "Shift" s WHERE s.user_id = "User".id AND s.outlet_id = $%d)`
+
+	usersCreatedFrom = ` AND "User".created_at >= $%d`
+
+	usersCreatedBefore = ` AND "User".created_at < $%d`
+
+	usersAfter = ` AND (%s, "User".id) %s ($%d, $%d)`
+
+	orderUsers = ` ORDER BY %[1]s %[2]s, "User".id %[2]s LIMIT $%[3]d`
+
+	// sortColumns are the only expressions a listing can be ordered by.
+	sortColumns = map[user.SortField]string{
+		user.SortByID:        `"User".id`,
+		user.SortByFirstName: `"User".firstname`,
+		user.SortByLastName:  `COALESCE("User".lastname, '')`,
+		user.SortByEmail:     `"User".email`,
+		user.SortByCreatedAt: `"User".created_at`,
+	}
+
+	restoreUser = `
+		UPDATE "User"
+		SET deleted_at = null, updated_at = $1
+		WHERE id = $2 AND "merchant_id" = $3 AND "deleted_at" IS NOT NULL AND "purged_at" IS NULL;
+	`
+
+	anonymizeUser = `
+		UPDATE "User"
+		SET email = '', firstname = '', lastname = null, "password" = '', pin_hash = null,
+			pin_failed_attempts = 0, pin_locked_until = null, pending = false, purged_at = $1, updated_at = $1
+		WHERE id = $2 AND "merchant_id" = $3 AND "deleted_at" IS NOT NULL AND "purged_at" IS NULL;
+	`
+
+	// purgeUserData erases what a purged user leaves behind besides the
+	// anonymized row, which sales, shifts and stock movements still point to.
+	purgeUserData = []string{
+		`DELETE FROM "Session" WHERE user_id = $1;`,
+		`DELETE FROM "RecoveryCode" WHERE user_id = $1;`,
+		`DELETE FROM "LoginChallenge" WHERE user_id = $1;`,
+		`DELETE FROM "TwoFactor" WHERE user_id = $1;`,
+		`DELETE FROM "PasswordReset" WHERE user_id = $1;`,
+		`DELETE FROM "Invitation" WHERE user_id = $1;`,
+	}
+
+	revokeUserAPIKeys = `
+		UPDATE "APIKey"
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE created_by = $2;
 	`
 )
@@ -4,65 +4,208 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/twofactor"
 	"github.com/mhdiiilham/POS/entity/user"
 	"github.com/mhdiiilham/POS/pkg/logger"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type apiService struct {
-	userRepository user.Repository
-	hasher         Hasher
-	tokenSigner    TokenSigner
+	userRepository      user.Repository
+	sessionRepository   session.Repository
+	roleRepository      role.Repository
+	twoFactorRepository twofactor.Repository
+	hasher              Hasher
+	passwordPolicy      PasswordPolicy
+	tokenSigner         TokenSigner
+	loginAttempts       LoginAttemptStore
 }
 
-func NewAPIService(userRepository user.Repository, pwdHasher Hasher, tokenSigner TokenSigner) *apiService {
+func NewAPIService(userRepository user.Repository, sessionRepository session.Repository, roleRepository role.Repository, twoFactorRepository twofactor.Repository, pwdHasher Hasher, passwordPolicy PasswordPolicy, tokenSigner TokenSigner, loginAttempts LoginAttemptStore) *apiService {
 	return &apiService{
-		userRepository: userRepository,
-		hasher:         pwdHasher,
-		tokenSigner:    tokenSigner,
+		userRepository:      userRepository,
+		sessionRepository:   sessionRepository,
+		roleRepository:      roleRepository,
+		twoFactorRepository: twoFactorRepository,
+		hasher:              pwdHasher,
+		passwordPolicy:      passwordPolicy,
+		tokenSigner:         tokenSigner,
+		loginAttempts:       loginAttempts,
 	}
 }
 
-func (s *apiService) Login(ctx context.Context, email, password string) (accessToken string, err error) {
+// Login checks the password and starts a session. Users with two-factor
+// authentication enabled, or whose role requires it, get a challenge instead
+// of a token; they finish the login with a code.
+//
+// Logins are counted per account and per client IP as they start, and
+// either one backing off answers with a LockoutError before the password is
+// checked. Only wrong credentials keep their count; unknown emails count
+// too, so the lockout does not reveal which accounts exist.
+func (s *apiService) Login(ctx context.Context, email, password, clientIP string) (token session.Token, challenge *twofactor.LoginChallenge, err error) {
 	const ops = "service.user.Login"
+	var enrollment twofactor.Enrollment
+	now := time.Now()
+	account := attemptLimit{loginAccountKey(email), user.AccountFreeLoginAttempts}
+	ip := attemptLimit{"ip:" + clientIP, user.IPFreeLoginAttempts}
+
+	if err = takeAttempts(ctx, s.loginAttempts, now, user.LoginAttemptWindow, account, ip); err != nil {
+		var lockout *user.LockoutError
+		if !errors.As(err, &lockout) {
+			logger.Error(ctx, ops, "error counting login attempt: %v", err)
+		}
+		return session.Token{}, nil, err
+	}
+
+	// Attempts that get past the credentials are handed back. Only the
+	// account count is ever reset, below once no second factor is needed or
+	// else by VerifyLogin: logging into your own account must not clear the
+	// failures of guessing at others from the same address.
+	defer func() {
+		if !errors.Is(err, user.ErrInvalidEmailAndPasword) {
+			releaseAttempts(ctx, s.loginAttempts, account, ip)
+		}
+	}()
+
 	entity, err := s.userRepository.FindUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", user.ErrInvalidEmailAndPasword
+			return session.Token{}, nil, user.ErrInvalidEmailAndPasword
 		}
 
 		logger.Error(ctx, ops, "error trying to find user by email: %v", err)
-		return "", err
+		return session.Token{}, nil, err
+	}
+
+	// Invited users have no password until they accept their invitation.
+	if entity.Pending {
+		return session.Token{}, nil, user.ErrInvalidEmailAndPasword
 	}
 
 	if err := s.hasher.ComparePassword(ctx, entity.Password, password); err != nil {
-		logger.Error(ctx, ops, "error trying to compare password %v", err)
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return "", user.ErrInvalidEmailAndPasword
+			logger.Info(ctx, ops, "wrong password for user %d", entity.ID)
+			return session.Token{}, nil, user.ErrInvalidEmailAndPasword
 		}
-		return "", err
+
+		logger.Error(ctx, ops, "error trying to compare password %v", err)
+		return session.Token{}, nil, err
+	}
+
+	// The password is only in hand at login, so this is where hashes made
+	// with outdated settings get upgraded. A failed upgrade keeps the old
+	// hash, which still works, and is tried again next time.
+	if s.hasher.NeedsRehash(entity.Password) {
+		s.rehashPassword(ctx, *entity, password)
 	}
 
-	accessToken, err = s.tokenSigner.Sign(ctx, entity.ID, entity.Email, entity.MerchantID)
+	enrollment, err = s.twoFactorRepository.GetEnrollment(ctx, entity.ID)
+	if err != nil && !errors.Is(err, twofactor.ErrNotEnrolled) {
+		logger.Error(ctx, ops, "error getting two-factor enrollment %v", err)
+		return session.Token{}, nil, err
+	}
+
+	if enrollment.Enabled() || twofactor.Required(entity.RoleID) {
+		pending, err := startChallenge(ctx, s.twoFactorRepository, *entity, !enrollment.Enabled())
+		if err != nil {
+			logger.Error(ctx, ops, "error trying to start login challenge %v", err)
+			return session.Token{}, nil, err
+		}
+		return session.Token{}, &pending, nil
+	}
+
+	if err = s.loginAttempts.Reset(ctx, account.key); err != nil {
+		logger.Error(ctx, ops, "error resetting login attempts: %v", err)
+		return session.Token{}, nil, err
+	}
+
+	token, err = startSession(ctx, s.sessionRepository, s.tokenSigner, *entity, nil)
 	if err != nil {
-		logger.Error(ctx, ops, "error trying to compare password %v", err)
-		return "", err
+		logger.Error(ctx, ops, "error trying to start session %v", err)
+		return session.Token{}, nil, err
+	}
+
+	return token, nil, nil
+}
+
+func (s *apiService) rehashPassword(ctx context.Context, entity user.User, password string) {
+	const ops = "service.apiService.rehashPassword"
+
+	hashedPwd, err := s.hasher.HashPassword(ctx, password)
+	if err != nil {
+		logger.Error(ctx, ops, "error rehashing password of user %d: %v", entity.ID, err)
+		return
 	}
 
-	return accessToken, nil
+	if err = s.userRepository.UpdatePassword(ctx, entity.MerchantID, entity.ID, hashedPwd); err != nil {
+		logger.Error(ctx, ops, "error storing rehashed password of user %d: %v", entity.ID, err)
+	}
 }
 
-func (s *apiService) CreateUser(ctx context.Context, entity user.User) (userID int, err error) {
+// UnlockUser lifts the password and PIN lockouts of a user of the merchant.
+// The grantor must hold every permission of the user, so a manager cannot
+// keep guessing an owner's password past the lockout.
+func (s *apiService) UnlockUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) (err error) {
+	const ops = "service.apiService.UnlockUser"
+
+	u, err := s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return err
+	}
+
+	if !role.Covers(grantor, u.Permissions) {
+		return role.ErrInsufficientPermissions
+	}
+
+	if err = s.loginAttempts.Reset(ctx, loginAccountKey(u.Email)); err != nil {
+		logger.Error(ctx, ops, "error resetting login attempts: %v", err)
+		return err
+	}
+
+	if err = s.userRepository.ResetPINFailures(ctx, u.ID); err != nil {
+		logger.Error(ctx, ops, "error resetting PIN failures: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func loginAccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// CreateUser adds a user to the grantor's merchant. New users are cashiers
+// unless a role is given, and the grantor may only hand out a role whose
+// permissions they hold.
+func (s *apiService) CreateUser(ctx context.Context, grantor []role.Permission, entity user.User) (userID int, err error) {
 	const ops = "service.apiService.CreateUser"
 	var hashedPwd string
 	var insertedID int64
 	var u *user.User
+	var r role.Role
 
-	if entity.Email == "" || entity.FirstName == "" || len(entity.Password) < 8 {
+	if entity.Email == "" || entity.FirstName == "" {
 		return 0, user.ErrInvalidCreateParameters
 	}
 
+	if err = s.passwordPolicy.Check(entity.Password, personalInfo(entity)...); err != nil {
+		return 0, err
+	}
+
+	if entity.RoleID == 0 {
+		entity.RoleID = role.CashierRoleID
+	}
+
 	hashedPwd, err = s.hasher.HashPassword(ctx, entity.Password)
 	if err != nil {
 		logger.Error(ctx, ops, "error when trying to hash password: %v", err)
@@ -79,6 +222,16 @@ func (s *apiService) CreateUser(ctx context.Context, entity user.User) (userID i
 		return 0, user.ErrEmailNotUnique
 	}
 
+	r, err = s.roleRepository.GetRole(ctx, entity.MerchantID, entity.RoleID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting role: %v", err)
+		return 0, err
+	}
+
+	if !role.Covers(grantor, r.Permissions) {
+		return 0, role.ErrInsufficientPermissions
+	}
+
 	entity.Password = hashedPwd
 	insertedID, err = s.userRepository.Create(ctx, entity)
 	if err != nil {
@@ -88,25 +241,87 @@ func (s *apiService) CreateUser(ctx context.Context, entity user.User) (userID i
 	return int(insertedID), nil
 }
 
-func (s *apiService) GetUsers(ctx context.Context, merchantID, lastID, limit int) (users []user.User, totalData int, err error) {
+// GetUsers lists the merchant's users matching filter in sort order, limit
+// at a time. cursor is the nextCursor of the previous page, empty for the
+// first one; nextCursor is empty on the last page. An empty status lists
+// active users and an empty sort field orders by ID.
+func (s *apiService) GetUsers(ctx context.Context, merchantID int, filter user.Filter, sort user.Sort, cursor string, limit int) (users []user.User, totalData int, nextCursor string, err error) {
 	const ops = "service.apiService.GetUsers"
+	var after *user.Cursor
+
+	if filter.Status == "" {
+		filter.Status = user.StatusActive
+	}
+
+	if !filter.Status.Valid() {
+		return nil, 0, "", user.ErrInvalidStatus
+	}
+
+	if sort.Field == "" {
+		sort.Field = user.SortByID
+	}
+
+	if !sort.Field.Valid() {
+		return nil, 0, "", user.ErrInvalidSortField
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, 0, "", user.ErrInvalidDateRange
+	}
+
+	if cursor != "" {
+		if after, err = decodeUserCursor(sort, cursor); err != nil {
+			return nil, 0, "", err
+		}
+	}
+
 	paginationOpts := user.RepositoryGetUserPaginationOptions{
-		Limit:  limit,
-		Cursor: lastID,
+		Filter: filter,
+		Sort:   sort,
+		After:  after,
+	}
+
+	// One user more than asked for tells whether there is a next page. A
+	// limit below one lists every user.
+	if limit > 0 {
+		paginationOpts.Limit = limit + 1
 	}
 
 	users, totalData, err = s.userRepository.Get(ctx, merchantID, &paginationOpts)
 	if err != nil {
 		logger.Error(ctx, ops, "unexpected error %v", err)
-		return
+		return nil, 0, "", err
 	}
 
-	return
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+		nextCursor = encodeUserCursor(sort, users[limit-1])
+	}
+
+	return users, totalData, nextCursor, nil
 }
 
-func (s *apiService) DeleteUser(ctx context.Context, userID int) error {
+// DeleteUser removes a user of the merchant. Users of other merchants are
+// reported as not found, and the grantor must hold every permission of the
+// user being removed.
+func (s *apiService) DeleteUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) error {
 	const ops = "service.apiService.DeleteUser"
-	err := s.userRepository.Remove(ctx, userID)
+
+	target, err := s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return err
+	}
+
+	if !role.Covers(grantor, target.Permissions) {
+		return role.ErrInsufficientPermissions
+	}
+
+	err = s.userRepository.Remove(ctx, merchantID, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return user.ErrUserNotFound
@@ -116,17 +331,374 @@ func (s *apiService) DeleteUser(ctx context.Context, userID int) error {
 		return err
 	}
 
+	if err = s.sessionRepository.RevokeUserSessions(ctx, userID); err != nil {
+		logger.Error(ctx, ops, "error revoking sessions of removed user %v", err)
+		return err
+	}
+
 	return nil
 }
 
-func (s *apiService) GetUser(ctx context.Context, userID int) (entity user.User, err error) {
+// RestoreUser brings back a removed user. It fails with ErrEmailNotUnique
+// when the email has been given to another user in the meantime. Purged
+// users cannot be restored, and the grantor must hold every permission of
+// the removed user.
+func (s *apiService) RestoreUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) (err error) {
+	const ops = "service.apiService.RestoreUser"
+
+	if err = s.checkRemovedUser(ctx, merchantID, grantor, userID); err != nil {
+		return err
+	}
+
+	if err = s.userRepository.Restore(ctx, merchantID, userID); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, user.ErrEmailNotUnique) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error restoring user %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// PurgeUser permanently erases the personal data of a removed user. Only
+// removed users can be purged, and it cannot be undone. As with RestoreUser
+// the grantor must hold every permission of the removed user.
+func (s *apiService) PurgeUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) (err error) {
+	const ops = "service.apiService.PurgeUser"
+
+	if err = s.checkRemovedUser(ctx, merchantID, grantor, userID); err != nil {
+		return err
+	}
+
+	if err = s.userRepository.Purge(ctx, merchantID, userID); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error purging user %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// checkRemovedUser fails unless grantor holds every permission of the
+// removed user.
+func (s *apiService) checkRemovedUser(ctx context.Context, merchantID int, grantor []role.Permission, userID int) error {
+	const ops = "service.apiService.checkRemovedUser"
+
+	target, err := s.userRepository.GetRemovedUser(ctx, merchantID, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error getting removed user: %v", err)
+		return err
+	}
+
+	if !role.Covers(grantor, target.Permissions) {
+		return role.ErrInsufficientPermissions
+	}
+
+	return nil
+}
+
+func (s *apiService) GetUser(ctx context.Context, merchantID, userID int) (entity user.User, err error) {
 	const ops = "service.apiService.GetUser"
 
-	entity, err = s.userRepository.GetUser(ctx, userID)
+	entity, err = s.userRepository.GetUser(ctx, merchantID, userID)
 	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return
+		}
+
 		logger.Error(ctx, ops, "unknown error: %v", err)
 		return
 	}
 
 	return
 }
+
+// AssignRole moves a user of the merchant to another role. The grantor must
+// hold every permission of both the user's current role and the new one, and
+// cannot change their own role. The user's sessions are revoked so the new
+// permissions apply from their next login.
+func (s *apiService) AssignRole(ctx context.Context, merchantID, grantorID int, grantor []role.Permission, userID, roleID int) (err error) {
+	const ops = "service.apiService.AssignRole"
+	var target user.User
+	var r role.Role
+
+	if userID == grantorID {
+		return role.ErrForbidden
+	}
+
+	target, err = s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return err
+	}
+
+	r, err = s.roleRepository.GetRole(ctx, merchantID, roleID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting role: %v", err)
+		return err
+	}
+
+	if !role.Covers(grantor, target.Permissions) || !role.Covers(grantor, r.Permissions) {
+		return role.ErrInsufficientPermissions
+	}
+
+	if err = s.userRepository.UpdateRole(ctx, merchantID, userID, roleID); err != nil {
+		logger.Error(ctx, ops, "error updating user role: %v", err)
+		return err
+	}
+
+	if err = s.sessionRepository.RevokeUserSessions(ctx, userID); err != nil {
+		logger.Error(ctx, ops, "error revoking sessions of user %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateUser changes the profile fields that are set. Editing another user
+// requires holding every permission they have, so a manager cannot take over
+// an owner account by changing its email. Users changing their own email must
+// confirm their current password, and an email change ends the user's
+// sessions, except the caller's own session when the change is their own.
+func (s *apiService) UpdateUser(ctx context.Context, merchantID, grantorID int, sessionID string, grantor []role.Permission, userID int, profile user.Profile) (entity user.User, err error) {
+	const ops = "service.apiService.UpdateUser"
+	var u *user.User
+	var emailChanged bool
+
+	entity, err = s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return user.User{}, err
+	}
+
+	if userID != grantorID && !role.Covers(grantor, entity.Permissions) {
+		return user.User{}, role.ErrInsufficientPermissions
+	}
+
+	if profile.FirstName != nil {
+		entity.FirstName = strings.TrimSpace(*profile.FirstName)
+	}
+
+	if profile.LastName != nil {
+		entity.LastName = profile.LastName
+	}
+
+	if profile.Email != nil && strings.TrimSpace(*profile.Email) != entity.Email {
+		if userID == grantorID {
+			if profile.CurrentPassword == nil {
+				return user.User{}, user.ErrWrongPassword
+			}
+
+			if err = s.hasher.ComparePassword(ctx, entity.Password, *profile.CurrentPassword); err != nil {
+				if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+					return user.User{}, user.ErrWrongPassword
+				}
+
+				logger.Error(ctx, ops, "error trying to compare password %v", err)
+				return user.User{}, err
+			}
+		}
+
+		emailChanged = true
+		entity.Email = strings.TrimSpace(*profile.Email)
+
+		u, err = s.userRepository.FindUserByEmail(ctx, entity.Email)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Error(ctx, ops, "unexpected error happened %v", err)
+			return user.User{}, err
+		}
+
+		if u != nil {
+			return user.User{}, user.ErrEmailNotUnique
+		}
+	}
+
+	if entity.Email == "" || entity.FirstName == "" {
+		return user.User{}, user.ErrInvalidUpdateParameters
+	}
+
+	entity.UpdatedAt = time.Now()
+	if err = s.userRepository.Update(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error updating user: %v", err)
+		return user.User{}, err
+	}
+
+	if emailChanged {
+		if userID == grantorID {
+			err = s.sessionRepository.RevokeOtherSessions(ctx, userID, sessionID)
+		} else {
+			err = s.sessionRepository.RevokeUserSessions(ctx, userID)
+		}
+
+		if err != nil {
+			logger.Error(ctx, ops, "error revoking sessions after email change %v", err)
+			return user.User{}, err
+		}
+	}
+
+	return entity, nil
+}
+
+// ChangePassword replaces the caller's password after checking the current
+// one, then ends every other session of the user so a leaked password stops
+// working everywhere but here.
+func (s *apiService) ChangePassword(ctx context.Context, merchantID, userID int, sessionID, currentPassword, newPassword string) (err error) {
+	const ops = "service.apiService.ChangePassword"
+	var entity user.User
+	var hashedPwd string
+
+	entity, err = s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return err
+	}
+
+	if err = s.passwordPolicy.Check(newPassword, personalInfo(entity)...); err != nil {
+		return err
+	}
+
+	if err = s.hasher.ComparePassword(ctx, entity.Password, currentPassword); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return user.ErrWrongPassword
+		}
+
+		logger.Error(ctx, ops, "error trying to compare password %v", err)
+		return err
+	}
+
+	hashedPwd, err = s.hasher.HashPassword(ctx, newPassword)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to hash password: %v", err)
+		return err
+	}
+
+	if err = s.userRepository.UpdatePassword(ctx, merchantID, userID, hashedPwd); err != nil {
+		logger.Error(ctx, ops, "error updating password: %v", err)
+		return err
+	}
+
+	if err = s.sessionRepository.RevokeOtherSessions(ctx, userID, sessionID); err != nil {
+		logger.Error(ctx, ops, "error revoking other sessions %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetPIN sets the caller's PIN for quick login at outlet terminals.
+func (s *apiService) SetPIN(ctx context.Context, merchantID, userID int, pin string) (err error) {
+	const ops = "service.apiService.SetPIN"
+	var hashedPIN string
+
+	if !user.ValidPIN(pin) {
+		return user.ErrInvalidPINFormat
+	}
+
+	hashedPIN, err = s.hasher.HashPassword(ctx, pin)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to hash PIN: %v", err)
+		return err
+	}
+
+	if err = s.userRepository.SetPIN(ctx, merchantID, userID, hashedPIN); err != nil {
+		logger.Error(ctx, ops, "error setting PIN: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// PINLogin signs a user in at an outlet terminal with their PIN. The session
+// is limited to that outlet and to cashier permissions, whatever the user's
+// role. PIN login locks after MaxPINAttempts wrong PINs in a row, for longer
+// with every further one, and a client IP guessing PINs across users is
+// backed off after IPFreePINAttempts.
+func (s *apiService) PINLogin(ctx context.Context, outletID, userID int, pin, clientIP string) (token session.Token, err error) {
+	const ops = "service.apiService.PINLogin"
+	var entity user.User
+	var lockedUntil *time.Time
+	now := time.Now()
+	ip := attemptLimit{"pin:ip:" + clientIP, user.IPFreePINAttempts}
+
+	if err = takeAttempts(ctx, s.loginAttempts, now, user.LoginAttemptWindow, ip); err != nil {
+		var lockout *user.LockoutError
+		if !errors.As(err, &lockout) {
+			logger.Error(ctx, ops, "error counting PIN attempt: %v", err)
+		}
+		return session.Token{}, err
+	}
+
+	// Wrong PINs and tries at a locked user keep their count against the IP.
+	defer func() {
+		if !errors.Is(err, user.ErrInvalidPIN) && !errors.Is(err, user.ErrLockedOut) {
+			releaseAttempts(ctx, s.loginAttempts, ip)
+		}
+	}()
+
+	entity, err = s.userRepository.GetOutletUser(ctx, outletID, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return session.Token{}, user.ErrInvalidPIN
+		}
+
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return session.Token{}, err
+	}
+
+	if entity.PINLockedUntil != nil && entity.PINLockedUntil.After(now) {
+		return session.Token{}, &user.LockoutError{Until: *entity.PINLockedUntil}
+	}
+
+	if entity.PIN == "" {
+		return session.Token{}, user.ErrInvalidPIN
+	}
+
+	if err = s.hasher.ComparePassword(ctx, entity.PIN, pin); err != nil {
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			logger.Error(ctx, ops, "error trying to compare PIN %v", err)
+			return session.Token{}, err
+		}
+
+		lockedUntil, err = s.userRepository.RecordPINFailure(ctx, entity.ID)
+		if err != nil {
+			logger.Error(ctx, ops, "error recording PIN failure %v", err)
+			return session.Token{}, err
+		}
+
+		if lockedUntil != nil && lockedUntil.After(now) {
+			logger.Info(ctx, ops, "PIN login of user %d locked until %s", entity.ID, lockedUntil.Format(time.RFC3339))
+			return session.Token{}, &user.LockoutError{Until: *lockedUntil}
+		}
+		return session.Token{}, user.ErrInvalidPIN
+	}
+
+	if entity.PINAttempts > 0 {
+		if err = s.userRepository.ResetPINFailures(ctx, entity.ID); err != nil {
+			logger.Error(ctx, ops, "error resetting PIN failures %v", err)
+			return session.Token{}, err
+		}
+	}
+
+	entity, err = limitToCashier(ctx, s.roleRepository, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error limiting outlet session: %v", err)
+		return session.Token{}, err
+	}
+
+	token, err = startSession(ctx, s.sessionRepository, s.tokenSigner, entity, &outletID)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to start session %v", err)
+		return session.Token{}, err
+	}
+
+	return token, nil
+}
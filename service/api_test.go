@@ -3,20 +3,67 @@ package service_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bxcodec/faker/v3"
 	"github.com/golang-jwt/jwt"
 	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/role"
+	romock "github.com/mhdiiilham/POS/entity/role/mock"
+	"github.com/mhdiiilham/POS/entity/session"
+	sesmock "github.com/mhdiiilham/POS/entity/session/mock"
+	"github.com/mhdiiilham/POS/entity/twofactor"
+	tfmock "github.com/mhdiiilham/POS/entity/twofactor/mock"
 	"github.com/mhdiiilham/POS/entity/user"
 	"github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/pkg/password"
 	"github.com/mhdiiilham/POS/service"
 	smock "github.com/mhdiiilham/POS/service/mock"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const loginIP = "203.0.113.7"
+
+// passwordPolicy only enforces the minimum length, so tests that are not
+// about the policy can use any password of eight characters or more.
+var passwordPolicy = password.Policy{}
+
+// expectLoginAllowed expects a login from loginIP with no earlier failures
+// to be counted against the account and the IP.
+func expectLoginAllowed(ctx context.Context, loginAttempts *smock.MockLoginAttemptStore, email string) {
+	loginAttempts.
+		EXPECT().
+		Take(ctx, "account:"+strings.ToLower(email), user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+		Return(user.LoginAttempts{Failures: 1}, nil).
+		Times(1)
+
+	loginAttempts.
+		EXPECT().
+		Take(ctx, "ip:"+loginIP, user.IPFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+		Return(user.LoginAttempts{Failures: 1}, nil).
+		Times(1)
+}
+
+// expectLoginReleased expects the attempt of a login that got past the
+// credentials, or failed for another reason, to be handed back.
+func expectLoginReleased(ctx context.Context, loginAttempts *smock.MockLoginAttemptStore, email string) {
+	loginAttempts.
+		EXPECT().
+		Release(ctx, "account:"+strings.ToLower(email)).
+		Return(nil).
+		Times(1)
+
+	loginAttempts.
+		EXPECT().
+		Release(ctx, "ip:"+loginIP).
+		Return(nil).
+		Times(1)
+}
+
 func Test_apiService_Login(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -31,8 +78,12 @@ func Test_apiService_Login(t *testing.T) {
 		jwt := faker.Jwt()
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
@@ -52,17 +103,149 @@ func Test_apiService_Login(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		hasher.EXPECT().
+			NeedsRehash(hashedPassword).
+			Return(false).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 1).
+			Return(twofactor.Enrollment{}, twofactor.ErrNotEnrolled).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil).Times(1)
+
 		tokenSigner.
 			EXPECT().
-			Sign(ctx, 1, email, 1).
+			Sign(ctx, gomock.Any()).
 			Return(jwt, nil).Times(1)
 
-		service := service.NewAPIService(userRepository, hasher, tokenSigner)
+		expectLoginAllowed(ctx, loginAttempts, email)
+		expectLoginReleased(ctx, loginAttempts, email)
+
+		loginAttempts.
+			EXPECT().
+			Reset(ctx, "account:"+strings.ToLower(email)).
+			Return(nil).
+			Times(1)
+
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		accessToken, err := service.Login(ctx, email, password)
+		token, _, err := service.Login(ctx, email, password, loginIP)
 		assert.NoError(t, err)
-		assert.NotEmpty(t, accessToken)
-		assert.Equal(t, jwt, accessToken)
+		assert.Equal(t, jwt, token.AccessToken)
+		assert.NotEmpty(t, token.RefreshToken)
+	})
+
+	for name, updateErr := range map[string]error{
+		"success - outdated hash is upgraded":              nil,
+		"success - failed upgrade keeps the login working": errors.New("connection reset"),
+	} {
+		updateErr := updateErr
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			email := faker.Email()
+			password := faker.Password()
+			jwt := faker.Jwt()
+
+			userRepository := mock.NewMockRepository(ctrl)
+			sessionRepository := sesmock.NewMockRepository(ctrl)
+			roleRepository := romock.NewMockRepository(ctrl)
+			twoFactorRepository := tfmock.NewMockRepository(ctrl)
+			hasher := smock.NewMockHasher(ctrl)
+			tokenSigner := smock.NewMockTokenSigner(ctrl)
+			loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+			userRepository.
+				EXPECT().
+				FindUserByEmail(ctx, email).
+				Return(&user.User{ID: 1, Email: email, Password: "$2a$04$outdated", MerchantID: 3}, nil).
+				Times(1)
+
+			hasher.EXPECT().
+				ComparePassword(ctx, "$2a$04$outdated", password).
+				Return(nil).
+				Times(1)
+
+			hasher.EXPECT().
+				NeedsRehash("$2a$04$outdated").
+				Return(true).
+				Times(1)
+
+			hasher.EXPECT().
+				HashPassword(ctx, password).
+				Return("$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$a2V5", nil).
+				Times(1)
+
+			userRepository.
+				EXPECT().
+				UpdatePassword(ctx, 3, 1, "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$a2V5").
+				Return(updateErr).
+				Times(1)
+
+			twoFactorRepository.
+				EXPECT().
+				GetEnrollment(ctx, 1).
+				Return(twofactor.Enrollment{}, twofactor.ErrNotEnrolled).
+				Times(1)
+
+			sessionRepository.
+				EXPECT().
+				Create(ctx, gomock.Any()).
+				Return(nil).Times(1)
+
+			tokenSigner.
+				EXPECT().
+				Sign(ctx, gomock.Any()).
+				Return(jwt, nil).Times(1)
+
+			expectLoginAllowed(ctx, loginAttempts, email)
+			expectLoginReleased(ctx, loginAttempts, email)
+
+			loginAttempts.
+				EXPECT().
+				Reset(ctx, "account:"+strings.ToLower(email)).
+				Return(nil).
+				Times(1)
+
+			service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+
+			token, _, err := service.Login(ctx, email, password, loginIP)
+			assert.NoError(t, err)
+			assert.Equal(t, jwt, token.AccessToken)
+		})
+	}
+
+	t.Run("fail - invited user has not accepted yet", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, email).
+			Return(&user.User{ID: 9, Email: email, MerchantID: 1, Pending: true}, nil).
+			Times(1)
+
+		expectLoginAllowed(ctx, loginAttempts, email)
+
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, _, err := service.Login(ctx, email, "", loginIP)
+		assert.ErrorIs(t, err, user.ErrInvalidEmailAndPasword)
 	})
 
 	t.Run("fail - user not found", func(t *testing.T) {
@@ -74,8 +257,12 @@ func Test_apiService_Login(t *testing.T) {
 		expectedErr := user.ErrInvalidEmailAndPasword
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
@@ -83,11 +270,13 @@ func Test_apiService_Login(t *testing.T) {
 			Return(nil, sql.ErrNoRows).
 			Times(1)
 
-		service := service.NewAPIService(userRepository, hasher, tokenSigner)
+		expectLoginAllowed(ctx, loginAttempts, email)
 
-		accessToken, err := service.Login(ctx, email, password)
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+
+		token, _, err := service.Login(ctx, email, password, loginIP)
 		assert.ErrorIs(t, err, expectedErr)
-		assert.Empty(t, accessToken)
+		assert.Empty(t, token)
 	})
 
 	t.Run("fail - unkown error", func(t *testing.T) {
@@ -99,8 +288,12 @@ func Test_apiService_Login(t *testing.T) {
 		expectedErr := sql.ErrConnDone
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
@@ -108,11 +301,14 @@ func Test_apiService_Login(t *testing.T) {
 			Return(nil, sql.ErrConnDone).
 			Times(1)
 
-		service := service.NewAPIService(userRepository, hasher, tokenSigner)
+		expectLoginAllowed(ctx, loginAttempts, email)
+		expectLoginReleased(ctx, loginAttempts, email)
+
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		accessToken, err := service.Login(ctx, email, password)
+		token, _, err := service.Login(ctx, email, password, loginIP)
 		assert.ErrorIs(t, err, expectedErr)
-		assert.Empty(t, accessToken)
+		assert.Empty(t, token)
 	})
 
 	t.Run("failed - wrong password", func(t *testing.T) {
@@ -124,8 +320,12 @@ func Test_apiService_Login(t *testing.T) {
 		hashedPassword := faker.Password()
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
@@ -145,11 +345,13 @@ func Test_apiService_Login(t *testing.T) {
 			Return(bcrypt.ErrMismatchedHashAndPassword).
 			Times(1)
 
-		service := service.NewAPIService(userRepository, hasher, tokenSigner)
+		expectLoginAllowed(ctx, loginAttempts, email)
 
-		accessToken, err := service.Login(ctx, email, password)
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+
+		token, _, err := service.Login(ctx, email, password, loginIP)
 		assert.ErrorIs(t, err, user.ErrInvalidEmailAndPasword)
-		assert.Empty(t, accessToken)
+		assert.Empty(t, token)
 	})
 
 	t.Run("failed - bcyrpt unknown error", func(t *testing.T) {
@@ -161,8 +363,12 @@ func Test_apiService_Login(t *testing.T) {
 		hashedPassword := faker.Password()
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
@@ -182,11 +388,14 @@ func Test_apiService_Login(t *testing.T) {
 			Return(bcrypt.ErrHashTooShort).
 			Times(1)
 
-		service := service.NewAPIService(userRepository, hasher, tokenSigner)
+		expectLoginAllowed(ctx, loginAttempts, email)
+		expectLoginReleased(ctx, loginAttempts, email)
+
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		accessToken, err := service.Login(ctx, email, password)
+		token, _, err := service.Login(ctx, email, password, loginIP)
 		assert.ErrorIs(t, err, bcrypt.ErrHashTooShort)
-		assert.Empty(t, accessToken)
+		assert.Empty(t, token)
 	})
 
 	t.Run("failed - sign token error", func(t *testing.T) {
@@ -198,8 +407,12 @@ func Test_apiService_Login(t *testing.T) {
 		hashedPassword := faker.Password()
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
@@ -219,16 +432,164 @@ func Test_apiService_Login(t *testing.T) {
 			Return(nil).
 			Times(1)
 
+		hasher.EXPECT().
+			NeedsRehash(hashedPassword).
+			Return(false).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 1).
+			Return(twofactor.Enrollment{}, twofactor.ErrNotEnrolled).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil).Times(1)
+
 		tokenSigner.
 			EXPECT().
-			Sign(ctx, 1, email, 1).
+			Sign(ctx, gomock.Any()).
 			Return("", jwt.ErrInvalidKey).Times(1)
 
-		service := service.NewAPIService(userRepository, hasher, tokenSigner)
+		expectLoginAllowed(ctx, loginAttempts, email)
+		expectLoginReleased(ctx, loginAttempts, email)
+
+		loginAttempts.
+			EXPECT().
+			Reset(ctx, "account:"+strings.ToLower(email)).
+			Return(nil).
+			Times(1)
+
+		service := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		accessToken, err := service.Login(ctx, email, password)
+		token, _, err := service.Login(ctx, email, password, loginIP)
 		assert.ErrorIs(t, err, jwt.ErrInvalidKey)
-		assert.Empty(t, accessToken)
+		assert.Empty(t, token)
+	})
+
+	t.Run("two-factor enabled - returns a challenge", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		password := faker.Password()
+		hashedPassword := faker.Password()
+		enabledAt := time.Now().Add(-24 * time.Hour)
+
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, email).
+			Return(&user.User{ID: 1, MerchantID: 1, RoleID: role.CashierRoleID, Email: email, Password: hashedPassword}, nil).
+			Times(1)
+
+		hasher.EXPECT().
+			ComparePassword(ctx, hashedPassword, password).
+			Return(nil).
+			Times(1)
+
+		hasher.EXPECT().
+			NeedsRehash(hashedPassword).
+			Return(false).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 1).
+			Return(twofactor.Enrollment{UserID: 1, Secret: "JBSWY3DPEHPK3PXP", EnabledAt: &enabledAt}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			CreateChallenge(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity twofactor.Challenge) error {
+				assert.Equal(t, 1, entity.UserID)
+				assert.Equal(t, 1, entity.MerchantID)
+				assert.NotEmpty(t, entity.TokenHash)
+				return nil
+			}).
+			Times(1)
+
+		// The attempt is handed back, but the account count is only reset
+		// once the code is verified too.
+		expectLoginAllowed(ctx, loginAttempts, email)
+		expectLoginReleased(ctx, loginAttempts, email)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+
+		token, challenge, err := s.Login(ctx, email, password, loginIP)
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+		assert.NotNil(t, challenge)
+		assert.NotEmpty(t, challenge.Token)
+		assert.False(t, challenge.EnrollmentRequired)
+	})
+
+	t.Run("owner without two-factor - must enroll", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		password := faker.Password()
+		hashedPassword := faker.Password()
+
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, email).
+			Return(&user.User{ID: 1, MerchantID: 1, RoleID: role.OwnerRoleID, Email: email, Password: hashedPassword}, nil).
+			Times(1)
+
+		hasher.EXPECT().
+			ComparePassword(ctx, hashedPassword, password).
+			Return(nil).
+			Times(1)
+
+		hasher.EXPECT().
+			NeedsRehash(hashedPassword).
+			Return(false).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 1).
+			Return(twofactor.Enrollment{}, twofactor.ErrNotEnrolled).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			CreateChallenge(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		// The attempt is handed back, but the account count is only reset
+		// once the code is verified too.
+		expectLoginAllowed(ctx, loginAttempts, email)
+		expectLoginReleased(ctx, loginAttempts, email)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+
+		token, challenge, err := s.Login(ctx, email, password, loginIP)
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+		assert.NotNil(t, challenge)
+		assert.True(t, challenge.EnrollmentRequired)
 	})
 }
 
@@ -243,16 +604,57 @@ func Test_apiService_CreateUser(t *testing.T) {
 		payload := user.User{}
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.CreateUser(ctx, payload)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, role.Permissions, payload)
 		assert.Empty(t, resp)
 		assert.NotNil(t, err)
 		assert.ErrorIs(t, err, user.ErrInvalidCreateParameters)
 	})
 
+	t.Run("failed - password breaks the policy", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := user.User{Email: "budi@example.com", FirstName: "Budi", Password: "password"}
+		policy := password.Policy{
+			MinLength:          10,
+			RequireUppercase:   true,
+			RequireLowercase:   true,
+			RequireDigit:       true,
+			RequireSymbol:      true,
+			RejectPersonalInfo: true,
+			RejectCommon:       true,
+		}
+
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, policy, tokenSigner, loginAttempts)
+		_, err := s.CreateUser(ctx, role.Permissions, payload)
+		assert.ErrorIs(t, err, user.ErrInvalidPassword)
+
+		var policyErr *user.PasswordPolicyError
+		if assert.ErrorAs(t, err, &policyErr) {
+			var rules []string
+			for _, v := range policyErr.Violations {
+				rules = append(rules, v.Rule)
+			}
+			assert.Equal(t, []string{password.RuleMinLength, password.RuleUppercase, password.RuleDigit, password.RuleSymbol, password.RuleCommon}, rules)
+		}
+	})
+
 	t.Run("failed - hashing password", func(t *testing.T) {
 		t.Parallel()
 
@@ -267,8 +669,12 @@ func Test_apiService_CreateUser(t *testing.T) {
 		}
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		hasher.
 			EXPECT().
@@ -276,8 +682,8 @@ func Test_apiService_CreateUser(t *testing.T) {
 			Return("", bcrypt.ErrHashTooShort).
 			Times(1)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.CreateUser(ctx, payload)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, role.Permissions, payload)
 		assert.Empty(t, resp)
 		assert.NotNil(t, err)
 		assert.ErrorIs(t, err, bcrypt.ErrHashTooShort)
@@ -297,8 +703,12 @@ func Test_apiService_CreateUser(t *testing.T) {
 		}
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		hasher.
 			EXPECT().
@@ -312,14 +722,22 @@ func Test_apiService_CreateUser(t *testing.T) {
 			Return(nil, sql.ErrNoRows).
 			Times(1)
 
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, payload.MerchantID, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier", Permissions: []role.Permission{role.PermissionSaleCreate}}, nil).
+			Times(1)
+
+		created := payload
+		created.RoleID = role.CashierRoleID
 		userRepository.
 			EXPECT().
-			Create(ctx, payload).
+			Create(ctx, created).
 			Return(int64(0), sql.ErrConnDone).
 			Times(1)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.CreateUser(ctx, payload)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, role.Permissions, payload)
 		assert.Empty(t, resp)
 		assert.NotNil(t, err)
 		assert.ErrorIs(t, err, sql.ErrConnDone)
@@ -339,8 +757,12 @@ func Test_apiService_CreateUser(t *testing.T) {
 		}
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		hasher.
 			EXPECT().
@@ -354,14 +776,22 @@ func Test_apiService_CreateUser(t *testing.T) {
 			Return(nil, sql.ErrNoRows).
 			Times(1)
 
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, payload.MerchantID, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier", Permissions: []role.Permission{role.PermissionSaleCreate}}, nil).
+			Times(1)
+
+		created := payload
+		created.RoleID = role.CashierRoleID
 		userRepository.
 			EXPECT().
-			Create(ctx, payload).
+			Create(ctx, created).
 			Return(int64(1), nil).
 			Times(1)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.CreateUser(ctx, payload)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, role.Permissions, payload)
 		assert.NotEmpty(t, resp)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, resp)
@@ -381,8 +811,12 @@ func Test_apiService_CreateUser(t *testing.T) {
 		}
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		hasher.
 			EXPECT().
@@ -396,8 +830,8 @@ func Test_apiService_CreateUser(t *testing.T) {
 			Return(&user.User{}, sql.ErrNoRows).
 			Times(1)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.CreateUser(ctx, payload)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, role.Permissions, payload)
 		assert.Empty(t, resp)
 		assert.NotNil(t, err)
 		assert.ErrorIs(t, err, user.ErrEmailNotUnique)
@@ -417,8 +851,12 @@ func Test_apiService_CreateUser(t *testing.T) {
 		}
 
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		hasher.
 			EXPECT().
@@ -432,12 +870,57 @@ func Test_apiService_CreateUser(t *testing.T) {
 			Return(&user.User{}, sql.ErrConnDone).
 			Times(1)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.CreateUser(ctx, payload)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, role.Permissions, payload)
 		assert.Empty(t, resp)
 		assert.NotNil(t, err)
 		assert.ErrorIs(t, err, sql.ErrConnDone)
 	})
+
+	t.Run("grantor cannot hand out role", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		password := faker.Password()
+		payload := user.User{
+			MerchantID: 1,
+			RoleID:     role.ManagerRoleID,
+			Email:      faker.Email(),
+			FirstName:  faker.FirstName(),
+			Password:   password,
+		}
+
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, password).
+			Return(password, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, payload.Email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 1, role.ManagerRoleID).
+			Return(role.Role{ID: role.ManagerRoleID, Name: "manager", Permissions: []role.Permission{role.PermissionUserWrite, role.PermissionSaleRefund}}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.CreateUser(ctx, []role.Permission{role.PermissionUserWrite}, payload)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
 }
 
 func Test_apiService_GetUsers(t *testing.T) {
@@ -449,191 +932,1804 @@ func Test_apiService_GetUsers(t *testing.T) {
 
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 		merchantID := 1
-		expectedErr := sql.ErrConnDone
 
 		opts := user.RepositoryGetUserPaginationOptions{
-			Limit:  10,
-			Cursor: 0,
+			Limit:  11,
+			Filter: user.Filter{Status: user.StatusActive},
+			Sort:   user.Sort{Field: user.SortByID},
 		}
 
 		userRepository.
 			EXPECT().
 			Get(ctx, merchantID, &opts).
-			Return([]user.User{}, 0, sql.ErrConnDone)
+			Return(nil, 0, sql.ErrConnDone)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		users, totalData, err := s.GetUsers(ctx, merchantID, opts.Cursor, opts.Limit)
+		users, totalData, nextCursor, err := s.GetUsers(ctx, merchantID, user.Filter{}, user.Sort{}, "", 10)
 		assert.Empty(t, users)
 		assert.Empty(t, totalData)
-		assert.ErrorIs(t, err, expectedErr)
+		assert.Empty(t, nextCursor)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("success - last page has no cursor", func(t *testing.T) {
 		t.Parallel()
 
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 		merchantID := 1
+		createdFrom := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+		filter := user.Filter{Search: "doe", RoleID: 3, OutletID: 2, Status: user.StatusDeleted, CreatedFrom: &createdFrom}
+		sort := user.Sort{Field: user.SortByEmail, Descending: true}
 
 		opts := user.RepositoryGetUserPaginationOptions{
-			Limit:  10,
-			Cursor: 0,
+			Limit:  11,
+			Filter: filter,
+			Sort:   sort,
 		}
 
 		userRepository.
 			EXPECT().
 			Get(ctx, merchantID, &opts).
-			Return([]user.User{{}, {}, {}, {}, {}, {}, {}, {}, {}, {}}, 1764, nil)
+			Return([]user.User{{ID: 4}, {ID: 2}}, 2, nil)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		users, totalData, err := s.GetUsers(ctx, merchantID, opts.Cursor, opts.Limit)
+		users, totalData, nextCursor, err := s.GetUsers(ctx, merchantID, filter, sort, "", 10)
 		assert.NoError(t, err)
-		assert.Equal(t, totalData, 1764)
-		assert.NotEmpty(t, users)
-		assert.Len(t, users, 10)
+		assert.Equal(t, 2, totalData)
+		assert.Len(t, users, 2)
+		assert.Empty(t, nextCursor)
 	})
-}
-
-func Test_apiService_DeleteUser(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
 
-	t.Run("failed - user not found", func(t *testing.T) {
+	t.Run("success - cursor continues where the page ended", func(t *testing.T) {
 		t.Parallel()
 
-		userID := 0
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		merchantID := 1
+		sort := user.Sort{Field: user.SortByCreatedAt}
+		createdAt := time.Date(2021, time.March, 4, 10, 30, 0, 123456000, time.UTC)
+		filter := user.Filter{Status: user.StatusActive}
 
 		userRepository.
 			EXPECT().
-			Remove(ctx, userID).
-			Return(sql.ErrNoRows).
-			Times(1)
+			Get(ctx, merchantID, &user.RepositoryGetUserPaginationOptions{Limit: 3, Filter: filter, Sort: sort}).
+			Return([]user.User{{ID: 9}, {ID: 7, CreatedAt: createdAt}, {ID: 8}}, 5, nil)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		err := s.DeleteUser(ctx, userID)
-		assert.NotNil(t, err)
-		assert.ErrorIs(t, err, user.ErrUserNotFound)
+		users, totalData, nextCursor, err := s.GetUsers(ctx, merchantID, user.Filter{}, sort, "", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, totalData)
+		assert.Equal(t, []user.User{{ID: 9}, {ID: 7, CreatedAt: createdAt}}, users)
+		assert.NotEmpty(t, nextCursor)
+
+		userRepository.
+			EXPECT().
+			Get(ctx, merchantID, &user.RepositoryGetUserPaginationOptions{
+				Limit:  3,
+				Filter: filter,
+				Sort:   sort,
+				After:  &user.Cursor{Value: createdAt.Format(time.RFC3339Nano), ID: 7},
+			}).
+			Return([]user.User{{ID: 8}}, 5, nil)
+
+		users, _, nextCursor, err = s.GetUsers(ctx, merchantID, user.Filter{}, sort, nextCursor, 2)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Empty(t, nextCursor)
 	})
 
-	t.Run("failed - unknown error", func(t *testing.T) {
+	t.Run("failed - cursor of another sort order", func(t *testing.T) {
 		t.Parallel()
 
-		userID := 0
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		merchantID := 1
+		sort := user.Sort{Field: user.SortByFirstName}
 
 		userRepository.
 			EXPECT().
-			Remove(ctx, userID).
-			Return(sql.ErrTxDone).
-			Times(1)
+			Get(ctx, merchantID, gomock.Any()).
+			Return([]user.User{{ID: 1, FirstName: "Ann"}, {ID: 2}}, 2, nil)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		err := s.DeleteUser(ctx, userID)
-		assert.NotNil(t, err)
-		assert.ErrorIs(t, err, sql.ErrTxDone)
+		_, _, nextCursor, err := s.GetUsers(ctx, merchantID, user.Filter{}, sort, "", 1)
+		assert.NoError(t, err)
+
+		_, _, _, err = s.GetUsers(ctx, merchantID, user.Filter{}, user.Sort{Field: user.SortByFirstName, Descending: true}, nextCursor, 1)
+		assert.ErrorIs(t, err, user.ErrInvalidCursor)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("failed - invalid status", func(t *testing.T) {
 		t.Parallel()
 
-		userID := 0
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		userRepository.
-			EXPECT().
-			Remove(ctx, userID).
-			Return(nil).
-			Times(1)
-
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		err := s.DeleteUser(ctx, userID)
-		assert.Nil(t, err)
+		users, totalData, nextCursor, err := s.GetUsers(ctx, 1, user.Filter{Status: user.Status("purged")}, user.Sort{}, "", 10)
+		assert.Empty(t, users)
+		assert.Empty(t, totalData)
+		assert.Empty(t, nextCursor)
+		assert.ErrorIs(t, err, user.ErrInvalidStatus)
 	})
-}
 
-func Test_apiService_GetUser(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	t.Run("failed - user not found", func(t *testing.T) {
+	t.Run("failed - invalid sort field", func(t *testing.T) {
 		t.Parallel()
 
-		userID := 3
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		userRepository.
-			EXPECT().
-			GetUser(ctx, userID).
-			Return(user.User{}, user.ErrUserNotFound).
-			Times(1)
-
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.GetUser(ctx, userID)
-		assert.Empty(t, resp)
-		assert.ErrorIs(t, err, user.ErrUserNotFound)
+		users, totalData, nextCursor, err := s.GetUsers(ctx, 1, user.Filter{}, user.Sort{Field: user.SortField("password")}, "", 10)
+		assert.Empty(t, users)
+		assert.Empty(t, totalData)
+		assert.Empty(t, nextCursor)
+		assert.ErrorIs(t, err, user.ErrInvalidSortField)
 	})
 
-	t.Run("failed - unknown error", func(t *testing.T) {
+	t.Run("failed - malformed cursor", func(t *testing.T) {
 		t.Parallel()
 
-		userID := 3
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
 
-		userRepository.
-			EXPECT().
-			GetUser(ctx, userID).
-			Return(user.User{}, sql.ErrConnDone).
-			Times(1)
+		users, totalData, nextCursor, err := s.GetUsers(ctx, 1, user.Filter{}, user.Sort{}, "not-a-cursor", 10)
+		assert.Empty(t, users)
+		assert.Empty(t, totalData)
+		assert.Empty(t, nextCursor)
+		assert.ErrorIs(t, err, user.ErrInvalidCursor)
+	})
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.GetUser(ctx, userID)
-		assert.Empty(t, resp)
-		assert.ErrorIs(t, err, sql.ErrConnDone)
+	t.Run("failed - empty date range", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		day := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		users, totalData, nextCursor, err := s.GetUsers(ctx, 1, user.Filter{CreatedFrom: &day, CreatedTo: &day}, user.Sort{}, "", 10)
+		assert.Empty(t, users)
+		assert.Empty(t, totalData)
+		assert.Empty(t, nextCursor)
+		assert.ErrorIs(t, err, user.ErrInvalidDateRange)
 	})
+}
 
-	t.Run("success", func(t *testing.T) {
+func Test_apiService_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashier := []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}
+	manager := []role.Permission{role.PermissionUserWrite, role.PermissionSaleCreate, role.PermissionSaleRead, role.PermissionSaleRefund}
+
+	t.Run("failed - manager removes the owner", func(t *testing.T) {
 		t.Parallel()
 
-		userID := 3
-		u := user.User{
-			ID: userID,
-		}
+		userID := 2
+		merchantID := 1
 		ctx := context.Background()
 		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
 		hasher := smock.NewMockHasher(ctrl)
 		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
 
 		userRepository.
 			EXPECT().
-			GetUser(ctx, userID).
-			Return(u, nil).
+			GetUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: role.Permissions}, nil).
 			Times(1)
 
-		s := service.NewAPIService(userRepository, hasher, tokenSigner)
-		resp, err := s.GetUser(ctx, userID)
-		assert.NoError(t, err)
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.DeleteUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("failed - user not found", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 0
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantID, userID).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.DeleteUser(ctx, merchantID, manager, userID)
+		assert.NotNil(t, err)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("failed - unknown error", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 0
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Remove(ctx, merchantID, userID).
+			Return(sql.ErrTxDone).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.DeleteUser(ctx, merchantID, manager, userID)
+		assert.NotNil(t, err)
+		assert.ErrorIs(t, err, sql.ErrTxDone)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 0
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Remove(ctx, merchantID, userID).
+			Return(nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			RevokeUserSessions(ctx, userID).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.DeleteUser(ctx, merchantID, manager, userID)
+		assert.Nil(t, err)
+	})
+}
+
+func Test_apiService_RestoreUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashier := []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}
+	manager := []role.Permission{role.PermissionUserWrite, role.PermissionUserPurge, role.PermissionSaleCreate, role.PermissionSaleRead}
+
+	t.Run("failed - manager targets the owner", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: role.Permissions}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.RestoreUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("failed - user not removed", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Restore(ctx, merchantID, userID).
+			Return(user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.RestoreUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("failed - email taken meanwhile", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Restore(ctx, merchantID, userID).
+			Return(user.ErrEmailNotUnique).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.RestoreUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, user.ErrEmailNotUnique)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Restore(ctx, merchantID, userID).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.RestoreUser(ctx, merchantID, manager, userID)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_apiService_PurgeUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashier := []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}
+	manager := []role.Permission{role.PermissionUserWrite, role.PermissionUserPurge, role.PermissionSaleCreate, role.PermissionSaleRead}
+
+	t.Run("failed - manager targets the owner", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: role.Permissions}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.PurgeUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("failed - user not removed", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Purge(ctx, merchantID, userID).
+			Return(user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.PurgeUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("failed - db error", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Purge(ctx, merchantID, userID).
+			Return(sql.ErrConnDone).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.PurgeUser(ctx, merchantID, manager, userID)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 2
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetRemovedUser(ctx, merchantID, userID).
+			Return(user.User{ID: userID, MerchantID: merchantID, Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Purge(ctx, merchantID, userID).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.PurgeUser(ctx, merchantID, manager, userID)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_apiService_GetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - user not found", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 3
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantID, userID).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.GetUser(ctx, merchantID, userID)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("failed - unknown error", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 3
+		merchantID := 1
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantID, userID).
+			Return(user.User{}, sql.ErrConnDone).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.GetUser(ctx, merchantID, userID)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		userID := 3
+		merchantID := 1
+		u := user.User{
+			ID:         userID,
+			MerchantID: merchantID,
+		}
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantID, userID).
+			Return(u, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.GetUser(ctx, merchantID, userID)
+		assert.NoError(t, err)
 		assert.NotEmpty(t, resp)
 		assert.Equal(t, userID, resp.ID)
 	})
 }
+
+func Test_apiService_AssignRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashier := []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}
+	manager := []role.Permission{role.PermissionUserWrite, role.PermissionSaleCreate, role.PermissionSaleRead, role.PermissionSaleRefund}
+
+	t.Run("cannot change own role", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.AssignRole(ctx, 1, 5, role.Permissions, 5, role.CashierRoleID)
+		assert.ErrorIs(t, err, role.ErrForbidden)
+	})
+
+	t.Run("user of another merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.AssignRole(ctx, 1, 5, role.Permissions, 7, role.ManagerRoleID)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("grantor lacks the target's permissions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, RoleID: role.OwnerRoleID, Permissions: role.Permissions}, nil).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 1, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier", Permissions: cashier}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.AssignRole(ctx, 1, 5, manager, 7, role.CashierRoleID)
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, RoleID: role.CashierRoleID, Permissions: cashier}, nil).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 1, 4).
+			Return(role.Role{ID: 4, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRead, role.PermissionSaleRefund}}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			UpdateRole(ctx, 1, 7, 4).
+			Return(nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			RevokeUserSessions(ctx, 7).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.AssignRole(ctx, 1, 5, manager, 7, 4)
+		assert.NoError(t, err)
+	})
+}
+
+// Test_apiService_TenantIsolation shows that every user lookup and change is
+// made within the caller's merchant, so a user of merchant A cannot read or
+// change a user of merchant B even when guessing their id.
+func Test_apiService_TenantIsolation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	merchantA := 1
+	userOfMerchantB := 42
+
+	t.Run("cannot read another merchant's user", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantA, userOfMerchantB).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		resp, err := s.GetUser(ctx, merchantA, userOfMerchantB)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("cannot delete another merchant's user", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantA, userOfMerchantB).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.DeleteUser(ctx, merchantA, role.Permissions, userOfMerchantB)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("cannot change another merchant's user role", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, merchantA, userOfMerchantB).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.AssignRole(ctx, merchantA, 5, role.Permissions, userOfMerchantB, role.CashierRoleID)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("new users join the creator's merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		password := faker.Password()
+		payload := user.User{
+			MerchantID: merchantA,
+			Email:      faker.Email(),
+			FirstName:  faker.FirstName(),
+			Password:   password,
+		}
+
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, password).
+			Return(password, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, payload.Email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantA, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier"}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity user.User) (int64, error) {
+				assert.Equal(t, merchantA, entity.MerchantID)
+				return 8, nil
+			}).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		userID, err := s.CreateUser(ctx, role.Permissions, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, 8, userID)
+	})
+}
+
+func Test_apiService_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashier := []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}
+
+	t.Run("cannot edit a user with more permissions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "owner@shop.id", FirstName: "Owner", Permissions: role.Permissions}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.UpdateUser(ctx, 1, 5, "sid", cashier, 7, user.Profile{Email: &email})
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, email).
+			Return(&user.User{ID: 8}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.UpdateUser(ctx, 1, 5, "sid", role.Permissions, 7, user.Profile{Email: &email})
+		assert.ErrorIs(t, err, user.ErrEmailNotUnique)
+	})
+
+	t.Run("empty firstname", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		firstName := " "
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Permissions: cashier}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.UpdateUser(ctx, 1, 7, "sid", cashier, 7, user.Profile{FirstName: &firstName})
+		assert.ErrorIs(t, err, user.ErrInvalidUpdateParameters)
+	})
+
+	t.Run("success - own profile", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		firstName := "Ani"
+		lastName := "Wijaya"
+		before := time.Now().Add(-time.Hour)
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Permissions: cashier, UpdatedAt: before}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Update(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity user.User) error {
+				assert.Equal(t, "Ani", entity.FirstName)
+				assert.Equal(t, &lastName, entity.LastName)
+				assert.Equal(t, "cashier@shop.id", entity.Email)
+				assert.True(t, entity.UpdatedAt.After(before))
+				return nil
+			}).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		updated, err := s.UpdateUser(ctx, 1, 7, "sid", cashier, 7, user.Profile{FirstName: &firstName, LastName: &lastName})
+		assert.NoError(t, err)
+		assert.Equal(t, "Ani", updated.FirstName)
+	})
+
+	t.Run("own email change without the current password", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Password: "hashed", Permissions: cashier}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.UpdateUser(ctx, 1, 7, "sid", cashier, 7, user.Profile{Email: &email})
+		assert.ErrorIs(t, err, user.ErrWrongPassword)
+	})
+
+	t.Run("own email change with a wrong password", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		password := "not-my-password"
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Password: "hashed", Permissions: cashier}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed", password).
+			Return(bcrypt.ErrMismatchedHashAndPassword).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.UpdateUser(ctx, 1, 7, "sid", cashier, 7, user.Profile{Email: &email, CurrentPassword: &password})
+		assert.ErrorIs(t, err, user.ErrWrongPassword)
+	})
+
+	t.Run("success - own email change ends other sessions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		password := faker.Password()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Password: "hashed", Permissions: cashier}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed", password).
+			Return(nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Update(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			RevokeOtherSessions(ctx, 7, "sid").
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		updated, err := s.UpdateUser(ctx, 1, 7, "sid", cashier, 7, user.Profile{Email: &email, CurrentPassword: &password})
+		assert.NoError(t, err)
+		assert.Equal(t, email, updated.Email)
+	})
+
+	t.Run("success - email change by an admin ends the user's sessions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		email := faker.Email()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "cashier@shop.id", FirstName: "Cashier", Permissions: cashier}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Update(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			RevokeUserSessions(ctx, 7).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.UpdateUser(ctx, 1, 5, "sid", role.Permissions, 7, user.Profile{Email: &email})
+		assert.NoError(t, err)
+	})
+}
+
+func Test_apiService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("new password too short", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Password: "hashed"}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.ChangePassword(ctx, 1, 7, "sid", "current-password", "short")
+		assert.ErrorIs(t, err, user.ErrInvalidPassword)
+	})
+
+	t.Run("new password contains the user's name", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		lastName := "Wijaya"
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "siti@example.com", FirstName: "Siti", LastName: &lastName, Password: "hashed"}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, password.Policy{RejectPersonalInfo: true}, tokenSigner, loginAttempts)
+		err := s.ChangePassword(ctx, 1, 7, "sid", "current-password", "wijaya-1987-pos")
+
+		var policyErr *user.PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []user.PasswordViolation{{Rule: password.RulePersonalInfo, Message: "password must not contain your email or name"}}, policyErr.Violations)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Password: "hashed"}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed", "not-my-password").
+			Return(bcrypt.ErrMismatchedHashAndPassword).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.ChangePassword(ctx, 1, 7, "sid", "not-my-password", "new-password")
+		assert.ErrorIs(t, err, user.ErrWrongPassword)
+	})
+
+	t.Run("success - other sessions are revoked", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Password: "hashed"}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed", "current-password").
+			Return(nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, "new-password").
+			Return("new-hashed", nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			UpdatePassword(ctx, 1, 7, "new-hashed").
+			Return(nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			RevokeOtherSessions(ctx, 7, "sid").
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.ChangePassword(ctx, 1, 7, "sid", "current-password", "new-password")
+		assert.NoError(t, err)
+	})
+}
+
+func Test_apiService_SetPIN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("invalid PIN", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		for _, pin := range []string{"", "123", "1234567", "12a4"} {
+			assert.ErrorIs(t, s.SetPIN(ctx, 1, 7, pin), user.ErrInvalidPINFormat)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, "482913").
+			Return("hashed-pin", nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			SetPIN(ctx, 1, 7, "hashed-pin").
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.SetPIN(ctx, 1, 7, "482913")
+		assert.NoError(t, err)
+	})
+}
+
+func Test_apiService_PINLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashier := []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}
+
+	t.Run("outlet of another merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "pin:ip:10.0.0.1", user.IPFreePINAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 1}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetOutletUser(ctx, 4, 7).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.PINLogin(ctx, 4, 7, "1234", "10.0.0.1")
+		assert.ErrorIs(t, err, user.ErrInvalidPIN)
+	})
+
+	t.Run("client IP backing off", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(20 * time.Second)
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "pin:ip:10.0.0.1", user.IPFreePINAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: user.IPFreePINAttempts}, &user.LockoutError{Until: until}).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.PINLogin(ctx, 4, 7, "1234", "10.0.0.1")
+
+		var lockout *user.LockoutError
+		assert.ErrorAs(t, err, &lockout)
+		assert.Equal(t, until, lockout.Until)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(10 * time.Minute)
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "pin:ip:10.0.0.1", user.IPFreePINAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 1}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetOutletUser(ctx, 4, 7).
+			Return(user.User{ID: 7, MerchantID: 1, PIN: "hashed-pin", PINLockedUntil: &until}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.PINLogin(ctx, 4, 7, "1234", "10.0.0.1")
+		assert.ErrorIs(t, err, user.ErrLockedOut)
+
+		var lockout *user.LockoutError
+		assert.ErrorAs(t, err, &lockout)
+		assert.Equal(t, until, lockout.Until)
+	})
+
+	t.Run("wrong PIN", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "pin:ip:10.0.0.1", user.IPFreePINAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 1}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetOutletUser(ctx, 4, 7).
+			Return(user.User{ID: 7, MerchantID: 1, PIN: "hashed-pin", PINAttempts: 1}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed-pin", "0000").
+			Return(bcrypt.ErrMismatchedHashAndPassword).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			RecordPINFailure(ctx, 7).
+			Return(nil, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.PINLogin(ctx, 4, 7, "0000", "10.0.0.1")
+		assert.ErrorIs(t, err, user.ErrInvalidPIN)
+	})
+
+	t.Run("last wrong PIN locks", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(user.PINLockout)
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "pin:ip:10.0.0.1", user.IPFreePINAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 1}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetOutletUser(ctx, 4, 7).
+			Return(user.User{ID: 7, MerchantID: 1, PIN: "hashed-pin", PINAttempts: user.MaxPINAttempts - 1}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed-pin", "0000").
+			Return(bcrypt.ErrMismatchedHashAndPassword).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			RecordPINFailure(ctx, 7).
+			Return(&until, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, err := s.PINLogin(ctx, 4, 7, "0000", "10.0.0.1")
+		assert.ErrorIs(t, err, user.ErrLockedOut)
+	})
+
+	t.Run("success - token is limited to the outlet and cashier permissions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "pin:ip:10.0.0.1", user.IPFreePINAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 1}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetOutletUser(ctx, 4, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Role: "owner", Permissions: role.Permissions, PIN: "hashed-pin", PINAttempts: 2}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			ComparePassword(ctx, "hashed-pin", "482913").
+			Return(nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			ResetPINFailures(ctx, 7).
+			Return(nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Release(ctx, "pin:ip:10.0.0.1").
+			Return(nil).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 1, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier", Permissions: cashier}, nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity session.Session) error {
+				assert.Equal(t, 4, *entity.OutletID)
+				return nil
+			}).
+			Times(1)
+
+		tokenSigner.
+			EXPECT().
+			Sign(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, identity session.Identity) (string, error) {
+				assert.Equal(t, 4, identity.OutletID)
+				assert.Equal(t, cashier, identity.Permissions)
+				return "access-token", nil
+			}).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		token, err := s.PINLogin(ctx, 4, 7, "482913", "10.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", token.AccessToken)
+	})
+}
+
+func Test_apiService_LoginLockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("account backing off", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(time.Minute)
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "account:owner@shop.id", user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: user.AccountFreeLoginAttempts + 1}, &user.LockoutError{Until: until}).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, _, err := s.Login(ctx, " Owner@Shop.id", "password", loginIP)
+		assert.ErrorIs(t, err, user.ErrLockedOut)
+
+		var lockout *user.LockoutError
+		assert.ErrorAs(t, err, &lockout)
+		assert.Equal(t, until, lockout.Until)
+	})
+
+	t.Run("IP backing off hands the account attempt back", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(user.LoginBackoffMax)
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "account:cashier@shop.id", user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 1}, nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "ip:"+loginIP, user.IPFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 100}, &user.LockoutError{Until: until}).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Release(ctx, "account:cashier@shop.id").
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		_, _, err := s.Login(ctx, "cashier@shop.id", "password", loginIP)
+
+		var lockout *user.LockoutError
+		assert.ErrorAs(t, err, &lockout)
+		assert.Equal(t, until, lockout.Until)
+	})
+}
+
+func Test_apiService_UnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := []role.Permission{role.PermissionUserWrite, role.PermissionSaleCreate, role.PermissionSaleRead, role.PermissionSaleRefund}
+
+	t.Run("user of another merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 9).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.UnlockUser(ctx, 1, manager, 9)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("success - clears password and PIN lockouts", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "Cashier@shop.id", Permissions: []role.Permission{role.PermissionSaleCreate}}, nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Reset(ctx, "account:cashier@shop.id").
+			Return(nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			ResetPINFailures(ctx, 7).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.UnlockUser(ctx, 1, manager, 7)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed - manager unlocks the owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := mock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 2).
+			Return(user.User{ID: 2, MerchantID: 1, Email: "owner@shop.id", Permissions: role.Permissions}, nil).
+			Times(1)
+
+		s := service.NewAPIService(userRepository, sessionRepository, roleRepository, twoFactorRepository, hasher, passwordPolicy, tokenSigner, loginAttempts)
+		err := s.UnlockUser(ctx, 1, manager, 2)
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/apikey"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+// lastUsedResolution limits how often a busy key's last-used time is written.
+const lastUsedResolution = time.Minute
+
+type apiKeyService struct {
+	apiKeyRepository apikey.Repository
+	userRepository   user.Repository
+}
+
+// NewAPIKeyService manages API keys. userRepository looks up the creator of
+// a key on every use, as a key never does more than its creator still may.
+func NewAPIKeyService(apiKeyRepository apikey.Repository, userRepository user.Repository) *apiKeyService {
+	return &apiKeyService{
+		apiKeyRepository: apiKeyRepository,
+		userRepository:   userRepository,
+	}
+}
+
+// CreateAPIKey issues a key for the grantor's merchant and returns it with
+// the full key, which cannot be recovered later. Like roles, a key may only
+// carry permissions the grantor holds.
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, grantor []role.Permission, entity apikey.Key) (created apikey.Key, key string, err error) {
+	const ops = "service.apiKeyService.CreateAPIKey"
+	var id int64
+	now := time.Now()
+
+	entity.Name = strings.TrimSpace(entity.Name)
+	if entity.Name == "" || len(entity.Permissions) == 0 || (entity.ExpiresAt != nil && !entity.ExpiresAt.After(now)) {
+		return apikey.Key{}, "", apikey.ErrInvalidAPIKeyParameters
+	}
+
+	for _, p := range entity.Permissions {
+		if !p.Valid() {
+			return apikey.Key{}, "", apikey.ErrInvalidAPIKeyParameters
+		}
+	}
+
+	if !role.Covers(grantor, entity.Permissions) {
+		return apikey.Key{}, "", role.ErrInsufficientPermissions
+	}
+
+	b := make([]byte, 6)
+	if _, err = rand.Read(b); err != nil {
+		logger.Error(ctx, ops, "error generating key prefix: %v", err)
+		return apikey.Key{}, "", err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating key secret: %v", err)
+		return apikey.Key{}, "", err
+	}
+
+	entity.Prefix = hex.EncodeToString(b)
+	entity.SecretHash = hashSecret(secret)
+	entity.CreatedAt = now
+	entity.LastUsedAt = nil
+	entity.RevokedAt = nil
+
+	id, err = s.apiKeyRepository.Create(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error storing API key: %v", err)
+		return apikey.Key{}, "", err
+	}
+
+	entity.ID = int(id)
+	return entity, apikey.TokenPrefix + entity.Prefix + "." + secret, nil
+}
+
+func (s *apiKeyService) GetAPIKeys(ctx context.Context, merchantID int) (keys []apikey.Key, err error) {
+	const ops = "service.apiKeyService.GetAPIKeys"
+
+	keys, err = s.apiKeyRepository.Get(ctx, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting API keys: %v", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey stops a key of the merchant from working. Keys of other
+// merchants are reported as not found.
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, merchantID, keyID int) (err error) {
+	const ops = "service.apiKeyService.RevokeAPIKey"
+
+	if err = s.apiKeyRepository.Revoke(ctx, merchantID, keyID); err != nil {
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error revoking API key: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Authenticate returns the active key that key belongs to. Its permissions
+// are cut down to those its creator holds now, and a key whose creator was
+// removed stops working. Every mismatch is ErrInvalidAPIKey, so callers
+// cannot tell a wrong secret from an unknown or revoked key.
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (entity apikey.Key, err error) {
+	const ops = "service.apiKeyService.Authenticate"
+	now := time.Now()
+
+	if !strings.HasPrefix(key, apikey.TokenPrefix) {
+		return apikey.Key{}, apikey.ErrInvalidAPIKey
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(key, apikey.TokenPrefix), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return apikey.Key{}, apikey.ErrInvalidAPIKey
+	}
+
+	entity, err = s.apiKeyRepository.GetByPrefix(ctx, parts[0])
+	if err != nil {
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+			return apikey.Key{}, apikey.ErrInvalidAPIKey
+		}
+
+		logger.Error(ctx, ops, "error getting API key: %v", err)
+		return apikey.Key{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(parts[1])), []byte(entity.SecretHash)) != 1 || !entity.Active(now) {
+		return apikey.Key{}, apikey.ErrInvalidAPIKey
+	}
+
+	creator, err := s.userRepository.GetUser(ctx, entity.MerchantID, entity.CreatedBy)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return apikey.Key{}, apikey.ErrInvalidAPIKey
+		}
+
+		logger.Error(ctx, ops, "error getting API key creator: %v", err)
+		return apikey.Key{}, err
+	}
+	entity.Permissions = role.Intersect(entity.Permissions, creator.Permissions)
+
+	if entity.LastUsedAt == nil || now.Sub(*entity.LastUsedAt) >= lastUsedResolution {
+		if err = s.apiKeyRepository.MarkUsed(ctx, entity.ID, now); err != nil {
+			logger.Error(ctx, ops, "error recording API key use: %v", err)
+			return apikey.Key{}, err
+		}
+		entity.LastUsedAt = &now
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,302 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/apikey"
+	akmock "github.com/mhdiiilham/POS/entity/apikey/mock"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/user"
+	umock "github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_apiKeyService_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := []role.Permission{role.PermissionProductRead, role.PermissionProductWrite, role.PermissionSaleRead}
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		past := time.Now().Add(-time.Hour)
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		for _, entity := range []apikey.Key{
+			{Name: " ", Permissions: []role.Permission{role.PermissionProductRead}},
+			{Name: "shop sync"},
+			{Name: "shop sync", Permissions: []role.Permission{"product:delete"}},
+			{Name: "shop sync", Permissions: []role.Permission{role.PermissionProductRead}, ExpiresAt: &past},
+		} {
+			_, _, err := s.CreateAPIKey(ctx, manager, entity)
+			assert.ErrorIs(t, err, apikey.ErrInvalidAPIKeyParameters)
+		}
+	})
+
+	t.Run("permissions the grantor lacks", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		_, _, err := s.CreateAPIKey(ctx, manager, apikey.Key{
+			MerchantID:  1,
+			Name:        "accounting",
+			Permissions: []role.Permission{role.PermissionSaleRead, role.PermissionUserWrite},
+		})
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("success - only the secret hash is stored", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var stored apikey.Key
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity apikey.Key) (int64, error) {
+				stored = entity
+				return 12, nil
+			}).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		created, key, err := s.CreateAPIKey(ctx, manager, apikey.Key{
+			MerchantID:  1,
+			CreatedBy:   7,
+			Name:        "shop sync",
+			Permissions: []role.Permission{role.PermissionProductRead},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 12, created.ID)
+		assert.True(t, strings.HasPrefix(key, apikey.TokenPrefix+stored.Prefix+"."))
+		assert.Equal(t, refreshHash(strings.SplitN(key, ".", 2)[1]), stored.SecretHash)
+	})
+}
+
+func Test_apiKeyService_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := func(mutate func(*apikey.Key)) apikey.Key {
+		entity := apikey.Key{
+			ID:          12,
+			MerchantID:  1,
+			CreatedBy:   7,
+			Prefix:      "a1b2c3d4e5f6",
+			SecretHash:  refreshHash("secret"),
+			Permissions: []role.Permission{role.PermissionProductRead},
+		}
+		if mutate != nil {
+			mutate(&entity)
+		}
+		return entity
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		for _, raw := range []string{"", "a1b2c3d4e5f6.secret", "pos_", "pos_a1b2c3d4e5f6", "pos_.secret"} {
+			_, err := s.Authenticate(ctx, raw)
+			assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+		}
+	})
+
+	t.Run("unknown prefix", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			GetByPrefix(ctx, "000000000000").
+			Return(apikey.Key{}, apikey.ErrAPIKeyNotFound).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		_, err := s.Authenticate(ctx, "pos_000000000000.secret")
+		assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+	})
+
+	for name, entity := range map[string]apikey.Key{
+		"revoked": key(func(k *apikey.Key) {
+			revokedAt := time.Now().Add(-time.Minute)
+			k.RevokedAt = &revokedAt
+		}),
+		"expired": key(func(k *apikey.Key) {
+			expiresAt := time.Now().Add(-time.Minute)
+			k.ExpiresAt = &expiresAt
+		}),
+	} {
+		entity := entity
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			apiKeyRepository := akmock.NewMockRepository(ctrl)
+			userRepository := umock.NewMockRepository(ctrl)
+
+			apiKeyRepository.
+				EXPECT().
+				GetByPrefix(ctx, "a1b2c3d4e5f6").
+				Return(entity, nil).
+				Times(1)
+
+			s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+			_, err := s.Authenticate(ctx, "pos_a1b2c3d4e5f6.secret")
+			assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+		})
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			GetByPrefix(ctx, "a1b2c3d4e5f6").
+			Return(key(nil), nil).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		_, err := s.Authenticate(ctx, "pos_a1b2c3d4e5f6.guess")
+		assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+	})
+
+	t.Run("creator removed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			GetByPrefix(ctx, "a1b2c3d4e5f6").
+			Return(key(nil), nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		_, err := s.Authenticate(ctx, "pos_a1b2c3d4e5f6.secret")
+		assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+	})
+
+	t.Run("success - permissions the creator lost are dropped", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			GetByPrefix(ctx, "a1b2c3d4e5f6").
+			Return(key(func(k *apikey.Key) {
+				lastUsedAt := time.Now()
+				k.LastUsedAt = &lastUsedAt
+				k.Permissions = []role.Permission{role.PermissionProductRead, role.PermissionProductWrite}
+			}), nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, Permissions: []role.Permission{role.PermissionProductRead, role.PermissionSaleRead}}, nil).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		entity, err := s.Authenticate(ctx, "pos_a1b2c3d4e5f6.secret")
+		assert.NoError(t, err)
+		assert.Equal(t, []role.Permission{role.PermissionProductRead}, entity.Permissions)
+	})
+
+	t.Run("success - records first use", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			GetByPrefix(ctx, "a1b2c3d4e5f6").
+			Return(key(nil), nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, Permissions: []role.Permission{role.PermissionProductRead, role.PermissionSaleRead}}, nil).
+			Times(1)
+
+		apiKeyRepository.
+			EXPECT().
+			MarkUsed(ctx, 12, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		entity, err := s.Authenticate(ctx, "pos_a1b2c3d4e5f6.secret")
+		assert.NoError(t, err)
+		assert.Equal(t, 7, entity.CreatedBy)
+		assert.NotNil(t, entity.LastUsedAt)
+	})
+
+	t.Run("success - recent use is not written again", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		apiKeyRepository := akmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+
+		apiKeyRepository.
+			EXPECT().
+			GetByPrefix(ctx, "a1b2c3d4e5f6").
+			Return(key(func(k *apikey.Key) {
+				lastUsedAt := time.Now().Add(-10 * time.Second)
+				k.LastUsedAt = &lastUsedAt
+			}), nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, Permissions: []role.Permission{role.PermissionProductRead}}, nil).
+			Times(1)
+
+		s := service.NewAPIKeyService(apiKeyRepository, userRepository)
+		_, err := s.Authenticate(ctx, "pos_a1b2c3d4e5f6.secret")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+// attemptLimit is a key attempts are counted against and how many of them
+// are free before it backs off.
+type attemptLimit struct {
+	key  string
+	free int
+}
+
+// takeAttempts counts an attempt against every limit. When one of them is
+// locked out, the attempt is counted against none and its LockoutError is
+// returned.
+func takeAttempts(ctx context.Context, store LoginAttemptStore, now time.Time, ttl time.Duration, limits ...attemptLimit) error {
+	for i, limit := range limits {
+		if _, err := store.Take(ctx, limit.key, limit.free, now, ttl); err != nil {
+			releaseAttempts(ctx, store, limits[:i]...)
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseAttempts hands back attempts takeAttempts counted. A failed release
+// only leaves a count one too high until it expires, so it is logged rather
+// than returned.
+func releaseAttempts(ctx context.Context, store LoginAttemptStore, limits ...attemptLimit) {
+	const ops = "service.releaseAttempts"
+
+	for _, limit := range limits {
+		if err := store.Release(ctx, limit.key); err != nil {
+			logger.Error(ctx, ops, "error releasing attempt on %s: %v", limit.key, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/user"
+)
+
+// userCursor is what an opaque user listing cursor carries. It remembers the
+// order it was made for, so it cannot be replayed against another one.
+type userCursor struct {
+	Field      user.SortField `json:"f"`
+	Descending bool           `json:"d,omitempty"`
+	Value      string         `json:"v"`
+	ID         int            `json:"i"`
+}
+
+// encodeUserCursor returns the cursor of the page after last.
+func encodeUserCursor(sort user.Sort, last user.User) string {
+	c := userCursor{Field: sort.Field, Descending: sort.Descending, ID: last.ID}
+
+	switch sort.Field {
+	case user.SortByID:
+		c.Value = strconv.Itoa(last.ID)
+	case user.SortByFirstName:
+		c.Value = last.FirstName
+	case user.SortByLastName:
+		if last.LastName != nil {
+			c.Value = *last.LastName
+		}
+	case user.SortByEmail:
+		c.Value = last.Email
+	case user.SortByCreatedAt:
+		c.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeUserCursor reads a cursor made by encodeUserCursor for sort.
+func decodeUserCursor(sort user.Sort, token string) (*user.Cursor, error) {
+	var c userCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, user.ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(b, &c); err != nil || c.ID <= 0 {
+		return nil, user.ErrInvalidCursor
+	}
+
+	if c.Field != sort.Field || c.Descending != sort.Descending {
+		return nil, user.ErrInvalidCursor
+	}
+
+	return &user.Cursor{Value: c.Value, ID: c.ID}, nil
+}
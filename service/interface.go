@@ -2,16 +2,63 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/user"
 )
 
+// Hasher hashes passwords and PINs. NeedsRehash reports whether a stored hash
+// was made with an outdated algorithm or parameters.
 type Hasher interface {
 	HashPassword(ctx context.Context, password string) (hashed string, err error)
 	ComparePassword(ctx context.Context, hashedPassword, password string) error
+	NeedsRehash(hashedPassword string) bool
+}
+
+// PasswordPolicy decides which passwords users may choose. Check returns a
+// *user.PasswordPolicyError listing every failed rule; personal holds the
+// user's email and names.
+type PasswordPolicy interface {
+	Check(password string, personal ...string) error
 }
 
 type TokenSigner interface {
-	Sign(ctx context.Context, userID int, email string, merchantID int) (at string, err error)
+	Sign(ctx context.Context, identity session.Identity) (at string, err error)
 	Extract(ctx context.Context, signedToken string) (jwt.MapClaims, error)
 }
+
+// PaymentGateway talks to the acquirer for card and e-wallet payments. Refund
+// on a transaction that was authorized but never captured releases the hold.
+// A Refund repeated with the same idempotencyKey returns the first result
+// instead of paying out again.
+type PaymentGateway interface {
+	Authorize(ctx context.Context, method payment.Method, amount float64, token string) (tx payment.GatewayTransaction, err error)
+	Capture(ctx context.Context, transactionID string) (tx payment.GatewayTransaction, err error)
+	Refund(ctx context.Context, transactionID string, amount float64, idempotencyKey string) (tx payment.GatewayTransaction, err error)
+	Status(ctx context.Context, transactionID string) (tx payment.GatewayTransaction, err error)
+}
+
+// Transactor runs fn in one database transaction, which repositories called
+// with the context fn is given take part in.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Mailer delivers plain-text email to a single recipient.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// LoginAttemptStore keeps attempt counts by key. Take checks the lockout and
+// counts the attempt in one step, so parallel attempts cannot all pass the
+// check before any of them is counted; attempts that succeed are handed back
+// with Release or Reset. The in-memory store only sees its own instance;
+// running several instances needs a shared one, whose Take must be atomic.
+type LoginAttemptStore interface {
+	Take(ctx context.Context, key string, free int, at time.Time, ttl time.Duration) (attempts user.LoginAttempts, err error)
+	Release(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
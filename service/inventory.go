@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type inventoryService struct {
+	inventoryRepository inventory.Repository
+	outletRepository    outlet.Repository
+	productRepository   product.Repository
+}
+
+func NewInventoryService(inventoryRepository inventory.Repository, outletRepository outlet.Repository, productRepository product.Repository) *inventoryService {
+	return &inventoryService{
+		inventoryRepository: inventoryRepository,
+		outletRepository:    outletRepository,
+		productRepository:   productRepository,
+	}
+}
+
+func (s *inventoryService) AssignProduct(ctx context.Context, merchantID, userID int, entity inventory.OutletProduct) (assigned inventory.OutletProduct, err error) {
+	const ops = "service.inventoryService.AssignProduct"
+	var p product.Product
+
+	if entity.Price < 0 || entity.Stock < 0 {
+		return inventory.OutletProduct{}, inventory.ErrInvalidInventoryParameters
+	}
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, entity.OutletID); err != nil {
+		if errors.Is(err, outlet.ErrOutletNotFound) {
+			return inventory.OutletProduct{}, err
+		}
+
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return inventory.OutletProduct{}, err
+	}
+
+	p, err = s.productRepository.GetProduct(ctx, merchantID, entity.ProductID)
+	if err != nil {
+		if errors.Is(err, product.ErrProductNotFound) {
+			return inventory.OutletProduct{}, err
+		}
+
+		logger.Error(ctx, ops, "error getting product: %v", err)
+		return inventory.OutletProduct{}, err
+	}
+
+	if err = s.inventoryRepository.Assign(ctx, entity, userID); err != nil {
+		if errors.Is(err, inventory.ErrProductAlreadyAssigned) {
+			return inventory.OutletProduct{}, err
+		}
+
+		logger.Error(ctx, ops, "error assigning product to outlet: %v", err)
+		return inventory.OutletProduct{}, err
+	}
+
+	entity.SKU = p.SKU
+	entity.Name = p.Name
+	return entity, nil
+}
+
+func (s *inventoryService) GetOutletProducts(ctx context.Context, merchantID, outletID, lastID, limit int) (products []inventory.OutletProduct, totalData int, err error) {
+	const ops = "service.inventoryService.GetOutletProducts"
+	paginationOpts := inventory.RepositoryGetOutletProductPaginationOptions{
+		Limit:  limit,
+		Cursor: lastID,
+	}
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, outletID); err != nil {
+		if errors.Is(err, outlet.ErrOutletNotFound) {
+			return
+		}
+
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return
+	}
+
+	products, totalData, err = s.inventoryRepository.Get(ctx, outletID, &paginationOpts)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *inventoryService) SetPrice(ctx context.Context, merchantID, outletID, productID int, price float64) error {
+	const ops = "service.inventoryService.SetPrice"
+
+	if price < 0 {
+		return inventory.ErrInvalidInventoryParameters
+	}
+
+	if _, err := s.outletRepository.GetOutlet(ctx, merchantID, outletID); err != nil {
+		if errors.Is(err, outlet.ErrOutletNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return err
+	}
+
+	if err := s.inventoryRepository.UpdatePrice(ctx, outletID, productID, price); err != nil {
+		if errors.Is(err, inventory.ErrOutletProductNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error updating price: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *inventoryService) AdjustStock(ctx context.Context, merchantID int, movement inventory.StockMovement) (stock int, err error) {
+	const ops = "service.inventoryService.AdjustStock"
+
+	if movement.Quantity == 0 {
+		return 0, inventory.ErrInvalidInventoryParameters
+	}
+
+	if !movement.Reason.Valid() {
+		return 0, inventory.ErrInvalidStockReason
+	}
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, movement.OutletID); err != nil {
+		if errors.Is(err, outlet.ErrOutletNotFound) {
+			return 0, err
+		}
+
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return 0, err
+	}
+
+	stock, err = s.inventoryRepository.AdjustStock(ctx, movement)
+	if err != nil {
+		if errors.Is(err, inventory.ErrOutletProductNotFound) || errors.Is(err, inventory.ErrInsufficientStock) {
+			return 0, err
+		}
+
+		logger.Error(ctx, ops, "error adjusting stock: %v", err)
+		return 0, err
+	}
+
+	return stock, nil
+}
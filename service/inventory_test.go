@@ -0,0 +1,188 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	imock "github.com/mhdiiilham/POS/entity/inventory/mock"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	omock "github.com/mhdiiilham/POS/entity/outlet/mock"
+	"github.com/mhdiiilham/POS/entity/product"
+	pmock "github.com/mhdiiilham/POS/entity/product/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_inventoryService_AssignProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - negative price", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		_, err := s.AssignProduct(ctx, 1, 1, inventory.OutletProduct{OutletID: 1, ProductID: 1, Price: -1})
+		assert.ErrorIs(t, err, inventory.ErrInvalidInventoryParameters)
+	})
+
+	t.Run("failed - product of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		productRepository.
+			EXPECT().
+			GetProduct(ctx, 1, 3).
+			Return(product.Product{}, product.ErrProductNotFound).
+			Times(1)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		_, err := s.AssignProduct(ctx, 1, 1, inventory.OutletProduct{OutletID: 2, ProductID: 3, Price: 1000})
+		assert.ErrorIs(t, err, product.ErrProductNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := inventory.OutletProduct{OutletID: 2, ProductID: 3, Price: 1000, Stock: 5}
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		productRepository.
+			EXPECT().
+			GetProduct(ctx, 1, 3).
+			Return(product.Product{ID: 3, SKU: "SKU-3", Name: "Coffee"}, nil).
+			Times(1)
+
+		inventoryRepository.
+			EXPECT().
+			Assign(ctx, payload, 7).
+			Return(nil).
+			Times(1)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		resp, err := s.AssignProduct(ctx, 1, 7, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, "SKU-3", resp.SKU)
+		assert.Equal(t, 5, resp.Stock)
+	})
+}
+
+func Test_inventoryService_AdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - invalid reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		_, err := s.AdjustStock(ctx, 1, inventory.StockMovement{OutletID: 1, ProductID: 1, Quantity: 3, Reason: inventory.ReasonInitial})
+		assert.ErrorIs(t, err, inventory.ErrInvalidStockReason)
+	})
+
+	t.Run("failed - insufficient stock", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		movement := inventory.StockMovement{OutletID: 2, ProductID: 3, Quantity: -10, Reason: inventory.ReasonDamaged}
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		inventoryRepository.
+			EXPECT().
+			AdjustStock(ctx, movement).
+			Return(0, inventory.ErrInsufficientStock).
+			Times(1)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		_, err := s.AdjustStock(ctx, 1, movement)
+		assert.ErrorIs(t, err, inventory.ErrInsufficientStock)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		movement := inventory.StockMovement{OutletID: 2, ProductID: 3, Quantity: 12, Reason: inventory.ReasonRestock}
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		inventoryRepository.
+			EXPECT().
+			AdjustStock(ctx, movement).
+			Return(20, nil).
+			Times(1)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		stock, err := s.AdjustStock(ctx, 1, movement)
+		assert.NoError(t, err)
+		assert.Equal(t, 20, stock)
+	})
+}
+
+func Test_inventoryService_SetPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - outlet of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		inventoryRepository := imock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		productRepository := pmock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{}, outlet.ErrOutletNotFound).
+			Times(1)
+
+		s := service.NewInventoryService(inventoryRepository, outletRepository, productRepository)
+		err := s.SetPrice(ctx, 1, 2, 3, 1500)
+		assert.ErrorIs(t, err, outlet.ErrOutletNotFound)
+	})
+}
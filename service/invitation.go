@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/invitation"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type invitationService struct {
+	userRepository       user.Repository
+	roleRepository       role.Repository
+	invitationRepository invitation.Repository
+	hasher               Hasher
+	passwordPolicy       PasswordPolicy
+	mailer               Mailer
+	acceptURL            string
+}
+
+// NewInvitationService handles inviting employees by email. acceptURL is the
+// page that lets the invitee pick a password; the token is appended as
+// ?token=.
+func NewInvitationService(userRepository user.Repository, roleRepository role.Repository, invitationRepository invitation.Repository, pwdHasher Hasher, passwordPolicy PasswordPolicy, mailer Mailer, acceptURL string) *invitationService {
+	return &invitationService{
+		userRepository:       userRepository,
+		roleRepository:       roleRepository,
+		invitationRepository: invitationRepository,
+		hasher:               pwdHasher,
+		passwordPolicy:       passwordPolicy,
+		mailer:               mailer,
+		acceptURL:            acceptURL,
+	}
+}
+
+// Invite adds invitee to the merchant as a pending user and mails them an
+// invite link. As with CreateUser, invitees are cashiers unless a role is
+// given, and the grantor may only hand out a role whose permissions they
+// hold. When the mail cannot be sent the invitation is kept and can be
+// resent.
+func (s *invitationService) Invite(ctx context.Context, grantor []role.Permission, invitedBy int, invitee user.User) (created invitation.Invitation, err error) {
+	const ops = "service.invitationService.Invite"
+	var u *user.User
+	var r role.Role
+	var invitationID, userID int64
+	now := time.Now()
+
+	invitee.Email = strings.TrimSpace(invitee.Email)
+	invitee.FirstName = strings.TrimSpace(invitee.FirstName)
+	if invitee.Email == "" || invitee.FirstName == "" {
+		return invitation.Invitation{}, user.ErrInvalidCreateParameters
+	}
+
+	if invitee.RoleID == 0 {
+		invitee.RoleID = role.CashierRoleID
+	}
+
+	u, err = s.userRepository.FindUserByEmail(ctx, invitee.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error(ctx, ops, "unexpected error happened %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	if u != nil {
+		return invitation.Invitation{}, user.ErrEmailNotUnique
+	}
+
+	r, err = s.roleRepository.GetRole(ctx, invitee.MerchantID, invitee.RoleID)
+	if err != nil {
+		if !errors.Is(err, role.ErrRoleNotFound) {
+			logger.Error(ctx, ops, "error getting role: %v", err)
+		}
+		return invitation.Invitation{}, err
+	}
+
+	if !role.Covers(grantor, r.Permissions) {
+		return invitation.Invitation{}, role.ErrInsufficientPermissions
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating invite token: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	created = invitation.Invitation{
+		MerchantID: invitee.MerchantID,
+		InvitedBy:  invitedBy,
+		Email:      invitee.Email,
+		FirstName:  invitee.FirstName,
+		RoleID:     invitee.RoleID,
+		TokenHash:  hashSecret(secret),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(invitation.TTL),
+	}
+
+	invitationID, userID, err = s.invitationRepository.Create(ctx, created, invitee)
+	if err != nil {
+		if errors.Is(err, user.ErrEmailNotUnique) {
+			return invitation.Invitation{}, err
+		}
+
+		logger.Error(ctx, ops, "error storing invitation: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	created.ID, created.UserID = int(invitationID), int(userID)
+	if err = s.sendInvite(ctx, created, secret); err != nil {
+		logger.Error(ctx, ops, "error sending invite email: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	return created, nil
+}
+
+// GetInvitations returns the merchant's invitations that have not been
+// accepted yet.
+func (s *invitationService) GetInvitations(ctx context.Context, merchantID int) (invitations []invitation.Invitation, err error) {
+	const ops = "service.invitationService.GetInvitations"
+
+	invitations, err = s.invitationRepository.Get(ctx, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting invitations: %v", err)
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// ResendInvitation mails a new invite link, which replaces the earlier one
+// and starts a fresh TTL, so expired invitations can be revived.
+func (s *invitationService) ResendInvitation(ctx context.Context, merchantID, invitationID int) (entity invitation.Invitation, err error) {
+	const ops = "service.invitationService.ResendInvitation"
+	now := time.Now()
+
+	entity, err = s.invitationRepository.GetInvitation(ctx, merchantID, invitationID)
+	if err != nil {
+		if errors.Is(err, invitation.ErrInvitationNotFound) {
+			return invitation.Invitation{}, err
+		}
+
+		logger.Error(ctx, ops, "error getting invitation: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating invite token: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	entity.TokenHash = hashSecret(secret)
+	entity.ExpiresAt = now.Add(invitation.TTL)
+	if err = s.invitationRepository.Renew(ctx, merchantID, invitationID, entity.TokenHash, entity.ExpiresAt); err != nil {
+		if errors.Is(err, invitation.ErrInvitationNotFound) {
+			return invitation.Invitation{}, err
+		}
+
+		logger.Error(ctx, ops, "error renewing invitation: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	if err = s.sendInvite(ctx, entity, secret); err != nil {
+		logger.Error(ctx, ops, "error sending invite email: %v", err)
+		return invitation.Invitation{}, err
+	}
+
+	return entity, nil
+}
+
+// RevokeInvitation withdraws an invitation that has not been accepted and
+// removes its pending user.
+func (s *invitationService) RevokeInvitation(ctx context.Context, merchantID, invitationID int) (err error) {
+	const ops = "service.invitationService.RevokeInvitation"
+
+	if err = s.invitationRepository.Revoke(ctx, merchantID, invitationID); err != nil {
+		if errors.Is(err, invitation.ErrInvitationNotFound) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error revoking invitation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// AcceptInvitation sets the invitee's password with a token from their
+// invite and activates the account. The password policy applies as it does
+// to every other password.
+func (s *invitationService) AcceptInvitation(ctx context.Context, token, password string) (err error) {
+	const ops = "service.invitationService.AcceptInvitation"
+	var hashedPwd string
+	var u user.User
+
+	if token == "" {
+		return invitation.ErrInvalidInviteToken
+	}
+
+	u, err = s.invitationRepository.FindUser(ctx, hashSecret(token))
+	if err != nil {
+		if errors.Is(err, invitation.ErrInvalidInviteToken) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error finding invite user: %v", err)
+		return err
+	}
+
+	if err = s.passwordPolicy.Check(password, personalInfo(u)...); err != nil {
+		return err
+	}
+
+	hashedPwd, err = s.hasher.HashPassword(ctx, password)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to hash password: %v", err)
+		return err
+	}
+
+	if _, err = s.invitationRepository.Accept(ctx, hashSecret(token), hashedPwd); err != nil {
+		if errors.Is(err, invitation.ErrInvalidInviteToken) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error accepting invitation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *invitationService) sendInvite(ctx context.Context, entity invitation.Invitation, secret string) error {
+	body := fmt.Sprintf(
+		"Hi %s,\n\nYou have been invited to join your team on POS. Open the link below within %d days to choose your password:\n\n%s?token=%s\n\nIf you were not expecting this, ignore this email.",
+		entity.FirstName, int(invitation.TTL.Hours()/24), s.acceptURL, secret,
+	)
+	return s.mailer.Send(ctx, entity.Email, "You're invited to POS", body)
+}
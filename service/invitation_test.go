@@ -0,0 +1,311 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/invitation"
+	ivmock "github.com/mhdiiilham/POS/entity/invitation/mock"
+	"github.com/mhdiiilham/POS/entity/role"
+	romock "github.com/mhdiiilham/POS/entity/role/mock"
+	"github.com/mhdiiilham/POS/entity/user"
+	umock "github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/pkg/password"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const acceptURL = "https://pos.example/accept-invitation"
+
+// mailedToken returns the token of the link in an invite email.
+func mailedToken(t *testing.T, body string) string {
+	i := strings.Index(body, acceptURL+"?token=")
+	if !assert.NotEqual(t, -1, i) {
+		return ""
+	}
+	return strings.Fields(body[i+len(acceptURL+"?token="):])[0]
+}
+
+func Test_invitationService_Invite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := []role.Permission{role.PermissionUserRead, role.PermissionUserWrite, role.PermissionSaleCreate}
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		_, err := s.Invite(ctx, manager, 2, user.User{MerchantID: 1, Email: " ", FirstName: "Dewi"})
+		assert.ErrorIs(t, err, user.ErrInvalidCreateParameters)
+	})
+
+	t.Run("email already registered", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, "dewi@shop.id").
+			Return(&user.User{ID: 9, Pending: true}, nil).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		_, err := s.Invite(ctx, manager, 2, user.User{MerchantID: 1, Email: "dewi@shop.id", FirstName: "Dewi"})
+		assert.ErrorIs(t, err, user.ErrEmailNotUnique)
+	})
+
+	t.Run("role the grantor does not cover", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, "dewi@shop.id").
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 1, role.OwnerRoleID).
+			Return(role.Role{ID: role.OwnerRoleID, Permissions: role.Permissions}, nil).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		_, err := s.Invite(ctx, manager, 2, user.User{MerchantID: 1, Email: "dewi@shop.id", FirstName: "Dewi", RoleID: role.OwnerRoleID})
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("success - pending cashier is mailed a link whose token hash is stored", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var stored invitation.Invitation
+		var pending user.User
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, "dewi@shop.id").
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 1, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Permissions: []role.Permission{role.PermissionSaleCreate}}, nil).
+			Times(1)
+
+		invitationRepository.
+			EXPECT().
+			Create(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity invitation.Invitation, invitee user.User) (int64, int64, error) {
+				stored, pending = entity, invitee
+				return 4, 9, nil
+			}).
+			Times(1)
+
+		mailer.
+			EXPECT().
+			Send(ctx, "dewi@shop.id", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, body string) error {
+				assert.Equal(t, refreshHash(mailedToken(t, body)), stored.TokenHash)
+				return nil
+			}).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		created, err := s.Invite(ctx, manager, 2, user.User{MerchantID: 1, Email: "dewi@shop.id", FirstName: "Dewi"})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, created.ID)
+		assert.Equal(t, 9, created.UserID)
+		assert.Equal(t, 2, created.InvitedBy)
+		assert.Equal(t, role.CashierRoleID, pending.RoleID)
+		assert.Empty(t, pending.Password)
+		assert.WithinDuration(t, time.Now().Add(invitation.TTL), stored.ExpiresAt, time.Minute)
+	})
+}
+
+func Test_invitationService_ResendInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("invitation of another merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		invitationRepository.
+			EXPECT().
+			GetInvitation(ctx, 1, 4).
+			Return(invitation.Invitation{}, invitation.ErrInvitationNotFound).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		_, err := s.ResendInvitation(ctx, 1, 4)
+		assert.ErrorIs(t, err, invitation.ErrInvitationNotFound)
+	})
+
+	t.Run("success - expired invitation gets a new token and expiry", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var renewedHash string
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		invitationRepository.
+			EXPECT().
+			GetInvitation(ctx, 1, 4).
+			Return(invitation.Invitation{
+				ID:         4,
+				MerchantID: 1,
+				Email:      "dewi@shop.id",
+				FirstName:  "Dewi",
+				TokenHash:  refreshHash("old-token"),
+				ExpiresAt:  time.Now().Add(-time.Hour),
+			}, nil).
+			Times(1)
+
+		invitationRepository.
+			EXPECT().
+			Renew(ctx, 1, 4, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ int, tokenHash string, expiresAt time.Time) error {
+				renewedHash = tokenHash
+				assert.WithinDuration(t, time.Now().Add(invitation.TTL), expiresAt, time.Minute)
+				return nil
+			}).
+			Times(1)
+
+		mailer.
+			EXPECT().
+			Send(ctx, "dewi@shop.id", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, body string) error {
+				assert.Equal(t, refreshHash(mailedToken(t, body)), renewedHash)
+				return nil
+			}).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		entity, err := s.ResendInvitation(ctx, 1, 4)
+		assert.NoError(t, err)
+		assert.NotEqual(t, refreshHash("old-token"), renewedHash)
+		assert.False(t, entity.Expired(time.Now()))
+	})
+}
+
+func Test_invitationService_AcceptInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("used, revoked or expired token", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		invitationRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{}, invitation.ErrInvalidInviteToken).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		err := s.AcceptInvitation(ctx, "token", "a-fine-password")
+		assert.ErrorIs(t, err, invitation.ErrInvalidInviteToken)
+	})
+
+	t.Run("password breaks the policy", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		invitationRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{ID: 9, Email: "dewi@shop.id", FirstName: "Dewi", Pending: true}, nil).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, password.Policy{RejectCommon: true}, mailer, acceptURL)
+		err := s.AcceptInvitation(ctx, "token", "iloveyou1")
+		assert.ErrorIs(t, err, user.ErrInvalidPassword)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		invitationRepository := ivmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+
+		invitationRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{ID: 9, Email: "dewi@shop.id", FirstName: "Dewi", Pending: true}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, "a-fine-password").
+			Return("hashed", nil).
+			Times(1)
+
+		invitationRepository.
+			EXPECT().
+			Accept(ctx, refreshHash("token"), "hashed").
+			Return(9, nil).
+			Times(1)
+
+		s := service.NewInvitationService(userRepository, roleRepository, invitationRepository, hasher, passwordPolicy, mailer, acceptURL)
+		err := s.AcceptInvitation(ctx, "token", "a-fine-password")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mhdiiilham/POS/entity/merchant"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type merchantService struct {
+	transactor         Transactor
+	merchantRepository merchant.Repository
+	userRepository     user.Repository
+	sessionRepository  session.Repository
+	hasher             Hasher
+	passwordPolicy     PasswordPolicy
+	tokenSigner        TokenSigner
+}
+
+func NewMerchantService(transactor Transactor, merchantRepository merchant.Repository, userRepository user.Repository, sessionRepository session.Repository, pwdHasher Hasher, passwordPolicy PasswordPolicy, tokenSigner TokenSigner) *merchantService {
+	return &merchantService{
+		transactor:         transactor,
+		merchantRepository: merchantRepository,
+		userRepository:     userRepository,
+		sessionRepository:  sessionRepository,
+		hasher:             pwdHasher,
+		passwordPolicy:     passwordPolicy,
+		tokenSigner:        tokenSigner,
+	}
+}
+
+// Register creates a new merchant together with its owner account and
+// starts a session for the owner, so a shop can onboard itself.
+func (s *merchantService) Register(ctx context.Context, entity merchant.Merchant, owner user.User) (registered merchant.Merchant, createdOwner user.User, token session.Token, err error) {
+	const ops = "service.merchantService.Register"
+	var hashedPwd string
+	var merchantID, userID int64
+	var u *user.User
+
+	if entity.Name == "" {
+		return merchant.Merchant{}, user.User{}, session.Token{}, merchant.ErrInvalidMerchantParameters
+	}
+
+	if owner.Email == "" || owner.FirstName == "" {
+		return merchant.Merchant{}, user.User{}, session.Token{}, user.ErrInvalidCreateParameters
+	}
+
+	if err = s.passwordPolicy.Check(owner.Password, personalInfo(owner)...); err != nil {
+		return merchant.Merchant{}, user.User{}, session.Token{}, err
+	}
+
+	u, err = s.userRepository.FindUserByEmail(ctx, owner.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error(ctx, ops, "unexpected error happened %v", err)
+		return merchant.Merchant{}, user.User{}, session.Token{}, err
+	}
+
+	if u != nil {
+		return merchant.Merchant{}, user.User{}, session.Token{}, user.ErrEmailNotUnique
+	}
+
+	hashedPwd, err = s.hasher.HashPassword(ctx, owner.Password)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to hash password: %v", err)
+		return merchant.Merchant{}, user.User{}, session.Token{}, err
+	}
+
+	owner.Password = hashedPwd
+	owner.RoleID = role.OwnerRoleID
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if merchantID, err = s.merchantRepository.Create(ctx, entity); err != nil {
+			return err
+		}
+
+		owner.MerchantID = int(merchantID)
+		userID, err = s.userRepository.Create(ctx, owner)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrEmailNotUnique) {
+			return merchant.Merchant{}, user.User{}, session.Token{}, err
+		}
+
+		logger.Error(ctx, ops, "error registering merchant: %v", err)
+		return merchant.Merchant{}, user.User{}, session.Token{}, err
+	}
+
+	entity.ID = int(merchantID)
+	owner.ID = int(userID)
+	owner.Role = "owner"
+	owner.Permissions = role.Permissions
+
+	token, err = startSession(ctx, s.sessionRepository, s.tokenSigner, owner, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to start session %v", err)
+		return merchant.Merchant{}, user.User{}, session.Token{}, err
+	}
+
+	return entity, owner, token, nil
+}
+
+// CreateMerchant and GetMerchants work across tenants and are only routed for
+// platform admins; merchants onboard themselves through Register.
+func (s *merchantService) CreateMerchant(ctx context.Context, entity merchant.Merchant) (merchantID int, err error) {
+	const ops = "service.merchantService.CreateMerchant"
+	var insertedID int64
+
+	if entity.Name == "" {
+		return 0, merchant.ErrInvalidMerchantParameters
+	}
+
+	insertedID, err = s.merchantRepository.Create(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to insert entity to db: %v", err)
+		return 0, err
+	}
+
+	return int(insertedID), nil
+}
+
+func (s *merchantService) GetMerchants(ctx context.Context, lastID, limit int) (merchants []merchant.Merchant, totalData int, err error) {
+	const ops = "service.merchantService.GetMerchants"
+	paginationOpts := merchant.RepositoryGetMerchantPaginationOptions{
+		Limit:  limit,
+		Cursor: lastID,
+	}
+
+	merchants, totalData, err = s.merchantRepository.Get(ctx, &paginationOpts)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+// GetMerchant returns the requester's own merchant; other merchants are
+// forbidden.
+func (s *merchantService) GetMerchant(ctx context.Context, requesterMerchantID, merchantID int) (entity merchant.Merchant, err error) {
+	const ops = "service.merchantService.GetMerchant"
+
+	if merchantID != requesterMerchantID {
+		return merchant.Merchant{}, merchant.ErrForbiddenMerchant
+	}
+
+	entity, err = s.merchantRepository.GetMerchant(ctx, merchantID)
+	if err != nil {
+		if errors.Is(err, merchant.ErrMerchantNotFound) {
+			return merchant.Merchant{}, err
+		}
+
+		logger.Error(ctx, ops, "unknown error: %v", err)
+		return merchant.Merchant{}, err
+	}
+
+	return entity, nil
+}
+
+func (s *merchantService) UpdateMerchant(ctx context.Context, requesterMerchantID int, entity merchant.Merchant) error {
+	const ops = "service.merchantService.UpdateMerchant"
+
+	if entity.Name == "" {
+		return merchant.ErrInvalidMerchantParameters
+	}
+
+	if entity.ID != requesterMerchantID {
+		return merchant.ErrForbiddenMerchant
+	}
+
+	if err := s.merchantRepository.Update(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error updating merchant %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,458 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/merchant"
+	"github.com/mhdiiilham/POS/entity/merchant/mock"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
+	sesmock "github.com/mhdiiilham/POS/entity/session/mock"
+	"github.com/mhdiiilham/POS/entity/user"
+	umock "github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_merchantService_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - invalid owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		_, _, at, err := s.Register(ctx, merchant.Merchant{Name: faker.Name()}, user.User{Email: faker.Email()})
+		assert.Empty(t, at)
+		assert.ErrorIs(t, err, user.ErrInvalidCreateParameters)
+	})
+
+	t.Run("failed - email not unique", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		owner := user.User{Email: faker.Email(), FirstName: faker.FirstName(), Password: faker.Password()}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, owner.Email).
+			Return(&user.User{ID: 1}, nil).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		_, _, at, err := s.Register(ctx, merchant.Merchant{Name: faker.Name()}, owner)
+		assert.Empty(t, at)
+		assert.ErrorIs(t, err, user.ErrEmailNotUnique)
+	})
+
+	t.Run("failed - transaction error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		password := faker.Password()
+		entity := merchant.Merchant{Name: faker.Name()}
+		owner := user.User{Email: faker.Email(), FirstName: faker.FirstName(), Password: password}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, owner.Email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, password).
+			Return("hashed", nil).
+			Times(1)
+
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		merchantRepository.
+			EXPECT().
+			Create(ctx, entity).
+			Return(int64(0), sql.ErrTxDone).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		_, _, at, err := s.Register(ctx, entity, owner)
+		assert.Empty(t, at)
+		assert.ErrorIs(t, err, sql.ErrTxDone)
+	})
+
+	t.Run("failed - owner email taken concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		password := faker.Password()
+		entity := merchant.Merchant{Name: faker.Name()}
+		owner := user.User{Email: faker.Email(), FirstName: faker.FirstName(), Password: password}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, owner.Email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, password).
+			Return("hashed", nil).
+			Times(1)
+
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		merchantRepository.
+			EXPECT().
+			Create(ctx, entity).
+			Return(int64(3), nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(int64(0), user.ErrEmailNotUnique).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		_, _, at, err := s.Register(ctx, entity, owner)
+		assert.Empty(t, at)
+		assert.ErrorIs(t, err, user.ErrEmailNotUnique)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		password := faker.Password()
+		jwt := faker.Jwt()
+		entity := merchant.Merchant{Name: faker.Name()}
+		owner := user.User{Email: faker.Email(), FirstName: faker.FirstName(), Password: password}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(ctx, owner.Email).
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, password).
+			Return("hashed", nil).
+			Times(1)
+
+		hashedOwner := owner
+		hashedOwner.Password = "hashed"
+		hashedOwner.RoleID = role.OwnerRoleID
+		hashedOwner.MerchantID = 3
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		merchantRepository.
+			EXPECT().
+			Create(ctx, entity).
+			Return(int64(3), nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			Create(ctx, hashedOwner).
+			Return(int64(9), nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		tokenSigner.
+			EXPECT().
+			Sign(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, identity session.Identity) (string, error) {
+				assert.Equal(t, 9, identity.UserID)
+				assert.Equal(t, 3, identity.MerchantID)
+				assert.Equal(t, "owner", identity.Role)
+				assert.Equal(t, role.Permissions, identity.Permissions)
+				return jwt, nil
+			}).
+			Return(jwt, nil).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		m, u, token, err := s.Register(ctx, entity, owner)
+		assert.NoError(t, err)
+		assert.Equal(t, jwt, token.AccessToken)
+		assert.Equal(t, 3, m.ID)
+		assert.Equal(t, 9, u.ID)
+		assert.Equal(t, 3, u.MerchantID)
+	})
+}
+
+func Test_merchantService_CreateMerchant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - name is empty", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		resp, err := s.CreateMerchant(ctx, merchant.Merchant{})
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, merchant.ErrInvalidMerchantParameters)
+	})
+
+	t.Run("failed - inserting to DB", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := merchant.Merchant{Name: faker.Name()}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		merchantRepository.
+			EXPECT().
+			Create(ctx, payload).
+			Return(int64(0), sql.ErrConnDone).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		resp, err := s.CreateMerchant(ctx, payload)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := merchant.Merchant{Name: faker.Name()}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		merchantRepository.
+			EXPECT().
+			Create(ctx, payload).
+			Return(int64(7), nil).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		resp, err := s.CreateMerchant(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, resp)
+	})
+}
+
+func Test_merchantService_GetMerchants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		opts := merchant.RepositoryGetMerchantPaginationOptions{
+			Limit:  2,
+			Cursor: 4,
+		}
+
+		merchantRepository.
+			EXPECT().
+			Get(ctx, &opts).
+			Return([]merchant.Merchant{{ID: 5}, {ID: 6}}, 12, nil).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		merchants, totalData, err := s.GetMerchants(ctx, opts.Cursor, opts.Limit)
+		assert.NoError(t, err)
+		assert.Len(t, merchants, 2)
+		assert.Equal(t, 12, totalData)
+	})
+}
+
+func Test_merchantService_GetMerchant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		entity, err := s.GetMerchant(ctx, 1, 2)
+		assert.Empty(t, entity)
+		assert.ErrorIs(t, err, merchant.ErrForbiddenMerchant)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		merchantRepository.
+			EXPECT().
+			GetMerchant(ctx, 1).
+			Return(merchant.Merchant{ID: 1, Name: "Kopi"}, nil).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		entity, err := s.GetMerchant(ctx, 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, merchant.Merchant{ID: 1, Name: "Kopi"}, entity)
+	})
+}
+
+func Test_merchantService_UpdateMerchant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		err := s.UpdateMerchant(ctx, 1, merchant.Merchant{ID: 2, Name: faker.Name()})
+		assert.ErrorIs(t, err, merchant.ErrForbiddenMerchant)
+	})
+
+	t.Run("failed - merchant not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := merchant.Merchant{ID: 1, Name: faker.Name()}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		merchantRepository.
+			EXPECT().
+			Update(ctx, payload).
+			Return(merchant.ErrMerchantNotFound).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		err := s.UpdateMerchant(ctx, 1, payload)
+		assert.ErrorIs(t, err, merchant.ErrMerchantNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := merchant.Merchant{ID: 1, Name: faker.Name()}
+		transactor := smock.NewMockTransactor(ctrl)
+		merchantRepository := mock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		merchantRepository.
+			EXPECT().
+			Update(ctx, payload).
+			Return(nil).
+			Times(1)
+
+		s := service.NewMerchantService(transactor, merchantRepository, userRepository, sessionRepository, hasher, passwordPolicy, tokenSigner)
+		err := s.UpdateMerchant(ctx, 1, payload)
+		assert.NoError(t, err)
+	})
+}
+
+// inTransaction stands in for Transactor.WithinTransaction by running fn
+// directly.
+func inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type outletService struct {
+	outletRepository outlet.Repository
+}
+
+func NewOutletService(outletRepository outlet.Repository) *outletService {
+	return &outletService{
+		outletRepository: outletRepository,
+	}
+}
+
+func (s *outletService) CreateOutlet(ctx context.Context, entity outlet.Outlet) (outletID int, err error) {
+	const ops = "service.outletService.CreateOutlet"
+	var insertedID int64
+
+	if entity.Name == "" || entity.MerchantID == 0 {
+		return 0, outlet.ErrInvalidOutletParameters
+	}
+
+	insertedID, err = s.outletRepository.Create(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to insert entity to db: %v", err)
+		return 0, err
+	}
+
+	return int(insertedID), nil
+}
+
+func (s *outletService) GetOutlets(ctx context.Context, merchantID, lastID, limit int) (outlets []outlet.Outlet, totalData int, err error) {
+	const ops = "service.outletService.GetOutlets"
+	paginationOpts := outlet.RepositoryGetOutletPaginationOptions{
+		Limit:  limit,
+		Cursor: lastID,
+	}
+
+	outlets, totalData, err = s.outletRepository.Get(ctx, merchantID, &paginationOpts)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *outletService) GetOutlet(ctx context.Context, merchantID, outletID int) (entity outlet.Outlet, err error) {
+	const ops = "service.outletService.GetOutlet"
+
+	entity, err = s.outletRepository.GetOutlet(ctx, merchantID, outletID)
+	if err != nil {
+		logger.Error(ctx, ops, "unknown error: %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *outletService) UpdateOutlet(ctx context.Context, entity outlet.Outlet) error {
+	const ops = "service.outletService.UpdateOutlet"
+
+	if entity.Name == "" {
+		return outlet.ErrInvalidOutletParameters
+	}
+
+	if err := s.outletRepository.Update(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error updating outlet %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *outletService) DeleteOutlet(ctx context.Context, merchantID, outletID int) error {
+	const ops = "service.outletService.DeleteOutlet"
+
+	if err := s.outletRepository.Remove(ctx, merchantID, outletID); err != nil {
+		logger.Error(ctx, ops, "error removing outlet %v", err)
+		return err
+	}
+
+	return nil
+}
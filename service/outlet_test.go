@@ -0,0 +1,209 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/outlet/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_outletService_CreateOutlet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - name is empty", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		s := service.NewOutletService(outletRepository)
+		resp, err := s.CreateOutlet(ctx, outlet.Outlet{MerchantID: 1})
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, outlet.ErrInvalidOutletParameters)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := outlet.Outlet{MerchantID: 1, Name: faker.Name()}
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			Create(ctx, payload).
+			Return(int64(4), nil).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		resp, err := s.CreateOutlet(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, resp)
+	})
+}
+
+func Test_outletService_GetOutlets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - db error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+		opts := outlet.RepositoryGetOutletPaginationOptions{Limit: 10}
+
+		outletRepository.
+			EXPECT().
+			Get(ctx, 1, &opts).
+			Return(nil, 0, sql.ErrConnDone).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		outlets, totalData, err := s.GetOutlets(ctx, 1, opts.Cursor, opts.Limit)
+		assert.Empty(t, outlets)
+		assert.Empty(t, totalData)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+		opts := outlet.RepositoryGetOutletPaginationOptions{Limit: 2, Cursor: 3}
+
+		outletRepository.
+			EXPECT().
+			Get(ctx, 1, &opts).
+			Return([]outlet.Outlet{{ID: 4}, {ID: 5}}, 5, nil).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		outlets, totalData, err := s.GetOutlets(ctx, 1, opts.Cursor, opts.Limit)
+		assert.NoError(t, err)
+		assert.Len(t, outlets, 2)
+		assert.Equal(t, 5, totalData)
+	})
+}
+
+func Test_outletService_GetOutlet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - outlet of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 2, 9).
+			Return(outlet.Outlet{}, outlet.ErrOutletNotFound).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		resp, err := s.GetOutlet(ctx, 2, 9)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, outlet.ErrOutletNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 9).
+			Return(outlet.Outlet{ID: 9, MerchantID: 1}, nil).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		resp, err := s.GetOutlet(ctx, 1, 9)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, resp.ID)
+	})
+}
+
+func Test_outletService_UpdateOutlet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - name is empty", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		s := service.NewOutletService(outletRepository)
+		err := s.UpdateOutlet(ctx, outlet.Outlet{ID: 1, MerchantID: 1})
+		assert.ErrorIs(t, err, outlet.ErrInvalidOutletParameters)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := outlet.Outlet{ID: 1, MerchantID: 1, Name: faker.Name()}
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			Update(ctx, payload).
+			Return(nil).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		err := s.UpdateOutlet(ctx, payload)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_outletService_DeleteOutlet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - outlet not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			Remove(ctx, 1, 3).
+			Return(outlet.ErrOutletNotFound).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		err := s.DeleteOutlet(ctx, 1, 3)
+		assert.ErrorIs(t, err, outlet.ErrOutletNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletRepository := mock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			Remove(ctx, 1, 3).
+			Return(nil).
+			Times(1)
+
+		s := service.NewOutletService(outletRepository)
+		err := s.DeleteOutlet(ctx, 1, 3)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/passwordreset"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type passwordService struct {
+	userRepository          user.Repository
+	passwordResetRepository passwordreset.Repository
+	sessionRepository       session.Repository
+	hasher                  Hasher
+	passwordPolicy          PasswordPolicy
+	mailer                  Mailer
+	requestAttempts         LoginAttemptStore
+	resetURL                string
+}
+
+// NewPasswordService handles forgotten passwords. resetURL is the page that
+// lets the user pick a new password; the token is appended as ?token=.
+// requestAttempts counts reset requests to throttle them.
+func NewPasswordService(userRepository user.Repository, passwordResetRepository passwordreset.Repository, sessionRepository session.Repository, pwdHasher Hasher, passwordPolicy PasswordPolicy, mailer Mailer, requestAttempts LoginAttemptStore, resetURL string) *passwordService {
+	return &passwordService{
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		sessionRepository:       sessionRepository,
+		hasher:                  pwdHasher,
+		passwordPolicy:          passwordPolicy,
+		mailer:                  mailer,
+		requestAttempts:         requestAttempts,
+		resetURL:                resetURL,
+	}
+}
+
+// ForgotPassword mails a reset link to the user with that email. Requests
+// are throttled per email and per client IP with a LockoutError. Past the
+// throttle it returns at once and looks the email up in the background, so
+// neither the answer nor how long it takes tells who has an account.
+func (s *passwordService) ForgotPassword(ctx context.Context, email, clientIP string) (err error) {
+	const ops = "service.passwordService.ForgotPassword"
+	now := time.Now()
+	email = strings.TrimSpace(email)
+
+	if err = s.throttleRequest(ctx, now, email, clientIP); err != nil {
+		var lockout *user.LockoutError
+		if !errors.As(err, &lockout) {
+			logger.Error(ctx, ops, "error throttling reset request: %v", err)
+		}
+		return err
+	}
+
+	// The request context ends with the response, so the background work
+	// only keeps its request ID.
+	go s.sendResetLink(context.WithValue(context.Background(), logger.RequestIDKey, ctx.Value(logger.RequestIDKey)), email)
+	return nil
+}
+
+// throttleRequest counts the request against the email and the client IP,
+// or returns a LockoutError while either has to wait.
+func (s *passwordService) throttleRequest(ctx context.Context, now time.Time, email, clientIP string) error {
+	return takeAttempts(ctx, s.requestAttempts, now, passwordreset.RequestWindow,
+		attemptLimit{"reset:" + loginAccountKey(email), passwordreset.AccountFreeRequests},
+		attemptLimit{"reset:ip:" + clientIP, passwordreset.IPFreeRequests},
+	)
+}
+
+// sendResetLink does the work of ForgotPassword. Unknown emails and invited
+// users are skipped, and failures are only logged.
+func (s *passwordService) sendResetLink(ctx context.Context, email string) {
+	const ops = "service.passwordService.sendResetLink"
+	now := time.Now()
+
+	u, err := s.userRepository.FindUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Info(ctx, ops, "password reset requested for unknown email")
+			return
+		}
+
+		logger.Error(ctx, ops, "error trying to find user by email: %v", err)
+		return
+	}
+
+	if u.Pending {
+		logger.Info(ctx, ops, "password reset requested for user %d with a pending invitation", u.ID)
+		return
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating reset token: %v", err)
+		return
+	}
+
+	entity := passwordreset.Token{
+		UserID:    u.ID,
+		TokenHash: hashSecret(secret),
+		CreatedAt: now,
+		ExpiresAt: now.Add(passwordreset.TTL),
+	}
+	if _, err = s.passwordResetRepository.Create(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error storing reset token: %v", err)
+		return
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below within %d minutes to choose a new one:\n\n%s?token=%s\n\nIf it was not you, ignore this email and your password stays the same.",
+		u.FirstName, int(passwordreset.TTL.Minutes()), s.resetURL, secret,
+	)
+	if err = s.mailer.Send(ctx, u.Email, "Reset your password", body); err != nil {
+		logger.Error(ctx, ops, "error sending reset email: %v", err)
+	}
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword and
+// signs the user out everywhere.
+func (s *passwordService) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	const ops = "service.passwordService.ResetPassword"
+	var hashedPwd string
+	var userID int
+	var u user.User
+
+	if token == "" {
+		return passwordreset.ErrInvalidResetToken
+	}
+
+	u, err = s.passwordResetRepository.FindUser(ctx, hashSecret(token))
+	if err != nil {
+		if errors.Is(err, passwordreset.ErrInvalidResetToken) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error finding token user: %v", err)
+		return err
+	}
+
+	if err = s.passwordPolicy.Check(newPassword, personalInfo(u)...); err != nil {
+		return err
+	}
+
+	hashedPwd, err = s.hasher.HashPassword(ctx, newPassword)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to hash password: %v", err)
+		return err
+	}
+
+	userID, err = s.passwordResetRepository.Redeem(ctx, hashSecret(token), hashedPwd)
+	if err != nil {
+		if errors.Is(err, passwordreset.ErrInvalidResetToken) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error redeeming reset token: %v", err)
+		return err
+	}
+
+	if err = s.sessionRepository.RevokeUserSessions(ctx, userID); err != nil {
+		logger.Error(ctx, ops, "error revoking sessions %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// personalInfo is what a user's password may not contain under a policy
+// that rejects personal information.
+func personalInfo(u user.User) []string {
+	info := []string{u.Email, u.FirstName}
+	if u.LastName != nil {
+		info = append(info, *u.LastName)
+	}
+	return info
+}
@@ -0,0 +1,343 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/passwordreset"
+	prmock "github.com/mhdiiilham/POS/entity/passwordreset/mock"
+	sesmock "github.com/mhdiiilham/POS/entity/session/mock"
+	"github.com/mhdiiilham/POS/entity/user"
+	umock "github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/pkg/password"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const resetURL = "https://pos.example/reset-password"
+
+func Test_passwordService_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("throttled email", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(user.LoginBackoffBase)
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		requestAttempts.
+			EXPECT().
+			Take(ctx, "reset:account:cashier@shop.id", passwordreset.AccountFreeRequests, gomock.Any(), passwordreset.RequestWindow).
+			Return(user.LoginAttempts{Failures: passwordreset.AccountFreeRequests}, &user.LockoutError{Until: until}).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ForgotPassword(ctx, "Cashier@shop.id", "10.0.0.1")
+
+		var lockout *user.LockoutError
+		assert.ErrorAs(t, err, &lockout)
+		assert.Equal(t, until, lockout.Until)
+	})
+
+	t.Run("unknown email is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		done := make(chan struct{})
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		allowResetRequest(ctx, requestAttempts, "nobody@shop.id")
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(gomock.Any(), "nobody@shop.id").
+			DoAndReturn(func(context.Context, string) (*user.User, error) {
+				close(done)
+				return nil, sql.ErrNoRows
+			}).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ForgotPassword(ctx, "nobody@shop.id", "10.0.0.1")
+		assert.NoError(t, err)
+		waitFor(t, done)
+	})
+
+	t.Run("invited user without a password is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		done := make(chan struct{})
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		allowResetRequest(ctx, requestAttempts, "invited@shop.id")
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(gomock.Any(), "invited@shop.id").
+			DoAndReturn(func(context.Context, string) (*user.User, error) {
+				close(done)
+				return &user.User{ID: 9, Email: "invited@shop.id", FirstName: "Dewi", Pending: true}, nil
+			}).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ForgotPassword(ctx, "invited@shop.id", "10.0.0.1")
+		assert.NoError(t, err)
+		waitFor(t, done)
+	})
+
+	t.Run("failing mail is not reported to the caller", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		done := make(chan struct{})
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		allowResetRequest(ctx, requestAttempts, "cashier@shop.id")
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(gomock.Any(), "cashier@shop.id").
+			Return(&user.User{ID: 7, Email: "cashier@shop.id", FirstName: "Ani"}, nil).
+			Times(1)
+
+		passwordResetRepository.
+			EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Return(int64(1), nil).
+			Times(1)
+
+		mailer.
+			EXPECT().
+			Send(gomock.Any(), "cashier@shop.id", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, string, string, string) error {
+				close(done)
+				return errors.New("smtp: connection refused")
+			}).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ForgotPassword(ctx, "cashier@shop.id", "10.0.0.1")
+		assert.NoError(t, err)
+		waitFor(t, done)
+	})
+
+	t.Run("success - mails a link whose token hash is stored", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		done := make(chan struct{})
+		var stored passwordreset.Token
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+		allowResetRequest(ctx, requestAttempts, "cashier@shop.id")
+
+		userRepository.
+			EXPECT().
+			FindUserByEmail(gomock.Any(), "cashier@shop.id").
+			Return(&user.User{ID: 7, Email: "cashier@shop.id", FirstName: "Ani"}, nil).
+			Times(1)
+
+		passwordResetRepository.
+			EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity passwordreset.Token) (int64, error) {
+				stored = entity
+				return 1, nil
+			}).
+			Times(1)
+
+		mailer.
+			EXPECT().
+			Send(gomock.Any(), "cashier@shop.id", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, body string) error {
+				defer close(done)
+				i := strings.Index(body, resetURL+"?token=")
+				assert.NotEqual(t, -1, i)
+				token := strings.Fields(body[i+len(resetURL+"?token="):])[0]
+				assert.Equal(t, refreshHash(token), stored.TokenHash)
+				return nil
+			}).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ForgotPassword(ctx, "cashier@shop.id", "10.0.0.1")
+		assert.NoError(t, err)
+		waitFor(t, done)
+		assert.Equal(t, 7, stored.UserID)
+		assert.WithinDuration(t, time.Now().Add(passwordreset.TTL), stored.ExpiresAt, time.Minute)
+	})
+}
+
+// allowResetRequest expects a reset request for email from 10.0.0.1 to pass
+// the throttle and be counted.
+func allowResetRequest(ctx context.Context, requestAttempts *smock.MockLoginAttemptStore, email string) {
+	requestAttempts.
+		EXPECT().
+		Take(ctx, "reset:account:"+email, passwordreset.AccountFreeRequests, gomock.Any(), passwordreset.RequestWindow).
+		Return(user.LoginAttempts{Failures: 1}, nil).
+		Times(1)
+
+	requestAttempts.
+		EXPECT().
+		Take(ctx, "reset:ip:10.0.0.1", passwordreset.IPFreeRequests, gomock.Any(), passwordreset.RequestWindow).
+		Return(user.LoginAttempts{Failures: 1}, nil).
+		Times(1)
+}
+
+// waitFor fails the test when done is not closed soon, for work a service
+// leaves running in the background.
+func waitFor(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("background work did not finish")
+	}
+}
+
+func Test_passwordService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("password too short", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		passwordResetRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{ID: 7, Email: "rina@example.com", FirstName: "Rina"}, nil).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ResetPassword(ctx, "token", "short")
+		assert.ErrorIs(t, err, user.ErrInvalidPassword)
+	})
+
+	t.Run("password contains the user's email", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		passwordResetRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{ID: 7, Email: "rina.kasir@example.com", FirstName: "Rina"}, nil).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, password.Policy{RejectPersonalInfo: true}, mailer, requestAttempts, resetURL)
+		err := s.ResetPassword(ctx, "token", "Kasir-2024-baru")
+
+		var policyErr *user.PasswordPolicyError
+		if assert.ErrorAs(t, err, &policyErr) {
+			assert.Equal(t, password.RulePersonalInfo, policyErr.Violations[0].Rule)
+		}
+	})
+
+	t.Run("used or expired token", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		passwordResetRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{}, passwordreset.ErrInvalidResetToken).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ResetPassword(ctx, "token", "new-password")
+		assert.ErrorIs(t, err, passwordreset.ErrInvalidResetToken)
+	})
+
+	t.Run("success - sessions are revoked", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		passwordResetRepository := prmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		hasher := smock.NewMockHasher(ctrl)
+		mailer := smock.NewMockMailer(ctrl)
+		requestAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		passwordResetRepository.
+			EXPECT().
+			FindUser(ctx, refreshHash("token")).
+			Return(user.User{ID: 7, Email: "rina@example.com", FirstName: "Rina"}, nil).
+			Times(1)
+
+		hasher.
+			EXPECT().
+			HashPassword(ctx, "new-password").
+			Return("hashed", nil).
+			Times(1)
+
+		passwordResetRepository.
+			EXPECT().
+			Redeem(ctx, refreshHash("token"), "hashed").
+			Return(7, nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			RevokeUserSessions(ctx, 7).
+			Return(nil).
+			Times(1)
+
+		s := service.NewPasswordService(userRepository, passwordResetRepository, sessionRepository, hasher, passwordPolicy, mailer, requestAttempts, resetURL)
+		err := s.ResetPassword(ctx, "token", "new-password")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type paymentService struct {
+	paymentRepository payment.Repository
+	outletRepository  outlet.Repository
+}
+
+func NewPaymentService(paymentRepository payment.Repository, outletRepository outlet.Repository) *paymentService {
+	return &paymentService{
+		paymentRepository: paymentRepository,
+		outletRepository:  outletRepository,
+	}
+}
+
+// GetPaymentMethods lists every payment method with whether the outlet
+// accepts it.
+func (s *paymentService) GetPaymentMethods(ctx context.Context, merchantID, outletID int) (methods []payment.OutletPaymentMethod, err error) {
+	const ops = "service.paymentService.GetPaymentMethods"
+	var enabled map[payment.Method]bool
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, outletID); err != nil {
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return nil, err
+	}
+
+	enabled, err = enabledPaymentMethods(ctx, s.paymentRepository, outletID)
+	if err != nil {
+		return nil, err
+	}
+
+	methods = make([]payment.OutletPaymentMethod, 0, len(payment.Methods))
+	for _, m := range payment.Methods {
+		methods = append(methods, payment.OutletPaymentMethod{
+			OutletID: outletID,
+			Method:   m,
+			Enabled:  enabled[m],
+		})
+	}
+
+	return methods, nil
+}
+
+func (s *paymentService) SetPaymentMethod(ctx context.Context, merchantID int, entity payment.OutletPaymentMethod) (err error) {
+	const ops = "service.paymentService.SetPaymentMethod"
+
+	if !entity.Method.Valid() {
+		return payment.ErrInvalidPaymentMethod
+	}
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, entity.OutletID); err != nil {
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return err
+	}
+
+	if err = s.paymentRepository.SetOutletMethod(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error setting payment method: %v", err)
+		return err
+	}
+
+	logger.Info(ctx, ops, "payment method %s on outlet %d set to enabled=%t", entity.Method, entity.OutletID, entity.Enabled)
+	return nil
+}
+
+// enabledPaymentMethods resolves which methods an outlet accepts, falling back
+// to the method default for anything the merchant has not configured.
+func enabledPaymentMethods(ctx context.Context, paymentRepository payment.Repository, outletID int) (map[payment.Method]bool, error) {
+	const ops = "service.enabledPaymentMethods"
+
+	configured, err := paymentRepository.GetOutletMethods(ctx, outletID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting outlet payment methods: %v", err)
+		return nil, err
+	}
+
+	enabled := make(map[payment.Method]bool, len(payment.Methods))
+	for _, m := range payment.Methods {
+		enabled[m] = m.EnabledByDefault()
+	}
+	for _, m := range configured {
+		enabled[m.Method] = m.Enabled
+	}
+
+	return enabled, nil
+}
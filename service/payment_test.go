@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	omock "github.com/mhdiiilham/POS/entity/outlet/mock"
+	"github.com/mhdiiilham/POS/entity/payment"
+	pymock "github.com/mhdiiilham/POS/entity/payment/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_paymentService_GetPaymentMethods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - outlet of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{}, outlet.ErrOutletNotFound).
+			Times(1)
+
+		s := service.NewPaymentService(paymentRepository, outletRepository)
+		_, err := s.GetPaymentMethods(ctx, 1, 2)
+		assert.ErrorIs(t, err, outlet.ErrOutletNotFound)
+	})
+
+	t.Run("success - configured methods override defaults", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return([]payment.OutletPaymentMethod{
+				{OutletID: 2, Method: payment.MethodCash, Enabled: false},
+				{OutletID: 2, Method: payment.MethodCard, Enabled: true},
+			}, nil).
+			Times(1)
+
+		s := service.NewPaymentService(paymentRepository, outletRepository)
+		methods, err := s.GetPaymentMethods(ctx, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []payment.OutletPaymentMethod{
+			{OutletID: 2, Method: payment.MethodCash, Enabled: false},
+			{OutletID: 2, Method: payment.MethodCard, Enabled: true},
+			{OutletID: 2, Method: payment.MethodEWallet, Enabled: false},
+			{OutletID: 2, Method: payment.MethodStoreCredit, Enabled: false},
+		}, methods)
+	})
+}
+
+func Test_paymentService_SetPaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - unknown method", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		s := service.NewPaymentService(paymentRepository, outletRepository)
+		err := s.SetPaymentMethod(ctx, 1, payment.OutletPaymentMethod{OutletID: 2, Method: "cheque", Enabled: true})
+		assert.ErrorIs(t, err, payment.ErrInvalidPaymentMethod)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		entity := payment.OutletPaymentMethod{OutletID: 2, Method: payment.MethodEWallet, Enabled: true}
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			SetOutletMethod(ctx, entity).
+			Return(nil).
+			Times(1)
+
+		s := service.NewPaymentService(paymentRepository, outletRepository)
+		err := s.SetPaymentMethod(ctx, 1, entity)
+		assert.NoError(t, err)
+	})
+}
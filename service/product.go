@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type productService struct {
+	productRepository product.Repository
+}
+
+func NewProductService(productRepository product.Repository) *productService {
+	return &productService{
+		productRepository: productRepository,
+	}
+}
+
+func (s *productService) CreateProduct(ctx context.Context, entity product.Product) (productID int, err error) {
+	const ops = "service.productService.CreateProduct"
+	var insertedID int64
+
+	if entity.SKU == "" || entity.Name == "" || entity.MerchantID == 0 {
+		return 0, product.ErrInvalidProductParameters
+	}
+
+	if err = s.ensureUniqueSKU(ctx, entity); err != nil {
+		return 0, err
+	}
+
+	insertedID, err = s.productRepository.Create(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to insert entity to db: %v", err)
+		return 0, err
+	}
+
+	return int(insertedID), nil
+}
+
+func (s *productService) GetProducts(ctx context.Context, merchantID, lastID, limit int) (products []product.Product, totalData int, err error) {
+	const ops = "service.productService.GetProducts"
+	paginationOpts := product.RepositoryGetProductPaginationOptions{
+		Limit:  limit,
+		Cursor: lastID,
+	}
+
+	products, totalData, err = s.productRepository.Get(ctx, merchantID, &paginationOpts)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *productService) GetProduct(ctx context.Context, merchantID, productID int) (entity product.Product, err error) {
+	const ops = "service.productService.GetProduct"
+
+	entity, err = s.productRepository.GetProduct(ctx, merchantID, productID)
+	if err != nil {
+		logger.Error(ctx, ops, "unknown error: %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *productService) UpdateProduct(ctx context.Context, entity product.Product) error {
+	const ops = "service.productService.UpdateProduct"
+
+	if entity.SKU == "" || entity.Name == "" {
+		return product.ErrInvalidProductParameters
+	}
+
+	if err := s.ensureUniqueSKU(ctx, entity); err != nil {
+		return err
+	}
+
+	if err := s.productRepository.Update(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error updating product %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *productService) DeleteProduct(ctx context.Context, merchantID, productID int) error {
+	const ops = "service.productService.DeleteProduct"
+
+	if err := s.productRepository.Remove(ctx, merchantID, productID); err != nil {
+		logger.Error(ctx, ops, "error removing product %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ensureUniqueSKU rejects a SKU already used by another product of the same
+// merchant. The unique index on "Product" still guards concurrent writers.
+func (s *productService) ensureUniqueSKU(ctx context.Context, entity product.Product) error {
+	const ops = "service.productService.ensureUniqueSKU"
+
+	p, err := s.productRepository.FindProductBySKU(ctx, entity.MerchantID, entity.SKU)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Error(ctx, ops, "unexpected error happened %v", err)
+		return err
+	}
+
+	if p != nil && p.ID != entity.ID {
+		return product.ErrSKUNotUnique
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/product"
+	"github.com/mhdiiilham/POS/entity/product/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_productService_CreateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - sku is empty", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		productRepository := mock.NewMockRepository(ctrl)
+
+		s := service.NewProductService(productRepository)
+		resp, err := s.CreateProduct(ctx, product.Product{MerchantID: 1, Name: faker.Name()})
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, product.ErrInvalidProductParameters)
+	})
+
+	t.Run("failed - sku not unique", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := product.Product{MerchantID: 1, SKU: "SKU-1", Name: faker.Name()}
+		productRepository := mock.NewMockRepository(ctrl)
+
+		productRepository.
+			EXPECT().
+			FindProductBySKU(ctx, 1, "SKU-1").
+			Return(&product.Product{ID: 2, MerchantID: 1, SKU: "SKU-1"}, nil).
+			Times(1)
+
+		s := service.NewProductService(productRepository)
+		resp, err := s.CreateProduct(ctx, payload)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, product.ErrSKUNotUnique)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := product.Product{MerchantID: 1, SKU: "SKU-1", Name: faker.Name()}
+		productRepository := mock.NewMockRepository(ctrl)
+
+		productRepository.
+			EXPECT().
+			FindProductBySKU(ctx, 1, "SKU-1").
+			Return(nil, sql.ErrNoRows).
+			Times(1)
+
+		productRepository.
+			EXPECT().
+			Create(ctx, payload).
+			Return(int64(11), nil).
+			Times(1)
+
+		s := service.NewProductService(productRepository)
+		resp, err := s.CreateProduct(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, resp)
+	})
+}
+
+func Test_productService_UpdateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - sku used by another product", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := product.Product{ID: 1, MerchantID: 1, SKU: "SKU-2", Name: faker.Name()}
+		productRepository := mock.NewMockRepository(ctrl)
+
+		productRepository.
+			EXPECT().
+			FindProductBySKU(ctx, 1, "SKU-2").
+			Return(&product.Product{ID: 2, MerchantID: 1, SKU: "SKU-2"}, nil).
+			Times(1)
+
+		s := service.NewProductService(productRepository)
+		err := s.UpdateProduct(ctx, payload)
+		assert.ErrorIs(t, err, product.ErrSKUNotUnique)
+	})
+
+	t.Run("success - keeping its own sku", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		payload := product.Product{ID: 1, MerchantID: 1, SKU: "SKU-1", Name: faker.Name()}
+		productRepository := mock.NewMockRepository(ctrl)
+
+		productRepository.
+			EXPECT().
+			FindProductBySKU(ctx, 1, "SKU-1").
+			Return(&product.Product{ID: 1, MerchantID: 1, SKU: "SKU-1"}, nil).
+			Times(1)
+
+		productRepository.
+			EXPECT().
+			Update(ctx, payload).
+			Return(nil).
+			Times(1)
+
+		s := service.NewProductService(productRepository)
+		err := s.UpdateProduct(ctx, payload)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_productService_GetProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - product of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		productRepository := mock.NewMockRepository(ctrl)
+
+		productRepository.
+			EXPECT().
+			GetProduct(ctx, 2, 5).
+			Return(product.Product{}, product.ErrProductNotFound).
+			Times(1)
+
+		s := service.NewProductService(productRepository)
+		resp, err := s.GetProduct(ctx, 2, 5)
+		assert.Empty(t, resp)
+		assert.ErrorIs(t, err, product.ErrProductNotFound)
+	})
+}
+
+func Test_productService_DeleteProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		productRepository := mock.NewMockRepository(ctrl)
+
+		productRepository.
+			EXPECT().
+			Remove(ctx, 1, 5).
+			Return(nil).
+			Times(1)
+
+		s := service.NewProductService(productRepository)
+		err := s.DeleteProduct(ctx, 1, 5)
+		assert.NoError(t, err)
+	})
+}
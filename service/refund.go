@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mhdiiilham/POS/entity/refund"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type refundService struct {
+	transactor       Transactor
+	refundRepository refund.Repository
+	saleRepository   sale.Repository
+	shiftRepository  shift.Repository
+	paymentGateway   PaymentGateway
+}
+
+func NewRefundService(transactor Transactor, refundRepository refund.Repository, saleRepository sale.Repository, shiftRepository shift.Repository, paymentGateway PaymentGateway) *refundService {
+	return &refundService{
+		transactor:       transactor,
+		refundRepository: refundRepository,
+		saleRepository:   saleRepository,
+		shiftRepository:  shiftRepository,
+		paymentGateway:   paymentGateway,
+	}
+}
+
+// VoidSale cancels a whole sale. It is only allowed while the shift that took
+// the sale is still open, and the void is booked against that same shift.
+func (s *refundService) VoidSale(ctx context.Context, entity refund.Refund) (voided refund.Refund, err error) {
+	const ops = "service.refundService.VoidSale"
+	var saleEntity sale.Sale
+	var saleShift shift.Shift
+
+	if !entity.Reason.Valid() {
+		return refund.Refund{}, refund.ErrInvalidReason
+	}
+
+	saleEntity, err = s.getOutletSale(ctx, entity)
+	if err != nil {
+		return refund.Refund{}, err
+	}
+
+	saleShift, err = s.shiftRepository.GetShift(ctx, entity.MerchantID, saleEntity.ShiftID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting shift of sale: %v", err)
+		return refund.Refund{}, err
+	}
+
+	if !saleShift.IsOpen() {
+		return refund.Refund{}, refund.ErrVoidWindowExpired
+	}
+
+	entity.ShiftID = &saleShift.ID
+	entity.Type = refund.TypeVoid
+	entity.Items = nil
+	voided, err = s.createRefund(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error voiding sale %d: %v", entity.SaleID, err)
+		return refund.Refund{}, err
+	}
+
+	logger.Info(ctx, ops, "sale %d voided by user %d", entity.SaleID, entity.UserID)
+	return voided, nil
+}
+
+func (s *refundService) RefundSale(ctx context.Context, entity refund.Refund) (refunded refund.Refund, err error) {
+	const ops = "service.refundService.RefundSale"
+
+	if !entity.Reason.Valid() {
+		return refund.Refund{}, refund.ErrInvalidReason
+	}
+
+	if len(entity.Items) == 0 {
+		return refund.Refund{}, refund.ErrEmptyRefund
+	}
+
+	merged := make([]refund.Item, 0, len(entity.Items))
+	positions := make(map[int]int, len(entity.Items))
+	for _, item := range entity.Items {
+		if item.ProductID == 0 || item.Quantity <= 0 {
+			return refund.Refund{}, refund.ErrInvalidRefundItem
+		}
+
+		if i, ok := positions[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+
+		positions[item.ProductID] = len(merged)
+		merged = append(merged, refund.Item{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	if _, err = s.getOutletSale(ctx, entity); err != nil {
+		return refund.Refund{}, err
+	}
+
+	// Refunds paid out of a drawer count against the refunding user's open
+	// shift. Without one only refunds that pay nothing back in cash go
+	// through, which createRefund checks once the refund is allocated.
+	openShift, err := s.shiftRepository.FindOpenShift(ctx, entity.MerchantID, entity.OutletID, entity.UserID)
+	switch {
+	case err == nil:
+		entity.ShiftID = &openShift.ID
+	case !errors.Is(err, shift.ErrNoOpenShift):
+		logger.Error(ctx, ops, "error finding open shift: %v", err)
+		return refund.Refund{}, err
+	}
+
+	entity.Type = refund.TypeRefund
+	entity.Items = merged
+	refunded, err = s.createRefund(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error refunding sale %d: %v", entity.SaleID, err)
+		return refund.Refund{}, err
+	}
+
+	logger.Info(ctx, ops, "sale %d refunded by user %d", entity.SaleID, entity.UserID)
+	return refunded, nil
+}
+
+// createRefund records the refund in one transaction and commits it before
+// any money goes back through the gateway, so a refund the customer was paid
+// for is never rolled back. Cash must come out of an open shift's drawer, so
+// a refund with a cash part and no shift is rolled back. The card and e-wallet
+// parts are then refunded by settlePayments; if the gateway fails, the refund
+// stays recorded with those parts pending until RetryRefund settles them.
+func (s *refundService) createRefund(ctx context.Context, entity refund.Refund) (created refund.Refund, err error) {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err = s.refundRepository.Create(ctx, entity)
+		if err != nil {
+			return err
+		}
+
+		if created.ShiftID == nil && created.Cash() > 0 {
+			return shift.ErrNoOpenShift
+		}
+
+		for _, p := range created.Payments {
+			if p.Method.RequiresGateway() && p.Reference == nil {
+				return fmt.Errorf("%w: %s payment has no gateway transaction", refund.ErrGatewayRefundFailed, p.Method)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return refund.Refund{}, err
+	}
+
+	return s.settlePayments(ctx, created)
+}
+
+// settlePayments refunds the pending payments of a recorded refund through
+// the gateway, marking each refunded as soon as the gateway has paid it back.
+// Each call carries the payment's idempotency key, so settling again after a
+// failure skips what is marked and the gateway does not pay out twice for a
+// refund it made but that could not be marked.
+func (s *refundService) settlePayments(ctx context.Context, entity refund.Refund) (refund.Refund, error) {
+	const ops = "service.refundService.settlePayments"
+
+	payments := make([]refund.Payment, len(entity.Payments))
+	copy(payments, entity.Payments)
+	entity.Payments = payments
+
+	for i, p := range payments {
+		if p.Status != refund.PaymentStatusPending {
+			continue
+		}
+
+		if _, err := s.paymentGateway.Refund(ctx, *p.Reference, p.Amount, p.IdempotencyKey()); err != nil {
+			logger.Error(ctx, ops, "error refunding %.2f to transaction %s: %v", p.Amount, *p.Reference, err)
+			return refund.Refund{}, fmt.Errorf("%w: refund %d is pending: %v", refund.ErrGatewayRefundFailed, entity.ID, err)
+		}
+
+		if err := s.refundRepository.MarkPaymentRefunded(ctx, p.ID); err != nil {
+			logger.Error(ctx, ops, "error marking refund payment %d refunded: %v", p.ID, err)
+			return refund.Refund{}, err
+		}
+		payments[i].Status = refund.PaymentStatusRefunded
+	}
+
+	return entity, nil
+}
+
+// RetryRefund refunds what the gateway has not yet paid back of a recorded
+// refund. A refund with nothing pending is returned as it is.
+func (s *refundService) RetryRefund(ctx context.Context, merchantID, outletID, saleID, refundID int) (retried refund.Refund, err error) {
+	const ops = "service.refundService.RetryRefund"
+
+	refunds, err := s.GetRefunds(ctx, merchantID, outletID, saleID)
+	if err != nil {
+		return refund.Refund{}, err
+	}
+
+	for _, rf := range refunds {
+		if rf.ID != refundID {
+			continue
+		}
+
+		retried, err = s.settlePayments(ctx, rf)
+		if err != nil {
+			logger.Error(ctx, ops, "error retrying refund %d: %v", refundID, err)
+			return refund.Refund{}, err
+		}
+
+		return retried, nil
+	}
+
+	return refund.Refund{}, refund.ErrRefundNotFound
+}
+
+func (s *refundService) GetRefunds(ctx context.Context, merchantID, outletID, saleID int) (refunds []refund.Refund, err error) {
+	const ops = "service.refundService.GetRefunds"
+
+	_, err = s.getOutletSale(ctx, refund.Refund{MerchantID: merchantID, OutletID: outletID, SaleID: saleID})
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err = s.refundRepository.GetBySale(ctx, merchantID, saleID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// getOutletSale loads the sale a refund points to, treating a sale of another
+// outlet as missing.
+func (s *refundService) getOutletSale(ctx context.Context, entity refund.Refund) (sale.Sale, error) {
+	const ops = "service.refundService.getOutletSale"
+
+	saleEntity, err := s.saleRepository.GetSale(ctx, entity.MerchantID, entity.SaleID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting sale: %v", err)
+		return sale.Sale{}, err
+	}
+
+	if saleEntity.OutletID != entity.OutletID {
+		return sale.Sale{}, sale.ErrSaleNotFound
+	}
+
+	return saleEntity, nil
+}
@@ -0,0 +1,598 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/refund"
+	rmock "github.com/mhdiiilham/POS/entity/refund/mock"
+	"github.com/mhdiiilham/POS/entity/sale"
+	samock "github.com/mhdiiilham/POS/entity/sale/mock"
+	"github.com/mhdiiilham/POS/entity/shift"
+	shmock "github.com/mhdiiilham/POS/entity/shift/mock"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_refundService_VoidSale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - invalid reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.VoidSale(ctx, refund.Refund{SaleID: 1, MerchantID: 1, OutletID: 1, Reason: "because"})
+		assert.ErrorIs(t, err, refund.ErrInvalidReason)
+	})
+
+	t.Run("failed - sale of other outlet", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 3, CreatedAt: time.Now()}, nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.VoidSale(ctx, refund.Refund{SaleID: 4, MerchantID: 1, OutletID: 2, Reason: refund.ReasonCashierError})
+		assert.ErrorIs(t, err, sale.ErrSaleNotFound)
+	})
+
+	t.Run("failed - void window expired", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2, ShiftID: 6, CreatedAt: time.Now().AddDate(0, 0, -2)}, nil).
+			Times(1)
+
+		closedAt := time.Now().Add(-time.Hour)
+		shiftRepository.
+			EXPECT().
+			GetShift(ctx, 1, 6).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, ClosedAt: &closedAt}, nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.VoidSale(ctx, refund.Refund{SaleID: 4, MerchantID: 1, OutletID: 2, Reason: refund.ReasonCashierError})
+		assert.ErrorIs(t, err, refund.ErrVoidWindowExpired)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		payload := refund.Refund{SaleID: 4, MerchantID: 1, OutletID: 2, UserID: 5, Reason: refund.ReasonCashierError}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2, ShiftID: 6, CreatedAt: time.Now()}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			GetShift(ctx, 1, 6).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftID := 6
+		expected := payload
+		expected.ShiftID = &shiftID
+		expected.Type = refund.TypeVoid
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			Create(ctx, expected).
+			Return(refund.Refund{ID: 1, SaleID: 4, Type: refund.TypeVoid, Total: 30000}, nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		resp, err := s.VoidSale(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, refund.TypeVoid, resp.Type)
+	})
+}
+
+func Test_refundService_RefundSale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - no items", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.RefundSale(ctx, refund.Refund{SaleID: 1, MerchantID: 1, OutletID: 1, Reason: refund.ReasonDamaged})
+		assert.ErrorIs(t, err, refund.ErrEmptyRefund)
+	})
+
+	t.Run("failed - refund exceeds sold quantity", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		payload := refund.Refund{
+			SaleID:     4,
+			MerchantID: 1,
+			OutletID:   2,
+			Reason:     refund.ReasonCustomerReturn,
+			Items:      []refund.Item{{ProductID: 7, Quantity: 2}, {ProductID: 7, Quantity: 2}},
+		}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 0).
+			Return(shift.Shift{}, shift.ErrNoOpenShift).
+			Times(1)
+
+		expected := payload
+		expected.Type = refund.TypeRefund
+		expected.Items = []refund.Item{{ProductID: 7, Quantity: 4}}
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			Create(ctx, expected).
+			Return(refund.Refund{}, refund.ErrRefundExceedsSold).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.RefundSale(ctx, payload)
+		assert.ErrorIs(t, err, refund.ErrRefundExceedsSold)
+	})
+
+	t.Run("failed - gateway refuses the refund", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		reference := "tx-1"
+		payload := refund.Refund{
+			SaleID:     4,
+			MerchantID: 1,
+			OutletID:   2,
+			UserID:     5,
+			Reason:     refund.ReasonCustomerReturn,
+			Items:      []refund.Item{{ProductID: 7, Quantity: 1}},
+		}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 5).
+			Return(shift.Shift{ID: 6}, nil).
+			Times(1)
+
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(refund.Refund{ID: 1, SaleID: 4, Total: 20, Payments: []refund.Payment{
+				{ID: 3, Method: payment.MethodCard, Amount: 20, Reference: &reference, Status: refund.PaymentStatusPending},
+			}}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, reference, float64(20), "refund-payment-3").
+			Return(payment.GatewayTransaction{}, payment.ErrInvalidTransition).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.RefundSale(ctx, payload)
+		assert.ErrorIs(t, err, refund.ErrGatewayRefundFailed)
+	})
+
+	t.Run("failed - second card payment refused, the first stays refunded", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		first, second := "tx-1", "tx-2"
+		payload := refund.Refund{
+			SaleID:     4,
+			MerchantID: 1,
+			OutletID:   2,
+			UserID:     5,
+			Reason:     refund.ReasonCustomerReturn,
+			Items:      []refund.Item{{ProductID: 7, Quantity: 2}},
+		}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 5).
+			Return(shift.Shift{ID: 6}, nil).
+			Times(1)
+
+		// The refund is committed before the gateway is called, so a
+		// gateway failure can't roll it back.
+		committed := false
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				err := fn(ctx)
+				committed = err == nil
+				return err
+			}).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(refund.Refund{ID: 1, SaleID: 4, Total: 40, Payments: []refund.Payment{
+				{ID: 3, Method: payment.MethodCard, Amount: 25, Reference: &first, Status: refund.PaymentStatusPending},
+				{ID: 4, Method: payment.MethodCard, Amount: 15, Reference: &second, Status: refund.PaymentStatusPending},
+			}}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, first, float64(25), "refund-payment-3").
+			DoAndReturn(func(context.Context, string, float64, string) (payment.GatewayTransaction, error) {
+				assert.True(t, committed)
+				return payment.GatewayTransaction{ID: first, Status: payment.GatewayStatusRefunded}, nil
+			}).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			MarkPaymentRefunded(ctx, 3).
+			Return(nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, second, float64(15), "refund-payment-4").
+			Return(payment.GatewayTransaction{}, payment.ErrInvalidTransition).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.RefundSale(ctx, payload)
+		assert.ErrorIs(t, err, refund.ErrGatewayRefundFailed)
+	})
+
+	t.Run("success - card part refunded through the gateway", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		reference := "tx-1"
+		payload := refund.Refund{
+			SaleID:     4,
+			MerchantID: 1,
+			OutletID:   2,
+			UserID:     5,
+			Reason:     refund.ReasonCustomerReturn,
+			Items:      []refund.Item{{ProductID: 7, Quantity: 2}},
+		}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 5).
+			Return(shift.Shift{ID: 6}, nil).
+			Times(1)
+
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		shiftID := 6
+		expected := payload
+		expected.ShiftID = &shiftID
+		expected.Type = refund.TypeRefund
+		refundRepository.
+			EXPECT().
+			Create(ctx, expected).
+			Return(refund.Refund{ID: 1, SaleID: 4, ShiftID: &shiftID, Total: 40, Payments: []refund.Payment{
+				{ID: 3, Method: payment.MethodCard, Amount: 30, Reference: &reference, Status: refund.PaymentStatusPending},
+				{ID: 4, Method: payment.MethodCash, Amount: 10, Status: refund.PaymentStatusRefunded},
+			}}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, reference, float64(30), "refund-payment-3").
+			Return(payment.GatewayTransaction{ID: reference, Status: payment.GatewayStatusPartiallyRefunded}, nil).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			MarkPaymentRefunded(ctx, 3).
+			Return(nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		resp, err := s.RefundSale(ctx, payload)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(10), resp.Cash())
+		assert.Equal(t, refund.PaymentStatusRefunded, resp.Payments[0].Status)
+	})
+
+	t.Run("failed - cash refund without an open shift", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		payload := refund.Refund{
+			SaleID:     4,
+			MerchantID: 1,
+			OutletID:   2,
+			UserID:     5,
+			Reason:     refund.ReasonCustomerReturn,
+			Items:      []refund.Item{{ProductID: 7, Quantity: 1}},
+		}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 5).
+			Return(shift.Shift{}, shift.ErrNoOpenShift).
+			Times(1)
+
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(refund.Refund{ID: 1, SaleID: 4, Total: 20, Payments: []refund.Payment{
+				{ID: 3, Method: payment.MethodCash, Amount: 20, Status: refund.PaymentStatusRefunded},
+			}}, nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.RefundSale(ctx, payload)
+		assert.ErrorIs(t, err, shift.ErrNoOpenShift)
+	})
+
+	t.Run("success - card refund without an open shift", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		reference := "tx-1"
+		payload := refund.Refund{
+			SaleID:     4,
+			MerchantID: 1,
+			OutletID:   2,
+			UserID:     5,
+			Reason:     refund.ReasonCustomerReturn,
+			Items:      []refund.Item{{ProductID: 7, Quantity: 1}},
+		}
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 5).
+			Return(shift.Shift{}, shift.ErrNoOpenShift).
+			Times(1)
+
+		transactor.
+			EXPECT().
+			WithinTransaction(ctx, gomock.Any()).
+			DoAndReturn(inTransaction).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(refund.Refund{ID: 1, SaleID: 4, Total: 20, Payments: []refund.Payment{
+				{ID: 3, Method: payment.MethodCard, Amount: 20, Reference: &reference, Status: refund.PaymentStatusPending},
+			}}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, reference, float64(20), "refund-payment-3").
+			Return(payment.GatewayTransaction{ID: reference, Status: payment.GatewayStatusRefunded}, nil).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			MarkPaymentRefunded(ctx, 3).
+			Return(nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		resp, err := s.RefundSale(ctx, payload)
+		assert.NoError(t, err)
+		assert.Nil(t, resp.ShiftID)
+	})
+}
+
+func Test_refundService_RetryRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	first, second := "tx-1", "tx-2"
+	recorded := []refund.Refund{{ID: 1, SaleID: 4, Total: 40, Payments: []refund.Payment{
+		{ID: 3, RefundID: 1, Method: payment.MethodCard, Amount: 25, Reference: &first, Status: refund.PaymentStatusRefunded},
+		{ID: 4, RefundID: 1, Method: payment.MethodCard, Amount: 15, Reference: &second, Status: refund.PaymentStatusPending},
+	}}}
+
+	t.Run("failed - refund not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			GetBySale(ctx, 1, 4).
+			Return(recorded, nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		_, err := s.RetryRefund(ctx, 1, 2, 4, 9)
+		assert.ErrorIs(t, err, refund.ErrRefundNotFound)
+	})
+
+	t.Run("success - resumes at the pending payment", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		refundRepository := rmock.NewMockRepository(ctrl)
+		saleRepository := samock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		transactor := smock.NewMockTransactor(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 4).
+			Return(sale.Sale{ID: 4, MerchantID: 1, OutletID: 2}, nil).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			GetBySale(ctx, 1, 4).
+			Return(recorded, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, second, float64(15), "refund-payment-4").
+			Return(payment.GatewayTransaction{ID: second, Status: payment.GatewayStatusRefunded}, nil).
+			Times(1)
+
+		refundRepository.
+			EXPECT().
+			MarkPaymentRefunded(ctx, 4).
+			Return(nil).
+			Times(1)
+
+		s := service.NewRefundService(transactor, refundRepository, saleRepository, shiftRepository, paymentGateway)
+		resp, err := s.RetryRefund(ctx, 1, 2, 4, 1)
+		assert.NoError(t, err)
+		for _, p := range resp.Payments {
+			assert.Equal(t, refund.PaymentStatusRefunded, p.Status)
+		}
+	})
+}
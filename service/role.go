@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type roleService struct {
+	roleRepository role.Repository
+}
+
+func NewRoleService(roleRepository role.Repository) *roleService {
+	return &roleService{
+		roleRepository: roleRepository,
+	}
+}
+
+func (s *roleService) GetRoles(ctx context.Context, merchantID int) (roles []role.Role, err error) {
+	const ops = "service.roleService.GetRoles"
+
+	roles, err = s.roleRepository.Get(ctx, merchantID)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *roleService) GetRole(ctx context.Context, merchantID, roleID int) (entity role.Role, err error) {
+	const ops = "service.roleService.GetRole"
+
+	entity, err = s.roleRepository.GetRole(ctx, merchantID, roleID)
+	if err != nil {
+		logger.Error(ctx, ops, "unknown error: %v", err)
+		return
+	}
+
+	return
+}
+
+// CreateRole stores a custom role for the merchant. The grantor must hold
+// every permission the role carries.
+func (s *roleService) CreateRole(ctx context.Context, grantor []role.Permission, entity role.Role) (roleID int, err error) {
+	const ops = "service.roleService.CreateRole"
+	var insertedID int64
+
+	if entity.MerchantID == nil {
+		return 0, role.ErrInvalidRoleParameters
+	}
+
+	if err = entity.Validate(); err != nil {
+		return 0, err
+	}
+
+	if !role.Covers(grantor, entity.Permissions) {
+		return 0, role.ErrInsufficientPermissions
+	}
+
+	insertedID, err = s.roleRepository.Create(ctx, entity)
+	if err != nil {
+		logger.Error(ctx, ops, "error when trying to insert entity to db: %v", err)
+		return 0, err
+	}
+
+	return int(insertedID), nil
+}
+
+// UpdateRole changes a custom role. The grantor must hold both the
+// permissions the role had and the ones it is given.
+func (s *roleService) UpdateRole(ctx context.Context, grantor []role.Permission, entity role.Role) (err error) {
+	const ops = "service.roleService.UpdateRole"
+	var current role.Role
+
+	if entity.MerchantID == nil {
+		return role.ErrInvalidRoleParameters
+	}
+
+	if err = entity.Validate(); err != nil {
+		return err
+	}
+
+	current, err = s.roleRepository.GetRole(ctx, *entity.MerchantID, entity.ID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting role: %v", err)
+		return err
+	}
+
+	if current.Builtin() {
+		return role.ErrBuiltinRole
+	}
+
+	if !role.Covers(grantor, current.Permissions) || !role.Covers(grantor, entity.Permissions) {
+		return role.ErrInsufficientPermissions
+	}
+
+	if err = s.roleRepository.Update(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error updating role %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, grantor []role.Permission, merchantID, roleID int) (err error) {
+	const ops = "service.roleService.DeleteRole"
+	var current role.Role
+
+	current, err = s.roleRepository.GetRole(ctx, merchantID, roleID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting role: %v", err)
+		return err
+	}
+
+	if current.Builtin() {
+		return role.ErrBuiltinRole
+	}
+
+	if !role.Covers(grantor, current.Permissions) {
+		return role.ErrInsufficientPermissions
+	}
+
+	if err = s.roleRepository.Remove(ctx, merchantID, roleID); err != nil {
+		logger.Error(ctx, ops, "error removing role %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/role"
+	romock "github.com/mhdiiilham/POS/entity/role/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_roleService_CreateRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	merchantID := 1
+	manager := []role.Permission{role.PermissionUserRead, role.PermissionSaleCreate, role.PermissionSaleRead, role.PermissionSaleVoid}
+
+	t.Run("invalid permission", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		s := service.NewRoleService(roleRepository)
+		_, err := s.CreateRole(ctx, role.Permissions, role.Role{MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{"sale:everything"}})
+		assert.ErrorIs(t, err, role.ErrInvalidRoleParameters)
+	})
+
+	t.Run("built-in name", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		s := service.NewRoleService(roleRepository)
+		_, err := s.CreateRole(ctx, role.Permissions, role.Role{MerchantID: &merchantID, Name: "Owner", Permissions: []role.Permission{role.PermissionSaleRead}})
+		assert.ErrorIs(t, err, role.ErrRoleNameNotUnique)
+	})
+
+	t.Run("grantor lacks a permission", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		s := service.NewRoleService(roleRepository)
+		_, err := s.CreateRole(ctx, manager, role.Role{MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRefund}})
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+		entity := role.Role{MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRead, role.PermissionSaleVoid}}
+
+		roleRepository.
+			EXPECT().
+			Create(ctx, entity).
+			Return(int64(4), nil).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		roleID, err := s.CreateRole(ctx, manager, entity)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, roleID)
+	})
+}
+
+func Test_roleService_UpdateRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	merchantID := 1
+
+	t.Run("built-in role", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantID, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier", Permissions: []role.Permission{role.PermissionSaleCreate}}, nil).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		err := s.UpdateRole(ctx, role.Permissions, role.Role{ID: role.CashierRoleID, MerchantID: &merchantID, Name: "till", Permissions: []role.Permission{role.PermissionSaleRead}})
+		assert.ErrorIs(t, err, role.ErrBuiltinRole)
+	})
+
+	t.Run("grantor lacks a current permission", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantID, 4).
+			Return(role.Role{ID: 4, MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRefund}}, nil).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		err := s.UpdateRole(ctx, []role.Permission{role.PermissionSaleRead}, role.Role{ID: 4, MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRead}})
+		assert.ErrorIs(t, err, role.ErrInsufficientPermissions)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+		entity := role.Role{ID: 4, MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRead, role.PermissionSaleRefund}}
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantID, 4).
+			Return(role.Role{ID: 4, MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRead}}, nil).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			Update(ctx, entity).
+			Return(nil).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		err := s.UpdateRole(ctx, role.Permissions, entity)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_roleService_DeleteRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	merchantID := 1
+
+	t.Run("built-in role", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantID, role.ManagerRoleID).
+			Return(role.Role{ID: role.ManagerRoleID, Name: "manager"}, nil).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		err := s.DeleteRole(ctx, role.Permissions, merchantID, role.ManagerRoleID)
+		assert.ErrorIs(t, err, role.ErrBuiltinRole)
+	})
+
+	t.Run("still in use", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantID, 4).
+			Return(role.Role{ID: 4, MerchantID: &merchantID, Name: "supervisor", Permissions: []role.Permission{role.PermissionSaleRead}}, nil).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			Remove(ctx, merchantID, 4).
+			Return(role.ErrRoleInUse).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		err := s.DeleteRole(ctx, role.Permissions, merchantID, 4)
+		assert.ErrorIs(t, err, role.ErrRoleInUse)
+	})
+
+	t.Run("failed - db error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		roleRepository := romock.NewMockRepository(ctrl)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, merchantID, 4).
+			Return(role.Role{}, sql.ErrConnDone).
+			Times(1)
+
+		s := service.NewRoleService(roleRepository)
+		err := s.DeleteRole(ctx, role.Permissions, merchantID, 4)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+	})
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/payment"
+	"github.com/mhdiiilham/POS/entity/sale"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type saleService struct {
+	saleRepository    sale.Repository
+	outletRepository  outlet.Repository
+	shiftRepository   shift.Repository
+	paymentRepository payment.Repository
+	paymentGateway    PaymentGateway
+}
+
+func NewSaleService(saleRepository sale.Repository, outletRepository outlet.Repository, shiftRepository shift.Repository, paymentRepository payment.Repository, paymentGateway PaymentGateway) *saleService {
+	return &saleService{
+		saleRepository:    saleRepository,
+		outletRepository:  outletRepository,
+		shiftRepository:   shiftRepository,
+		paymentRepository: paymentRepository,
+		paymentGateway:    paymentGateway,
+	}
+}
+
+// Checkout records a sale settled by one or more payments. The total is only
+// known once prices are locked, so the repository refuses the sale when the
+// payments fall short of it. Card and e-wallet payments are authorized and
+// captured before the sale is recorded, so a sale is only paid once the
+// gateway holds the money, and are given back if recording it fails.
+func (s *saleService) Checkout(ctx context.Context, merchantID, cashierID, outletID int, items []sale.Item, payments []payment.Payment) (entity sale.Sale, err error) {
+	const ops = "service.saleService.Checkout"
+	var openShift shift.Shift
+	var enabled map[payment.Method]bool
+
+	if len(items) == 0 {
+		return sale.Sale{}, sale.ErrEmptySale
+	}
+
+	if len(payments) == 0 {
+		return sale.Sale{}, payment.ErrInsufficientPayment
+	}
+
+	for _, p := range payments {
+		if !p.Method.Valid() {
+			return sale.Sale{}, payment.ErrInvalidPaymentMethod
+		}
+
+		if p.Amount <= 0 {
+			return sale.Sale{}, payment.ErrInvalidPayment
+		}
+	}
+
+	merged := make([]sale.Item, 0, len(items))
+	positions := make(map[int]int, len(items))
+	for _, item := range items {
+		if item.ProductID == 0 || item.Quantity <= 0 {
+			return sale.Sale{}, sale.ErrInvalidSaleItem
+		}
+
+		if i, ok := positions[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+
+		positions[item.ProductID] = len(merged)
+		merged = append(merged, sale.Item{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, outletID); err != nil {
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return sale.Sale{}, err
+	}
+
+	enabled, err = enabledPaymentMethods(ctx, s.paymentRepository, outletID)
+	if err != nil {
+		return sale.Sale{}, err
+	}
+
+	for _, p := range payments {
+		if !enabled[p.Method] {
+			return sale.Sale{}, payment.ErrPaymentMethodDisabled
+		}
+	}
+
+	openShift, err = s.shiftRepository.FindOpenShift(ctx, merchantID, outletID, cashierID)
+	if err != nil {
+		logger.Error(ctx, ops, "error finding open shift: %v", err)
+		return sale.Sale{}, err
+	}
+
+	payments, err = s.chargePayments(ctx, payments)
+	if err != nil {
+		return sale.Sale{}, err
+	}
+
+	entity, err = s.saleRepository.Create(ctx, sale.Sale{
+		MerchantID: merchantID,
+		OutletID:   outletID,
+		ShiftID:    openShift.ID,
+		CashierID:  cashierID,
+		Status:     sale.StatusCompleted,
+		Items:      merged,
+		Payments:   payments,
+	})
+	if err != nil {
+		logger.Error(ctx, ops, "error creating sale: %v", err)
+		s.releasePayments(ctx, payments)
+		return sale.Sale{}, err
+	}
+
+	logger.Info(ctx, ops, "sale %d recorded on outlet %d", entity.ID, outletID)
+	return entity, nil
+}
+
+// chargePayments authorizes and captures every gateway payment and returns a
+// copy of payments carrying the transaction ids as references. A decline or a
+// failed capture gives back what was already charged.
+func (s *saleService) chargePayments(ctx context.Context, payments []payment.Payment) ([]payment.Payment, error) {
+	const ops = "service.saleService.chargePayments"
+
+	charged := make([]payment.Payment, len(payments))
+	copy(charged, payments)
+
+	for i, p := range charged {
+		if !p.Method.RequiresGateway() {
+			continue
+		}
+
+		tx, err := s.paymentGateway.Authorize(ctx, p.Method, p.Amount, p.Token)
+		if err != nil {
+			logger.Error(ctx, ops, "error authorizing %s payment: %v", p.Method, err)
+			s.releasePayments(ctx, charged[:i])
+			return nil, err
+		}
+
+		transactionID := tx.ID
+		charged[i].Reference = &transactionID
+
+		if _, err = s.paymentGateway.Capture(ctx, transactionID); err != nil {
+			logger.Error(ctx, ops, "error capturing transaction %s: %v", transactionID, err)
+			s.releasePayments(ctx, charged[:i+1])
+			return nil, fmt.Errorf("%w: %v", payment.ErrCaptureFailed, err)
+		}
+	}
+
+	return charged, nil
+}
+
+// releasePayments gives back the whole of each gateway payment: a hold that
+// was never captured is released and a captured payment refunded.
+func (s *saleService) releasePayments(ctx context.Context, payments []payment.Payment) {
+	const ops = "service.saleService.releasePayments"
+
+	for _, p := range payments {
+		if !p.Method.RequiresGateway() || p.Reference == nil {
+			continue
+		}
+
+		if _, err := s.paymentGateway.Refund(ctx, *p.Reference, p.Amount, "release-"+*p.Reference); err != nil {
+			logger.Error(ctx, ops, "error releasing transaction %s: %v", *p.Reference, err)
+		}
+	}
+}
+
+func (s *saleService) GetSales(ctx context.Context, merchantID, outletID, lastID, limit int) (sales []sale.Sale, totalData int, err error) {
+	const ops = "service.saleService.GetSales"
+	paginationOpts := sale.RepositoryGetSalePaginationOptions{
+		Limit:  limit,
+		Cursor: lastID,
+	}
+
+	sales, totalData, err = s.saleRepository.Get(ctx, merchantID, outletID, &paginationOpts)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *saleService) GetSale(ctx context.Context, merchantID, saleID int) (entity sale.Sale, err error) {
+	const ops = "service.saleService.GetSale"
+
+	entity, err = s.saleRepository.GetSale(ctx, merchantID, saleID)
+	if err != nil {
+		logger.Error(ctx, ops, "unknown error: %v", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,515 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/inventory"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	omock "github.com/mhdiiilham/POS/entity/outlet/mock"
+	"github.com/mhdiiilham/POS/entity/payment"
+	pymock "github.com/mhdiiilham/POS/entity/payment/mock"
+	"github.com/mhdiiilham/POS/entity/sale"
+	samock "github.com/mhdiiilham/POS/entity/sale/mock"
+	"github.com/mhdiiilham/POS/entity/shift"
+	shmock "github.com/mhdiiilham/POS/entity/shift/mock"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_saleService_Checkout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cashPayment := []payment.Payment{{Method: payment.MethodCash, Amount: 100000}}
+
+	t.Run("failed - empty sale", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 1, nil, cashPayment)
+		assert.ErrorIs(t, err, sale.ErrEmptySale)
+	})
+
+	t.Run("failed - zero quantity", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 1, []sale.Item{{ProductID: 1, Quantity: 0}}, cashPayment)
+		assert.ErrorIs(t, err, sale.ErrInvalidSaleItem)
+	})
+
+	t.Run("failed - no payments", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 1, []sale.Item{{ProductID: 1, Quantity: 1}}, nil)
+		assert.ErrorIs(t, err, payment.ErrInsufficientPayment)
+	})
+
+	t.Run("failed - unknown payment method", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 1, []sale.Item{{ProductID: 1, Quantity: 1}}, []payment.Payment{{Method: "cheque", Amount: 1000}})
+		assert.ErrorIs(t, err, payment.ErrInvalidPaymentMethod)
+	})
+
+	t.Run("failed - payment method disabled on outlet", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return(nil, nil).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 2, []sale.Item{{ProductID: 1, Quantity: 1}}, []payment.Payment{{Method: payment.MethodEWallet, Amount: 1000}})
+		assert.ErrorIs(t, err, payment.ErrPaymentMethodDisabled)
+	})
+
+	t.Run("failed - payments do not cover total", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return(nil, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 1).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 1}, nil).
+			Times(1)
+
+		saleRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(sale.Sale{}, payment.ErrInsufficientPayment).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 2, []sale.Item{{ProductID: 1, Quantity: 1}}, []payment.Payment{{Method: payment.MethodCash, Amount: 1}})
+		assert.ErrorIs(t, err, payment.ErrInsufficientPayment)
+	})
+
+	t.Run("failed - outlet of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{}, outlet.ErrOutletNotFound).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 2, []sale.Item{{ProductID: 1, Quantity: 1}}, cashPayment)
+		assert.ErrorIs(t, err, outlet.ErrOutletNotFound)
+	})
+
+	t.Run("failed - no open shift", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return(nil, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 1).
+			Return(shift.Shift{}, shift.ErrNoOpenShift).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 2, []sale.Item{{ProductID: 1, Quantity: 1}}, cashPayment)
+		assert.ErrorIs(t, err, shift.ErrNoOpenShift)
+	})
+
+	t.Run("failed - insufficient stock", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return(nil, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 1).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 1}, nil).
+			Times(1)
+
+		saleRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(sale.Sale{}, fmt.Errorf("%w: product 1", inventory.ErrInsufficientStock)).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 1, 2, []sale.Item{{ProductID: 1, Quantity: 100}}, cashPayment)
+		assert.ErrorIs(t, err, inventory.ErrInsufficientStock)
+	})
+
+	t.Run("success - duplicate lines are merged", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return(nil, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 3).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3}, nil).
+			Times(1)
+
+		saleRepository.
+			EXPECT().
+			Create(ctx, sale.Sale{
+				MerchantID: 1,
+				OutletID:   2,
+				ShiftID:    6,
+				CashierID:  3,
+				Status:     sale.StatusCompleted,
+				Items: []sale.Item{
+					{ProductID: 5, Quantity: 3},
+					{ProductID: 4, Quantity: 1},
+				},
+				Payments: cashPayment,
+			}).
+			Return(sale.Sale{ID: 10, Total: 45000, Paid: 100000, Change: 55000}, nil).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		resp, err := s.Checkout(ctx, 1, 3, 2, []sale.Item{
+			{ProductID: 5, Quantity: 1},
+			{ProductID: 4, Quantity: 1},
+			{ProductID: 5, Quantity: 2},
+		}, cashPayment)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, resp.ID)
+		assert.Equal(t, 55000.0, resp.Change)
+	})
+
+	t.Run("failed - card declined", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		payments := []payment.Payment{
+			{Method: payment.MethodEWallet, Amount: 10000, Token: "qr-1"},
+			{Method: payment.MethodCard, Amount: 35000, Token: "card-1"},
+		}
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return([]payment.OutletPaymentMethod{
+				{OutletID: 2, Method: payment.MethodCard, Enabled: true},
+				{OutletID: 2, Method: payment.MethodEWallet, Enabled: true},
+			}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 3).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Authorize(ctx, payment.MethodEWallet, 10000.0, "qr-1").
+			Return(payment.GatewayTransaction{ID: "tx-1", Status: payment.GatewayStatusAuthorized}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Capture(ctx, "tx-1").
+			Return(payment.GatewayTransaction{ID: "tx-1", Status: payment.GatewayStatusCaptured}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Authorize(ctx, payment.MethodCard, 35000.0, "card-1").
+			Return(payment.GatewayTransaction{}, payment.ErrPaymentDeclined).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, "tx-1", 10000.0, "release-tx-1").
+			Return(payment.GatewayTransaction{ID: "tx-1", Status: payment.GatewayStatusRefunded}, nil).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 3, 2, []sale.Item{{ProductID: 5, Quantity: 1}}, payments)
+		assert.ErrorIs(t, err, payment.ErrPaymentDeclined)
+	})
+
+	t.Run("failed - capture fails, the hold is released", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		payments := []payment.Payment{{Method: payment.MethodCard, Amount: 35000, Token: "card-1"}}
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return([]payment.OutletPaymentMethod{{OutletID: 2, Method: payment.MethodCard, Enabled: true}}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 3).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Authorize(ctx, payment.MethodCard, 35000.0, "card-1").
+			Return(payment.GatewayTransaction{ID: "tx-1", Status: payment.GatewayStatusAuthorized}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Capture(ctx, "tx-1").
+			Return(payment.GatewayTransaction{}, payment.ErrInvalidTransition).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Refund(ctx, "tx-1", 35000.0, "release-tx-1").
+			Return(payment.GatewayTransaction{ID: "tx-1", Status: payment.GatewayStatusReleased}, nil).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.Checkout(ctx, 1, 3, 2, []sale.Item{{ProductID: 5, Quantity: 1}}, payments)
+		assert.ErrorIs(t, err, payment.ErrCaptureFailed)
+	})
+
+	t.Run("success - split tender captures card payment", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+		transactionID := "tx-1"
+		payments := []payment.Payment{
+			{Method: payment.MethodCard, Amount: 30000, Token: "card-1"},
+			{Method: payment.MethodCash, Amount: 20000},
+		}
+		charged := []payment.Payment{
+			{Method: payment.MethodCard, Amount: 30000, Token: "card-1", Reference: &transactionID},
+			{Method: payment.MethodCash, Amount: 20000},
+		}
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		paymentRepository.
+			EXPECT().
+			GetOutletMethods(ctx, 2).
+			Return([]payment.OutletPaymentMethod{{OutletID: 2, Method: payment.MethodCard, Enabled: true}}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			FindOpenShift(ctx, 1, 2, 3).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Authorize(ctx, payment.MethodCard, 30000.0, "card-1").
+			Return(payment.GatewayTransaction{ID: transactionID, Status: payment.GatewayStatusAuthorized}, nil).
+			Times(1)
+
+		paymentGateway.
+			EXPECT().
+			Capture(ctx, transactionID).
+			Return(payment.GatewayTransaction{ID: transactionID, Status: payment.GatewayStatusCaptured}, nil).
+			Times(1)
+
+		saleRepository.
+			EXPECT().
+			Create(ctx, sale.Sale{
+				MerchantID: 1,
+				OutletID:   2,
+				ShiftID:    6,
+				CashierID:  3,
+				Status:     sale.StatusCompleted,
+				Items:      []sale.Item{{ProductID: 5, Quantity: 1}},
+				Payments:   charged,
+			}).
+			Return(sale.Sale{ID: 10, Total: 45000, Paid: 50000, Change: 5000, Payments: charged}, nil).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		resp, err := s.Checkout(ctx, 1, 3, 2, []sale.Item{{ProductID: 5, Quantity: 1}}, payments)
+		assert.NoError(t, err)
+		assert.Equal(t, 5000.0, resp.Change)
+		assert.Equal(t, &transactionID, resp.Payments[0].Reference)
+	})
+}
+
+func Test_saleService_GetSale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - sale not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		saleRepository := samock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		paymentRepository := pymock.NewMockRepository(ctrl)
+		paymentGateway := smock.NewMockPaymentGateway(ctrl)
+
+		saleRepository.
+			EXPECT().
+			GetSale(ctx, 1, 9).
+			Return(sale.Sale{}, sale.ErrSaleNotFound).
+			Times(1)
+
+		s := service.NewSaleService(saleRepository, outletRepository, shiftRepository, paymentRepository, paymentGateway)
+		_, err := s.GetSale(ctx, 1, 9)
+		assert.ErrorIs(t, err, sale.ErrSaleNotFound)
+	})
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type sessionService struct {
+	sessionRepository session.Repository
+	userRepository    user.Repository
+	roleRepository    role.Repository
+	tokenSigner       TokenSigner
+}
+
+func NewSessionService(sessionRepository session.Repository, userRepository user.Repository, roleRepository role.Repository, tokenSigner TokenSigner) *sessionService {
+	return &sessionService{
+		sessionRepository: sessionRepository,
+		userRepository:    userRepository,
+		roleRepository:    roleRepository,
+		tokenSigner:       tokenSigner,
+	}
+}
+
+// Refresh exchanges a refresh token for a new token pair. Refresh tokens are
+// single use: presenting one that was already rotated means it leaked, so the
+// whole session is revoked.
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (token session.Token, err error) {
+	const ops = "service.sessionService.Refresh"
+	var entity session.Session
+	var u user.User
+	now := time.Now()
+
+	parts := strings.SplitN(refreshToken, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return session.Token{}, session.ErrInvalidRefreshToken
+	}
+	sessionID, secret := parts[0], parts[1]
+
+	// Session ids are uuids; anything else can't be one and would only fail
+	// in the database.
+	if _, err = uuid.Parse(sessionID); err != nil {
+		return session.Token{}, session.ErrInvalidRefreshToken
+	}
+
+	entity, err = s.sessionRepository.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionNotFound) {
+			return session.Token{}, session.ErrInvalidRefreshToken
+		}
+
+		logger.Error(ctx, ops, "error getting session: %v", err)
+		return session.Token{}, err
+	}
+
+	if !entity.Active(now) {
+		return session.Token{}, session.ErrInvalidRefreshToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(entity.RefreshTokenHash)) != 1 {
+		logger.Info(ctx, ops, "refresh token reuse on session %s, revoking it", entity.ID)
+		if err = s.sessionRepository.Revoke(ctx, entity.ID); err != nil {
+			logger.Error(ctx, ops, "error revoking session: %v", err)
+			return session.Token{}, err
+		}
+		return session.Token{}, session.ErrInvalidRefreshToken
+	}
+
+	u, err = s.userRepository.GetUser(ctx, entity.MerchantID, entity.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return session.Token{}, session.ErrInvalidRefreshToken
+		}
+
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return session.Token{}, err
+	}
+
+	if entity.OutletID != nil {
+		u, err = limitToCashier(ctx, s.roleRepository, u)
+		if err != nil {
+			logger.Error(ctx, ops, "error limiting outlet session: %v", err)
+			return session.Token{}, err
+		}
+	}
+
+	secret, err = newSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating refresh token: %v", err)
+		return session.Token{}, err
+	}
+
+	err = s.sessionRepository.Rotate(ctx, entity.ID, entity.RefreshTokenHash, hashSecret(secret), now.Add(session.RefreshTokenTTL))
+	if err != nil {
+		logger.Error(ctx, ops, "error rotating refresh token: %v", err)
+		return session.Token{}, err
+	}
+
+	token.AccessToken, err = s.tokenSigner.Sign(ctx, identityOf(u, entity))
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to sign access token %v", err)
+		return session.Token{}, err
+	}
+
+	token.RefreshToken = entity.ID + "." + secret
+	token.ExpiresAt = now.Add(session.AccessTokenTTL)
+	return token, nil
+}
+
+func (s *sessionService) Logout(ctx context.Context, sessionID string) (err error) {
+	const ops = "service.sessionService.Logout"
+
+	if err = s.sessionRepository.Revoke(ctx, sessionID); err != nil {
+		logger.Error(ctx, ops, "error revoking session: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Authenticate rejects access tokens whose session was revoked or whose user
+// was deleted after the token was issued.
+func (s *sessionService) Authenticate(ctx context.Context, sessionID string, userID int) (err error) {
+	const ops = "service.sessionService.Authenticate"
+
+	active, err := s.sessionRepository.IsActive(ctx, sessionID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error checking session: %v", err)
+		return err
+	}
+
+	if !active {
+		return session.ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// startSession stores a new session for u and signs its first token pair. A
+// non-nil outletID ties the session to that outlet.
+func startSession(ctx context.Context, sessionRepository session.Repository, tokenSigner TokenSigner, u user.User, outletID *int) (token session.Token, err error) {
+	const ops = "service.startSession"
+	now := time.Now()
+
+	secret, err := newSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating refresh token: %v", err)
+		return session.Token{}, err
+	}
+
+	entity := session.Session{
+		ID:               uuid.New().String(),
+		UserID:           u.ID,
+		MerchantID:       u.MerchantID,
+		OutletID:         outletID,
+		RefreshTokenHash: hashSecret(secret),
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(session.RefreshTokenTTL),
+	}
+	if err = sessionRepository.Create(ctx, entity); err != nil {
+		logger.Error(ctx, ops, "error creating session: %v", err)
+		return session.Token{}, err
+	}
+
+	token.AccessToken, err = tokenSigner.Sign(ctx, identityOf(u, entity))
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to sign access token %v", err)
+		return session.Token{}, err
+	}
+
+	token.RefreshToken = entity.ID + "." + secret
+	token.ExpiresAt = now.Add(session.AccessTokenTTL)
+	return token, nil
+}
+
+func identityOf(u user.User, entity session.Session) session.Identity {
+	identity := session.Identity{
+		UserID:      u.ID,
+		MerchantID:  u.MerchantID,
+		Email:       u.Email,
+		SessionID:   entity.ID,
+		Role:        u.Role,
+		Permissions: u.Permissions,
+	}
+	if entity.OutletID != nil {
+		identity.OutletID = *entity.OutletID
+	}
+	return identity
+}
+
+// limitToCashier narrows u's permissions to those of the built-in cashier
+// role, for sessions started at an outlet terminal.
+func limitToCashier(ctx context.Context, roleRepository role.Repository, u user.User) (user.User, error) {
+	cashier, err := roleRepository.GetRole(ctx, u.MerchantID, role.CashierRoleID)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u.Permissions = role.Intersect(u.Permissions, cashier.Permissions)
+	return u, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,303 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/role"
+	romock "github.com/mhdiiilham/POS/entity/role/mock"
+	"github.com/mhdiiilham/POS/entity/session"
+	sesmock "github.com/mhdiiilham/POS/entity/session/mock"
+	"github.com/mhdiiilham/POS/entity/user"
+	umock "github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func refreshHash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_sessionService_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const sid = "0b6e4a3c-5f1d-4c8e-9a27-3d5b8f1e6c40"
+
+	t.Run("failed - malformed token", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		_, err := s.Refresh(ctx, "not-a-refresh-token")
+		assert.ErrorIs(t, err, session.ErrInvalidRefreshToken)
+	})
+
+	t.Run("failed - session id is not a uuid", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		_, err := s.Refresh(ctx, "abc.def")
+		assert.ErrorIs(t, err, session.ErrInvalidRefreshToken)
+	})
+
+	t.Run("failed - revoked session", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		revokedAt := time.Now().Add(-time.Minute)
+
+		sessionRepository.
+			EXPECT().
+			GetSession(ctx, sid).
+			Return(session.Session{
+				ID:               sid,
+				UserID:           1,
+				RefreshTokenHash: refreshHash("secret"),
+				ExpiresAt:        time.Now().Add(time.Hour),
+				RevokedAt:        &revokedAt,
+			}, nil).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		_, err := s.Refresh(ctx, sid+".secret")
+		assert.ErrorIs(t, err, session.ErrInvalidRefreshToken)
+	})
+
+	t.Run("failed - reused token revokes session", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		sessionRepository.
+			EXPECT().
+			GetSession(ctx, sid).
+			Return(session.Session{
+				ID:               sid,
+				UserID:           1,
+				RefreshTokenHash: refreshHash("rotated"),
+				ExpiresAt:        time.Now().Add(time.Hour),
+			}, nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Revoke(ctx, sid).
+			Return(nil).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		_, err := s.Refresh(ctx, sid+".secret")
+		assert.ErrorIs(t, err, session.ErrInvalidRefreshToken)
+	})
+
+	t.Run("failed - user deleted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		sessionRepository.
+			EXPECT().
+			GetSession(ctx, sid).
+			Return(session.Session{
+				ID:               sid,
+				UserID:           1,
+				RefreshTokenHash: refreshHash("secret"),
+				ExpiresAt:        time.Now().Add(time.Hour),
+			}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 0, 1).
+			Return(user.User{}, user.ErrUserNotFound).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		_, err := s.Refresh(ctx, sid+".secret")
+		assert.ErrorIs(t, err, session.ErrInvalidRefreshToken)
+	})
+
+	t.Run("success - refresh token is rotated", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		sessionRepository.
+			EXPECT().
+			GetSession(ctx, sid).
+			Return(session.Session{
+				ID:               sid,
+				UserID:           1,
+				MerchantID:       2,
+				RefreshTokenHash: refreshHash("secret"),
+				ExpiresAt:        time.Now().Add(time.Hour),
+			}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 2, 1).
+			Return(user.User{ID: 1, MerchantID: 2, Email: "cashier@shop.id"}, nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Rotate(ctx, sid, refreshHash("secret"), gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		tokenSigner.
+			EXPECT().
+			Sign(ctx, session.Identity{UserID: 1, MerchantID: 2, Email: "cashier@shop.id", SessionID: sid}).
+			Return("access-token", nil).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		token, err := s.Refresh(ctx, sid+".secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", token.AccessToken)
+		assert.True(t, strings.HasPrefix(token.RefreshToken, sid+"."))
+		assert.NotEqual(t, sid+".secret", token.RefreshToken)
+	})
+
+	t.Run("success - outlet session keeps cashier permissions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outletID := 4
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		sessionRepository.
+			EXPECT().
+			GetSession(ctx, sid).
+			Return(session.Session{
+				ID:               sid,
+				UserID:           1,
+				MerchantID:       2,
+				OutletID:         &outletID,
+				RefreshTokenHash: refreshHash("secret"),
+				ExpiresAt:        time.Now().Add(time.Hour),
+			}, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 2, 1).
+			Return(user.User{ID: 1, MerchantID: 2, Email: "manager@shop.id", Role: "manager", Permissions: []role.Permission{role.PermissionUserWrite, role.PermissionSaleCreate}}, nil).
+			Times(1)
+
+		roleRepository.
+			EXPECT().
+			GetRole(ctx, 2, role.CashierRoleID).
+			Return(role.Role{ID: role.CashierRoleID, Name: "cashier", Permissions: []role.Permission{role.PermissionSaleCreate, role.PermissionSaleRead}}, nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Rotate(ctx, sid, refreshHash("secret"), gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		tokenSigner.
+			EXPECT().
+			Sign(ctx, session.Identity{
+				UserID:      1,
+				MerchantID:  2,
+				OutletID:    outletID,
+				Email:       "manager@shop.id",
+				SessionID:   sid,
+				Role:        "manager",
+				Permissions: []role.Permission{role.PermissionSaleCreate},
+			}).
+			Return("access-token", nil).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		token, err := s.Refresh(ctx, sid+".secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", token.AccessToken)
+	})
+}
+
+func Test_sessionService_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - session revoked", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		sessionRepository.
+			EXPECT().
+			IsActive(ctx, "sid", 1).
+			Return(false, nil).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		err := s.Authenticate(ctx, "sid", 1)
+		assert.ErrorIs(t, err, session.ErrSessionRevoked)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		userRepository := umock.NewMockRepository(ctrl)
+		roleRepository := romock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+
+		sessionRepository.
+			EXPECT().
+			IsActive(ctx, "sid", 1).
+			Return(true, nil).
+			Times(1)
+
+		s := service.NewSessionService(sessionRepository, userRepository, roleRepository, tokenSigner)
+		err := s.Authenticate(ctx, "sid", 1)
+		assert.NoError(t, err)
+	})
+}
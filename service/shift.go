@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/POS/entity/outlet"
+	"github.com/mhdiiilham/POS/entity/shift"
+	"github.com/mhdiiilham/POS/pkg/logger"
+)
+
+type shiftService struct {
+	shiftRepository  shift.Repository
+	outletRepository outlet.Repository
+}
+
+func NewShiftService(shiftRepository shift.Repository, outletRepository outlet.Repository) *shiftService {
+	return &shiftService{
+		shiftRepository:  shiftRepository,
+		outletRepository: outletRepository,
+	}
+}
+
+func (s *shiftService) OpenShift(ctx context.Context, merchantID, userID, outletID int, openingFloat float64) (entity shift.Shift, err error) {
+	const ops = "service.shiftService.OpenShift"
+	var insertedID int64
+
+	if openingFloat < 0 {
+		return shift.Shift{}, shift.ErrInvalidShiftParameters
+	}
+
+	if _, err = s.outletRepository.GetOutlet(ctx, merchantID, outletID); err != nil {
+		logger.Error(ctx, ops, "error getting outlet: %v", err)
+		return shift.Shift{}, err
+	}
+
+	insertedID, err = s.shiftRepository.Open(ctx, shift.Shift{
+		MerchantID:   merchantID,
+		OutletID:     outletID,
+		UserID:       userID,
+		OpeningFloat: openingFloat,
+	})
+	if err != nil {
+		logger.Error(ctx, ops, "error opening shift: %v", err)
+		return shift.Shift{}, err
+	}
+
+	return s.shiftRepository.GetShift(ctx, merchantID, int(insertedID))
+}
+
+// CloseShift ends the caller's shift with the cash counted in the drawer and
+// returns it with the over/short difference against the expected cash.
+func (s *shiftService) CloseShift(ctx context.Context, merchantID, userID, outletID, shiftID int, countedCash float64, note *string) (entity shift.Shift, err error) {
+	const ops = "service.shiftService.CloseShift"
+
+	if countedCash < 0 {
+		return shift.Shift{}, shift.ErrInvalidShiftParameters
+	}
+
+	entity, err = s.shiftRepository.GetShift(ctx, merchantID, shiftID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting shift: %v", err)
+		return shift.Shift{}, err
+	}
+
+	if entity.OutletID != outletID || entity.UserID != userID {
+		return shift.Shift{}, shift.ErrShiftNotFound
+	}
+
+	entity, err = s.shiftRepository.Close(ctx, shift.Shift{
+		ID:          shiftID,
+		MerchantID:  merchantID,
+		CountedCash: &countedCash,
+		Note:        note,
+	})
+	if err != nil {
+		logger.Error(ctx, ops, "error closing shift: %v", err)
+		return shift.Shift{}, err
+	}
+
+	logger.Info(ctx, ops, "shift %d closed with difference %.2f", entity.ID, *entity.Difference)
+	return entity, nil
+}
+
+func (s *shiftService) GetCurrentShift(ctx context.Context, merchantID, userID, outletID int) (entity shift.Shift, err error) {
+	const ops = "service.shiftService.GetCurrentShift"
+
+	entity, err = s.shiftRepository.FindOpenShift(ctx, merchantID, outletID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error finding open shift: %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *shiftService) GetShifts(ctx context.Context, merchantID, outletID, lastID, limit int) (shifts []shift.Shift, totalData int, err error) {
+	const ops = "service.shiftService.GetShifts"
+	paginationOpts := shift.RepositoryGetShiftPaginationOptions{
+		Limit:  limit,
+		Cursor: lastID,
+	}
+
+	shifts, totalData, err = s.shiftRepository.Get(ctx, merchantID, outletID, &paginationOpts)
+	if err != nil {
+		logger.Error(ctx, ops, "unexpected error %v", err)
+		return
+	}
+
+	return
+}
+
+func (s *shiftService) GetShift(ctx context.Context, merchantID, outletID, shiftID int) (entity shift.Shift, err error) {
+	const ops = "service.shiftService.GetShift"
+
+	entity, err = s.shiftRepository.GetShift(ctx, merchantID, shiftID)
+	if err != nil {
+		logger.Error(ctx, ops, "unknown error: %v", err)
+		return
+	}
+
+	if entity.OutletID != outletID {
+		return shift.Shift{}, shift.ErrShiftNotFound
+	}
+
+	return
+}
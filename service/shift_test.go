@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/outlet"
+	omock "github.com/mhdiiilham/POS/entity/outlet/mock"
+	"github.com/mhdiiilham/POS/entity/shift"
+	shmock "github.com/mhdiiilham/POS/entity/shift/mock"
+	"github.com/mhdiiilham/POS/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_shiftService_OpenShift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - negative opening float", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		_, err := s.OpenShift(ctx, 1, 3, 2, -1)
+		assert.ErrorIs(t, err, shift.ErrInvalidShiftParameters)
+	})
+
+	t.Run("failed - outlet of other merchant", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{}, outlet.ErrOutletNotFound).
+			Times(1)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		_, err := s.OpenShift(ctx, 1, 3, 2, 100000)
+		assert.ErrorIs(t, err, outlet.ErrOutletNotFound)
+	})
+
+	t.Run("failed - shift already open", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			Open(ctx, shift.Shift{MerchantID: 1, OutletID: 2, UserID: 3, OpeningFloat: 100000}).
+			Return(int64(0), shift.ErrShiftAlreadyOpen).
+			Times(1)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		_, err := s.OpenShift(ctx, 1, 3, 2, 100000)
+		assert.ErrorIs(t, err, shift.ErrShiftAlreadyOpen)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		outletRepository.
+			EXPECT().
+			GetOutlet(ctx, 1, 2).
+			Return(outlet.Outlet{ID: 2, MerchantID: 1}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			Open(ctx, shift.Shift{MerchantID: 1, OutletID: 2, UserID: 3, OpeningFloat: 100000}).
+			Return(int64(6), nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			GetShift(ctx, 1, 6).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3, OpeningFloat: 100000, ExpectedCash: 100000}, nil).
+			Times(1)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		resp, err := s.OpenShift(ctx, 1, 3, 2, 100000)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, resp.ID)
+		assert.True(t, resp.IsOpen())
+	})
+}
+
+func Test_shiftService_CloseShift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("failed - negative counted cash", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		_, err := s.CloseShift(ctx, 1, 3, 2, 6, -5, nil)
+		assert.ErrorIs(t, err, shift.ErrInvalidShiftParameters)
+	})
+
+	t.Run("failed - shift of other user", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+
+		shiftRepository.
+			EXPECT().
+			GetShift(ctx, 1, 6).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 4}, nil).
+			Times(1)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		_, err := s.CloseShift(ctx, 1, 3, 2, 6, 150000, nil)
+		assert.ErrorIs(t, err, shift.ErrShiftNotFound)
+	})
+
+	t.Run("failed - shift already closed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		countedCash := 150000.0
+
+		shiftRepository.
+			EXPECT().
+			GetShift(ctx, 1, 6).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			Close(ctx, shift.Shift{ID: 6, MerchantID: 1, CountedCash: &countedCash}).
+			Return(shift.Shift{}, shift.ErrShiftClosed).
+			Times(1)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		_, err := s.CloseShift(ctx, 1, 3, 2, 6, countedCash, nil)
+		assert.ErrorIs(t, err, shift.ErrShiftClosed)
+	})
+
+	t.Run("success - drawer short", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		shiftRepository := shmock.NewMockRepository(ctrl)
+		outletRepository := omock.NewMockRepository(ctrl)
+		countedCash := 145000.0
+		difference := -5000.0
+		closedAt := time.Now()
+
+		shiftRepository.
+			EXPECT().
+			GetShift(ctx, 1, 6).
+			Return(shift.Shift{ID: 6, MerchantID: 1, OutletID: 2, UserID: 3}, nil).
+			Times(1)
+
+		shiftRepository.
+			EXPECT().
+			Close(ctx, shift.Shift{ID: 6, MerchantID: 1, CountedCash: &countedCash}).
+			Return(shift.Shift{
+				ID:           6,
+				MerchantID:   1,
+				OutletID:     2,
+				UserID:       3,
+				ExpectedCash: 150000,
+				CountedCash:  &countedCash,
+				Difference:   &difference,
+				ClosedAt:     &closedAt,
+			}, nil).
+			Times(1)
+
+		s := service.NewShiftService(shiftRepository, outletRepository)
+		resp, err := s.CloseShift(ctx, 1, 3, 2, 6, countedCash, nil)
+		assert.NoError(t, err)
+		assert.False(t, resp.IsOpen())
+		assert.Equal(t, difference, *resp.Difference)
+	})
+}
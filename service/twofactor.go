@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mhdiiilham/POS/entity/session"
+	"github.com/mhdiiilham/POS/entity/twofactor"
+	"github.com/mhdiiilham/POS/entity/user"
+	"github.com/mhdiiilham/POS/pkg/logger"
+	"github.com/mhdiiilham/POS/pkg/totp"
+)
+
+type twoFactorService struct {
+	userRepository      user.Repository
+	twoFactorRepository twofactor.Repository
+	sessionRepository   session.Repository
+	tokenSigner         TokenSigner
+	loginAttempts       LoginAttemptStore
+	issuer              string
+}
+
+// NewTwoFactorService handles TOTP enrollment and the second step of logins.
+// loginAttempts must be the store password logins use, so wrong codes count
+// against the same account lockout. issuer is the name authenticator apps
+// show next to the account.
+func NewTwoFactorService(userRepository user.Repository, twoFactorRepository twofactor.Repository, sessionRepository session.Repository, tokenSigner TokenSigner, loginAttempts LoginAttemptStore, issuer string) *twoFactorService {
+	return &twoFactorService{
+		userRepository:      userRepository,
+		twoFactorRepository: twoFactorRepository,
+		sessionRepository:   sessionRepository,
+		tokenSigner:         tokenSigner,
+		loginAttempts:       loginAttempts,
+		issuer:              issuer,
+	}
+}
+
+// Enroll gives the user a new secret and recovery codes. Two-factor
+// authentication is not enforced until ConfirmEnrollment sees a valid code.
+func (s *twoFactorService) Enroll(ctx context.Context, merchantID, userID int) (setup twofactor.Setup, err error) {
+	const ops = "service.twoFactorService.Enroll"
+
+	u, err := s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return twofactor.Setup{}, err
+	}
+
+	return s.enroll(ctx, u)
+}
+
+func (s *twoFactorService) ConfirmEnrollment(ctx context.Context, userID int, code string) (err error) {
+	const ops = "service.twoFactorService.ConfirmEnrollment"
+
+	enrollment, err := s.twoFactorRepository.GetEnrollment(ctx, userID)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrNotEnrolled) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error getting enrollment: %v", err)
+		return err
+	}
+
+	if enrollment.Enabled() {
+		return twofactor.ErrAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+	if !ok {
+		return twofactor.ErrInvalidCode
+	}
+
+	if err = s.twoFactorRepository.Enable(ctx, userID, step); err != nil {
+		logger.Error(ctx, ops, "error enabling two-factor authentication: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Disable turns two-factor authentication off after checking a code. Users
+// whose role requires it cannot turn it off.
+func (s *twoFactorService) Disable(ctx context.Context, merchantID, userID int, code string) (err error) {
+	const ops = "service.twoFactorService.Disable"
+
+	u, err := s.userRepository.GetUser(ctx, merchantID, userID)
+	if err != nil {
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return err
+	}
+
+	if twofactor.Required(u.RoleID) {
+		return twofactor.ErrRequired
+	}
+
+	enrollment, err := s.twoFactorRepository.GetEnrollment(ctx, userID)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrNotEnrolled) {
+			return err
+		}
+
+		logger.Error(ctx, ops, "error getting enrollment: %v", err)
+		return err
+	}
+
+	if !enrollment.Enabled() {
+		return twofactor.ErrNotEnrolled
+	}
+
+	if err = s.verifyCode(ctx, enrollment, code); err != nil {
+		return err
+	}
+
+	if err = s.twoFactorRepository.Disable(ctx, userID); err != nil {
+		logger.Error(ctx, ops, "error disabling two-factor authentication: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// EnrollForLogin lets a user whose role requires two-factor authentication,
+// but who never set it up, enroll with the challenge from their password
+// login. The first code from the new app then completes the login.
+func (s *twoFactorService) EnrollForLogin(ctx context.Context, challengeToken string) (setup twofactor.Setup, err error) {
+	const ops = "service.twoFactorService.EnrollForLogin"
+
+	challenge, err := s.twoFactorRepository.GetChallenge(ctx, hashSecret(challengeToken))
+	if err != nil {
+		if errors.Is(err, twofactor.ErrInvalidChallenge) {
+			return twofactor.Setup{}, err
+		}
+
+		logger.Error(ctx, ops, "error getting challenge: %v", err)
+		return twofactor.Setup{}, err
+	}
+
+	u, err := s.userRepository.GetUser(ctx, challenge.MerchantID, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return twofactor.Setup{}, twofactor.ErrInvalidChallenge
+		}
+
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return twofactor.Setup{}, err
+	}
+
+	return s.enroll(ctx, u)
+}
+
+// VerifyLogin finishes a login with the code from the user's authenticator
+// app, or one of their recovery codes, and starts the session. Wrong codes
+// count against the account like wrong passwords, and the account count is
+// only cleared here, once both steps passed.
+func (s *twoFactorService) VerifyLogin(ctx context.Context, challengeToken, code string) (token session.Token, err error) {
+	const ops = "service.twoFactorService.VerifyLogin"
+	now := time.Now()
+
+	challenge, err := s.twoFactorRepository.GetChallenge(ctx, hashSecret(challengeToken))
+	if err != nil {
+		if errors.Is(err, twofactor.ErrInvalidChallenge) {
+			return session.Token{}, err
+		}
+
+		logger.Error(ctx, ops, "error getting challenge: %v", err)
+		return session.Token{}, err
+	}
+
+	u, err := s.userRepository.GetUser(ctx, challenge.MerchantID, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return session.Token{}, twofactor.ErrInvalidChallenge
+		}
+
+		logger.Error(ctx, ops, "error getting user: %v", err)
+		return session.Token{}, err
+	}
+
+	account := attemptLimit{loginAccountKey(u.Email), user.AccountFreeLoginAttempts}
+	if err = takeAttempts(ctx, s.loginAttempts, now, user.LoginAttemptWindow, account); err != nil {
+		var lockout *user.LockoutError
+		if !errors.As(err, &lockout) {
+			logger.Error(ctx, ops, "error counting login attempt: %v", err)
+		}
+		return session.Token{}, err
+	}
+
+	// Wrong codes keep their count and a verified login resets it; failures
+	// of our own hand the attempt back.
+	defer func() {
+		if err != nil && !errors.Is(err, twofactor.ErrInvalidCode) {
+			releaseAttempts(ctx, s.loginAttempts, account)
+		}
+	}()
+
+	enrollment, err := s.twoFactorRepository.GetEnrollment(ctx, u.ID)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrNotEnrolled) {
+			return session.Token{}, err
+		}
+
+		logger.Error(ctx, ops, "error getting enrollment: %v", err)
+		return session.Token{}, err
+	}
+
+	if enrollment.Enabled() {
+		err = s.verifyCode(ctx, enrollment, code)
+	} else if step, ok := totp.Validate(enrollment.Secret, code, now); ok {
+		err = s.twoFactorRepository.Enable(ctx, u.ID, step)
+	} else {
+		err = twofactor.ErrInvalidCode
+	}
+
+	if err != nil {
+		if !errors.Is(err, twofactor.ErrInvalidCode) {
+			logger.Error(ctx, ops, "error verifying code: %v", err)
+			return session.Token{}, err
+		}
+
+		if failErr := s.twoFactorRepository.RecordChallengeFailure(ctx, challenge.ID); failErr != nil {
+			logger.Error(ctx, ops, "error recording failed attempt: %v", failErr)
+			return session.Token{}, failErr
+		}
+		return session.Token{}, err
+	}
+
+	if err = s.twoFactorRepository.RedeemChallenge(ctx, challenge.ID); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidChallenge) {
+			return session.Token{}, err
+		}
+
+		logger.Error(ctx, ops, "error redeeming challenge: %v", err)
+		return session.Token{}, err
+	}
+
+	if err = s.loginAttempts.Reset(ctx, account.key); err != nil {
+		logger.Error(ctx, ops, "error resetting login attempts: %v", err)
+		return session.Token{}, err
+	}
+
+	token, err = startSession(ctx, s.sessionRepository, s.tokenSigner, u, nil)
+	if err != nil {
+		logger.Error(ctx, ops, "error trying to start session %v", err)
+		return session.Token{}, err
+	}
+
+	return token, nil
+}
+
+func (s *twoFactorService) enroll(ctx context.Context, u user.User) (setup twofactor.Setup, err error) {
+	const ops = "service.twoFactorService.enroll"
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		logger.Error(ctx, ops, "error generating secret: %v", err)
+		return twofactor.Setup{}, err
+	}
+
+	codes := make([]string, twofactor.RecoveryCodeCount)
+	hashes := make([]string, twofactor.RecoveryCodeCount)
+	for i := range codes {
+		codes[i], err = newRecoveryCode()
+		if err != nil {
+			logger.Error(ctx, ops, "error generating recovery code: %v", err)
+			return twofactor.Setup{}, err
+		}
+		hashes[i] = hashSecret(normalizeRecoveryCode(codes[i]))
+	}
+
+	entity := twofactor.Enrollment{
+		UserID:    u.ID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	if err = s.twoFactorRepository.Enroll(ctx, entity, hashes); err != nil {
+		if errors.Is(err, twofactor.ErrAlreadyEnabled) {
+			return twofactor.Setup{}, err
+		}
+
+		logger.Error(ctx, ops, "error storing enrollment: %v", err)
+		return twofactor.Setup{}, err
+	}
+
+	return twofactor.Setup{
+		Secret:        secret,
+		URI:           totp.URI(s.issuer, u.Email, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// verifyCode accepts a TOTP code, or else spends a recovery code.
+func (s *twoFactorService) verifyCode(ctx context.Context, enrollment twofactor.Enrollment, code string) error {
+	if step, ok := totp.Validate(enrollment.Secret, code, time.Now()); ok {
+		return s.twoFactorRepository.UseStep(ctx, enrollment.UserID, step)
+	}
+
+	code = normalizeRecoveryCode(code)
+	if code == "" {
+		return twofactor.ErrInvalidCode
+	}
+	return s.twoFactorRepository.UseRecoveryCode(ctx, enrollment.UserID, hashSecret(code))
+}
+
+// startChallenge stores a login challenge for u and returns its token.
+func startChallenge(ctx context.Context, twoFactorRepository twofactor.Repository, u user.User, enrollmentRequired bool) (challenge twofactor.LoginChallenge, err error) {
+	now := time.Now()
+
+	secret, err := newSecret()
+	if err != nil {
+		return twofactor.LoginChallenge{}, err
+	}
+
+	entity := twofactor.Challenge{
+		UserID:     u.ID,
+		MerchantID: u.MerchantID,
+		TokenHash:  hashSecret(secret),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(twofactor.ChallengeTTL),
+	}
+	if err = twoFactorRepository.CreateChallenge(ctx, entity); err != nil {
+		return twofactor.LoginChallenge{}, err
+	}
+
+	return twofactor.LoginChallenge{
+		Token:              secret,
+		ExpiresAt:          entity.ExpiresAt,
+		EnrollmentRequired: enrollmentRequired,
+	}, nil
+}
+
+// newRecoveryCode returns 80 random bits as four dash-separated groups, easy
+// to write down.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
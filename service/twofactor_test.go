@@ -0,0 +1,464 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mhdiiilham/POS/entity/role"
+	"github.com/mhdiiilham/POS/entity/session"
+	sesmock "github.com/mhdiiilham/POS/entity/session/mock"
+	"github.com/mhdiiilham/POS/entity/twofactor"
+	tfmock "github.com/mhdiiilham/POS/entity/twofactor/mock"
+	"github.com/mhdiiilham/POS/entity/user"
+	umock "github.com/mhdiiilham/POS/entity/user/mock"
+	"github.com/mhdiiilham/POS/pkg/totp"
+	"github.com/mhdiiilham/POS/service"
+	smock "github.com/mhdiiilham/POS/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const totpSecret = "JBSWY3DPEHPK3PXP"
+
+func currentCode(t *testing.T) string {
+	code, err := totp.Code(totpSecret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+	return code
+}
+
+func Test_twoFactorService_Enroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("success - stores the secret and hashed recovery codes", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var stored twofactor.Enrollment
+		var storedHashes []string
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "owner@shop.id"}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			Enroll(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, entity twofactor.Enrollment, hashes []string) error {
+				stored, storedHashes = entity, hashes
+				return nil
+			}).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		setup, err := s.Enroll(ctx, 1, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, stored.Secret, setup.Secret)
+		assert.Nil(t, stored.EnabledAt)
+		assert.True(t, strings.HasPrefix(setup.URI, "otpauth://totp/POS:owner@shop.id?"))
+		assert.Len(t, setup.RecoveryCodes, twofactor.RecoveryCodeCount)
+		assert.Len(t, storedHashes, twofactor.RecoveryCodeCount)
+		for _, code := range setup.RecoveryCodes {
+			assert.NotContains(t, storedHashes, code)
+		}
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "owner@shop.id"}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			Enroll(ctx, gomock.Any(), gomock.Any()).
+			Return(twofactor.ErrAlreadyEnabled).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		_, err := s.Enroll(ctx, 1, 7)
+		assert.ErrorIs(t, err, twofactor.ErrAlreadyEnabled)
+	})
+}
+
+func Test_twoFactorService_ConfirmEnrollment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("wrong code", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 7).
+			Return(twofactor.Enrollment{UserID: 7, Secret: totpSecret}, nil).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		err := s.ConfirmEnrollment(ctx, 7, "12345")
+		assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 7).
+			Return(twofactor.Enrollment{UserID: 7, Secret: totpSecret}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			Enable(ctx, 7, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		err := s.ConfirmEnrollment(ctx, 7, currentCode(t))
+		assert.NoError(t, err)
+	})
+}
+
+func Test_twoFactorService_Disable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("required for owners", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, RoleID: role.OwnerRoleID}, nil).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		err := s.Disable(ctx, 1, 7, currentCode(t))
+		assert.ErrorIs(t, err, twofactor.ErrRequired)
+	})
+
+	t.Run("success with a recovery code", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		enabledAt := time.Now().Add(-time.Hour)
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, RoleID: role.CashierRoleID}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 7).
+			Return(twofactor.Enrollment{UserID: 7, Secret: totpSecret, EnabledAt: &enabledAt}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			UseRecoveryCode(ctx, 7, refreshHash("abcdefghijklmnop")).
+			Return(nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			Disable(ctx, 7).
+			Return(nil).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		err := s.Disable(ctx, 1, 7, "ABCD-EFGH-IJKL-MNOP")
+		assert.NoError(t, err)
+	})
+}
+
+func Test_twoFactorService_VerifyLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	challenge := twofactor.Challenge{ID: 3, UserID: 7, MerchantID: 1, TokenHash: refreshHash("challenge")}
+
+	t.Run("invalid challenge", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetChallenge(ctx, refreshHash("expired")).
+			Return(twofactor.Challenge{}, twofactor.ErrInvalidChallenge).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		_, err := s.VerifyLogin(ctx, "expired", currentCode(t))
+		assert.ErrorIs(t, err, twofactor.ErrInvalidChallenge)
+	})
+
+	t.Run("account locked by earlier failures", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		until := time.Now().Add(user.LoginBackoffBase)
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetChallenge(ctx, refreshHash("challenge")).
+			Return(challenge, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "manager@shop.id"}, nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "account:manager@shop.id", user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: user.AccountFreeLoginAttempts}, &user.LockoutError{Until: until}).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		_, err := s.VerifyLogin(ctx, "challenge", currentCode(t))
+
+		var lockout *user.LockoutError
+		assert.ErrorAs(t, err, &lockout)
+		assert.Equal(t, until, lockout.Until)
+	})
+
+	t.Run("wrong code counts against the challenge and the account", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		enabledAt := time.Now().Add(-time.Hour)
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetChallenge(ctx, refreshHash("challenge")).
+			Return(challenge, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "manager@shop.id"}, nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "account:manager@shop.id", user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 3}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 7).
+			Return(twofactor.Enrollment{UserID: 7, Secret: totpSecret, EnabledAt: &enabledAt}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			UseRecoveryCode(ctx, 7, gomock.Any()).
+			Return(twofactor.ErrInvalidCode).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			RecordChallengeFailure(ctx, 3).
+			Return(nil).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		_, err := s.VerifyLogin(ctx, "challenge", "not-a-code")
+		assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+	})
+
+	t.Run("replayed code", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		enabledAt := time.Now().Add(-time.Hour)
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetChallenge(ctx, refreshHash("challenge")).
+			Return(challenge, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, Email: "manager@shop.id"}, nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "account:manager@shop.id", user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 3}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 7).
+			Return(twofactor.Enrollment{UserID: 7, Secret: totpSecret, EnabledAt: &enabledAt}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			UseStep(ctx, 7, gomock.Any()).
+			Return(twofactor.ErrInvalidCode).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			RecordChallengeFailure(ctx, 3).
+			Return(nil).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		_, err := s.VerifyLogin(ctx, "challenge", currentCode(t))
+		assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+	})
+
+	t.Run("success - first code enables a pending enrollment", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		userRepository := umock.NewMockRepository(ctrl)
+		twoFactorRepository := tfmock.NewMockRepository(ctrl)
+		sessionRepository := sesmock.NewMockRepository(ctrl)
+		tokenSigner := smock.NewMockTokenSigner(ctrl)
+		loginAttempts := smock.NewMockLoginAttemptStore(ctrl)
+
+		twoFactorRepository.
+			EXPECT().
+			GetChallenge(ctx, refreshHash("challenge")).
+			Return(challenge, nil).
+			Times(1)
+
+		userRepository.
+			EXPECT().
+			GetUser(ctx, 1, 7).
+			Return(user.User{ID: 7, MerchantID: 1, RoleID: role.OwnerRoleID, Email: "owner@shop.id", Role: "owner", Permissions: role.Permissions}, nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Take(ctx, "account:owner@shop.id", user.AccountFreeLoginAttempts, gomock.Any(), user.LoginAttemptWindow).
+			Return(user.LoginAttempts{Failures: 3}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			GetEnrollment(ctx, 7).
+			Return(twofactor.Enrollment{UserID: 7, Secret: totpSecret}, nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			Enable(ctx, 7, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		twoFactorRepository.
+			EXPECT().
+			RedeemChallenge(ctx, 3).
+			Return(nil).
+			Times(1)
+
+		loginAttempts.
+			EXPECT().
+			Reset(ctx, "account:owner@shop.id").
+			Return(nil).
+			Times(1)
+
+		sessionRepository.
+			EXPECT().
+			Create(ctx, gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		tokenSigner.
+			EXPECT().
+			Sign(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, identity session.Identity) (string, error) {
+				assert.Equal(t, 7, identity.UserID)
+				assert.Equal(t, role.Permissions, identity.Permissions)
+				return "access-token", nil
+			}).
+			Times(1)
+
+		s := service.NewTwoFactorService(userRepository, twoFactorRepository, sessionRepository, tokenSigner, loginAttempts, "POS")
+		token, err := s.VerifyLogin(ctx, "challenge", currentCode(t))
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", token.AccessToken)
+		assert.NotEmpty(t, token.RefreshToken)
+	})
+}